@@ -1,11 +1,20 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"github.com/sirupsen/logrus"
 	"immudb/internal"
 	"immudb/internal/configuration"
+	"immudb/internal/importer"
+	"immudb/internal/persistance"
+	"immudb/internal/vaultsim"
+	"os"
 )
 
+const devApiKey = "dev"
+
 // @title           Immudb Sample
 // @version         1.0
 // @description     This is a sample server that connects with immudb vault stores and receives data from there.
@@ -22,20 +31,137 @@ import (
 // @externalDocs.description  OpenAPI
 // @externalDocs.url          https://swagger.io/resources/open-api/
 func main() {
+	dev := flag.Bool("dev", false, "run against an in process vault simulator instead of the configured vault")
+	flag.Parse()
 
-	err := setupApi()
+	if flag.Arg(0) == "backfill" {
+		err := backfill(*dev)
+		if err != nil {
+			logrus.WithError(err).Fatal("backfill failed")
+		}
+		return
+	}
+	if flag.Arg(0) == "import" {
+		err := importFile(*dev, flag.Args()[1:])
+		if err != nil {
+			logrus.WithError(err).Fatal("import failed")
+		}
+		return
+	}
+	err := setupApi(*dev)
 	if err != nil {
-		logrus.WithError(err).Error("failed to setup api")
+		logrus.WithError(err).Fatal("failed to setup api")
 	}
 	logrus.Info("api setup complete")
 }
 
-func setupApi() error {
+func setupApi(dev bool) error {
 	appConfigurations, err := configuration.LoadConfiguration()
 	if err != nil {
 		return err
 	}
+	if dev {
+		sim, err := startVaultSimulator(appConfigurations)
+		if err != nil {
+			return err
+		}
+		defer sim.Close()
+	}
+	err = appConfigurations.Validate()
+	if err != nil {
+		return err
+	}
 
 	_, err = internal.NewServer(appConfigurations)
 	return err
 }
+
+// backfill rebuilds the read model of the sql-readthrough backend from the whole ledger, run it after the schema changed or the tables got out of sync
+func backfill(dev bool) error {
+	appConfigurations, err := configuration.LoadConfiguration()
+	if err != nil {
+		return err
+	}
+	if dev {
+		sim, err := startVaultSimulator(appConfigurations)
+		if err != nil {
+			return err
+		}
+		defer sim.Close()
+	}
+	err = appConfigurations.Validate()
+	if err != nil {
+		return err
+	}
+	projected, err := internal.Backfill(context.Background(), appConfigurations)
+	if err != nil {
+		return err
+	}
+	logrus.WithField("revisions", projected).Info("read model backfilled")
+	return nil
+}
+
+// importFile reads a CSV of accounts into the configured backend, the rows that are rejected end up in the report
+func importFile(dev bool, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	file := flags.String("file", "", "the CSV to import, the first line is the header")
+	dryRun := flags.Bool("dry-run", false, "only validate the rows, nothing is written")
+	mapping := flags.String("map", "", "column mapping as field=column pairs, for example account_name=Name,iban=IBAN")
+	reportPath := flags.String("report", "import-errors.csv", "where the rejected rows are written to")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("--file is required")
+	}
+	parsedMapping, err := importer.ParseMapping(*mapping)
+	if err != nil {
+		return err
+	}
+
+	appConfigurations, err := configuration.LoadConfiguration()
+	if err != nil {
+		return err
+	}
+	if dev {
+		sim, err := startVaultSimulator(appConfigurations)
+		if err != nil {
+			return err
+		}
+		defer sim.Close()
+	}
+	err = appConfigurations.Validate()
+	if err != nil {
+		return err
+	}
+	report, err := os.Create(*reportPath)
+	if err != nil {
+		return err
+	}
+	defer report.Close()
+	options := importer.Options{Mapping: parsedMapping, DryRun: *dryRun}
+	job, err := internal.Import(context.Background(), appConfigurations, *file, options, report)
+	if err != nil {
+		return err
+	}
+	logrus.WithFields(logrus.Fields{"rows": job.Rows, "imported": job.Imported, "failed": job.Failed, "dryRun": job.DryRun, "report": *reportPath}).Info("import finished")
+	return nil
+}
+
+// startVaultSimulator points the configuration at an in process vault, the ledger starts empty on every run
+func startVaultSimulator(config *configuration.ApplicationConfiguration) (*vaultsim.Server, error) {
+	sim := vaultsim.NewServer(devApiKey)
+	err := sim.Start("127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	config.Backend = persistance.BackendImmudbVault
+	config.SetBackendSettings(persistance.BackendImmudbVault, map[string]interface{}{
+		"Url":       vaultsim.DocumentUrl(sim.URL()),
+		"SearchUrl": vaultsim.SearchUrl(sim.URL()),
+		"ApiKey":    devApiKey,
+	})
+	logrus.WithField("url", sim.URL()).Warn("dev mode, using the vault simulator, nothing is persisted")
+	return sim, nil
+}
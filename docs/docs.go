@@ -24,12 +24,14 @@ const docTemplate = `{
     "paths": {
         "/account-info": {
             "get": {
-                "description": "Retrieve a paginated list of all account information",
+                "description": "Retrieve a paginated list of all account information.\nWith asOf every account is returned as it was at that point, accounts created later are left out.\nThe pages are pages of the accounts that existed then, only the last one is short.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv",
+                    "application/msgpack"
                 ],
                 "summary": "Get all account infos",
                 "operationId": "get-all-account-infos",
@@ -47,6 +49,12 @@ const docTemplate = `{
                         "description": "Page size",
                         "name": "pageSize",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Transaction id or RFC 3339 timestamp",
+                        "name": "asOf",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -56,6 +64,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_handlers.Response-array_internal_handlers_AccountInfoDto"
                         }
                     },
+                    "400": {
+                        "description": "Bad request, invalid asOf",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "406": {
+                        "description": "None of the accepted types is supported",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -65,7 +85,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Add a new account information entry",
+                "description": "Add a new account information entry, the response carries the vault receipt of the write",
                 "consumes": [
                     "application/json"
                 ],
@@ -87,9 +107,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "201": {
-                        "description": "Created account info",
+                        "description": "Created account info with the vault receipt",
                         "schema": {
-                            "$ref": "#/definitions/internal_handlers.Response-internal_handlers_AccountInfoDto"
+                            "$ref": "#/definitions/internal_handlers.Response-internal_handlers_CreatedAccountInfoDto"
                         }
                     },
                     "400": {
@@ -107,15 +127,299 @@ const docTemplate = `{
                 }
             }
         },
-        "/account-info/{id}": {
+        "/account-info/document/{documentId}": {
+            "get": {
+                "description": "Retrieve the latest revision of an account by the document id the vault assigned to it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "summary": "Get account info by document ID",
+                "operationId": "get-account-info-by-document-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Vault document ID",
+                        "name": "documentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Account information",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-internal_handlers_AccountInfoDto"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "406": {
+                        "description": "None of the accepted types is supported",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    }
+                }
+            }
+        },
+        "/account-info/export": {
             "get": {
-                "description": "Retrieve account information for a specific account by ID",
+                "description": "Streams every account as NDJSON, one account per line, or as CSV with the columns of the import.\nThe accounts are read page by page while the response is written, so it works for any number of accounts.\nIt is not a snapshot, an account written while the export runs can be missed or exported twice, receivers should deduplicate by account_number.\nIf the export fails after the response started, it ends early and the X-Export-Error trailer has the reason.",
+                "produces": [
+                    "application/x-ndjson",
+                    "text/csv"
+                ],
+                "summary": "Export all account infos",
+                "operationId": "export-account-infos",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "ndjson",
+                        "description": "ndjson or csv",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Transaction id or RFC 3339 timestamp",
+                        "name": "asOf",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The accounts",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, invalid format or asOf",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "501": {
+                        "description": "asOf is not supported by the backend",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    }
+                }
+            }
+        },
+        "/account-info/import": {
+            "post": {
+                "description": "Starts a background import of the uploaded CSV, either the raw request body or the \"file\" field of a multipart form.\nThe first line is the header, mapping picks the columns as field=column pairs, for example account_name=Name,iban=IBAN.\nFollow the job on the returned Location, rejected rows are listed in the error report of the job.",
                 "consumes": [
+                    "text/csv",
+                    "multipart/form-data"
+                ],
+                "produces": [
                     "application/json"
                 ],
+                "summary": "Import account infos from CSV",
+                "operationId": "import-account-infos",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV file, when the body is a multipart form",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate, nothing is written",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Column mapping, field=column pairs separated by commas",
+                        "name": "mapping",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Import started",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-internal_handlers_ImportJobDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, invalid mapping or upload",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "501": {
+                        "description": "Imports are not configured",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    }
+                }
+            }
+        },
+        "/account-info/search": {
+            "get": {
+                "description": "Full text search over name, iban and address with optional filters, served from the SQL read model.\nThe read model trails the vault by the projection interval.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "summary": "Search account infos",
+                "operationId": "search-account-infos",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Full text query",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            1,
+                            2
+                        ],
+                        "type": "integer",
+                        "description": "Account type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum amount",
+                        "name": "minAmount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum amount",
+                        "name": "maxAmount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size",
+                        "name": "pageSize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Matching accounts",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-array_internal_handlers_AccountInfoDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, invalid filters",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "406": {
+                        "description": "None of the accepted types is supported",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "501": {
+                        "description": "The read model is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    }
+                }
+            }
+        },
+        "/account-info/stats": {
+            "get": {
+                "description": "Number of accounts, revisions and amounts per account type, served from the SQL read model",
                 "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "summary": "Account statistics",
+                "operationId": "get-account-stats",
+                "responses": {
+                    "200": {
+                        "description": "Statistics per account type",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-array_internal_handlers_AccountStatsDto"
+                        }
+                    },
+                    "406": {
+                        "description": "None of the accepted types is supported",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "501": {
+                        "description": "The read model is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    }
+                }
+            }
+        },
+        "/account-info/{id}": {
+            "get": {
+                "description": "Retrieve account information for a specific account by ID.\nWith verify=true the document inclusion proof is checked against the locally trusted ledger state.\nWith asOf the revision that was current at that point is returned.",
+                "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/msgpack"
+                ],
                 "summary": "Get account info by ID",
                 "operationId": "get-account-info-by-id",
                 "parameters": [
@@ -125,31 +429,77 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Verify the document against the ledger proofs",
+                        "name": "verify",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Transaction id or RFC 3339 timestamp",
+                        "name": "asOf",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the revision the client already has, ignored with asOf",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Account information",
+                        "description": "Account information, verification is only set on verified reads",
                         "schema": {
-                            "$ref": "#/definitions/internal_handlers.Response-internal_handlers_AccountInfoDto"
+                            "$ref": "#/definitions/internal_handlers.Response-internal_handlers_VerifiedAccountInfoDto"
                         }
                     },
+                    "304": {
+                        "description": "The revision didn't change"
+                    },
                     "400": {
                         "description": "Bad request, ID is required",
                         "schema": {
                             "$ref": "#/definitions/internal_handlers.Response-string"
                         }
                     },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "406": {
+                        "description": "None of the accepted types is supported",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/internal_handlers.Response-string"
                         }
+                    },
+                    "501": {
+                        "description": "Verified reads are not supported by the backend",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "502": {
+                        "description": "Proof verification failed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
                     }
                 }
             },
             "put": {
-                "description": "Update account information for a specific account by ID (currently not allowed)",
+                "description": "Store a new revision of the account. If-Match must carry the ETag of the revision being updated,\nif somebody else updated the account in the meantime the update is rejected with 412.",
                 "consumes": [
                     "application/json"
                 ],
@@ -166,6 +516,13 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "ETag of the revision being updated",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
                     {
                         "description": "Updated account information",
                         "name": "account",
@@ -177,8 +534,32 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
+                    "200": {
+                        "description": "Updated account info",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-internal_handlers_AccountInfoDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, invalid input or an If-Match that is not a single strong etag",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
                     "405": {
-                        "description": "Method not allowed",
+                        "description": "Updates are not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "412": {
+                        "description": "The account was modified since the ETag was issued",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match is required",
                         "schema": {
                             "$ref": "#/definitions/internal_handlers.Response-string"
                         }
@@ -213,11 +594,618 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "immudb_internal_models.AccountType": {
-            "type": "integer",
+        },
+        "/account-info/{id}/diff": {
+            "get": {
+                "description": "Field level changes between two revisions, with format=json-patch an RFC 6902 patch document is returned instead",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/json-patch+json",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "summary": "Diff two revisions of an account",
+                "operationId": "get-account-info-diff",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision to diff from",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision to diff to",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "changes",
+                            "json-patch"
+                        ],
+                        "type": "string",
+                        "default": "changes",
+                        "description": "Output format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Changes between the revisions",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-internal_handlers_AccountDiffDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, invalid revisions",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "404": {
+                        "description": "Account or revision not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "406": {
+                        "description": "None of the accepted types is supported",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    }
+                }
+            }
+        },
+        "/account-info:batch": {
+            "post": {
+                "description": "Validates every account and writes the valid ones with as few vault calls as possible, every item reports its own status.\nWith atomic=true an invalid account rejects the whole batch, a write that fails halfway still keeps what was written before it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create account infos in a batch",
+                "operationId": "create-account-info-batch",
+                "parameters": [
+                    {
+                        "description": "Accounts to create, at most 500",
+                        "name": "accounts",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_handlers.AccountInfoDto"
+                            }
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Reject the whole batch if any account is invalid",
+                        "name": "atomic",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Every account was created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-array_internal_handlers_BatchItemDto"
+                        }
+                    },
+                    "207": {
+                        "description": "Some accounts were not created, see the status of every item",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-array_internal_handlers_BatchItemDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, the atomic batch was rejected or the body is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-array_internal_handlers_BatchItemDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    }
+                }
+            }
+        },
+        "/audit/status": {
+            "get": {
+                "description": "Results of the latest background audits of the ledger, newest first",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "summary": "Get the tamper audit status",
+                "operationId": "get-audit-status",
+                "responses": {
+                    "200": {
+                        "description": "Audit status",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-internal_handlers_AuditStatusDto"
+                        }
+                    },
+                    "406": {
+                        "description": "None of the accepted types is supported",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    }
+                }
+            }
+        },
+        "/events": {
+            "get": {
+                "description": "Server-Sent Events of the accounts committed by any instance, in ledger order as the change data capture reads them.\nThe event name is the type and the data the same JSON webhooks get.\nThe id of every event is its position in the ledger, a client reconnecting with Last-Event-ID first gets what it missed.\nA Last-Event-ID whose next event is older than the replay window is refused, the client reconnects without it and reloads the accounts.\nA client that falls too far behind is disconnected and resumes the same way.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "summary": "Stream account events",
+                "operationId": "stream-events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Event types separated by commas, all when empty",
+                        "name": "types",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of the last event the client got",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of events",
+                        "schema": {
+                            "$ref": "#/definitions/immudb_internal_events.Envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, unknown event type, invalid Last-Event-ID or one older than the replay window",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "500": {
+                        "description": "The change feed could not be read",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "501": {
+                        "description": "The change data capture is off or the backend has no change feed",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    }
+                }
+            }
+        },
+        "/imports/{id}": {
+            "get": {
+                "description": "Progress of an import, bytes_read against total_bytes tells how far a raw upload got",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/msgpack"
+                ],
+                "summary": "Get an import job",
+                "operationId": "get-import-job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Import job",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-internal_handlers_ImportJobDto"
+                        }
+                    },
+                    "404": {
+                        "description": "Job not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "406": {
+                        "description": "None of the accepted types is supported",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    }
+                }
+            }
+        },
+        "/imports/{id}/errors": {
+            "get": {
+                "description": "CSV with line, field and reason for every rejected row, while the job runs it has the rows rejected so far",
+                "produces": [
+                    "text/csv"
+                ],
+                "summary": "Download the error report of an import",
+                "operationId": "get-import-errors",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Error report",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Job not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List webhooks",
+                "operationId": "get-webhooks",
+                "responses": {
+                    "200": {
+                        "description": "Webhooks, oldest first",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-array_internal_handlers_WebhookDto"
+                        }
+                    },
+                    "501": {
+                        "description": "Webhooks are not configured",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Every event of the subscribed types is posted to the url, signed in X-Webhook-Signature as sha256=HMAC-SHA256(secret, timestamp + \".\" + body)\nwith the timestamp of X-Webhook-Timestamp. The secret is only returned here, a failed delivery is retried with backoff.\nAn url on a loopback, private or link-local address is refused unless its host is allowed in WebhookAllowedHosts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Subscribe to account events",
+                "operationId": "create-webhook",
+                "parameters": [
+                    {
+                        "description": "Url, events (account.created, account.updated) and an optional secret",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.WebhookInputDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created webhook",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-internal_handlers_WebhookDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, invalid url or events",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "501": {
+                        "description": "Webhooks are not configured",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get a webhook",
+                "operationId": "get-webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-internal_handlers_WebhookDto"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces url, events and active, the secret is only replaced when one is given",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update a webhook",
+                "operationId": "update-webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Url, events, active and an optional new secret",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.WebhookInputDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated webhook",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-internal_handlers_WebhookDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, invalid url or events",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deliveries under way and the dead letters of the webhook are dropped",
+                "summary": "Delete a webhook",
+                "operationId": "delete-webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Deleted"
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/dead-letters": {
+            "get": {
+                "description": "Deliveries that failed every attempt, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the dead letters of a webhook",
+                "operationId": "get-webhook-dead-letters",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dead letters",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-array_internal_handlers_WebhookDeliveryDto"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/dead-letters/{deliveryId}/redeliver": {
+            "post": {
+                "description": "Takes the delivery off the dead letters and sends it again with a fresh set of attempts, X-Webhook-Delivery stays the same",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Redeliver a dead letter",
+                "operationId": "redeliver-webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery id",
+                        "name": "deliveryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Delivery queued",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-internal_handlers_WebhookDeliveryDto"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook or dead letter not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "409": {
+                        "description": "The webhook is not active",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    },
+                    "503": {
+                        "description": "The delivery queue is full",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers.Response-string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "immudb_internal_errors.InvalidParam": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "immudb_internal_events.Account": {
+            "type": "object",
+            "properties": {
+                "account_name": {
+                    "type": "string"
+                },
+                "account_number": {
+                    "type": "integer"
+                },
+                "address": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "document_id": {
+                    "type": "string"
+                },
+                "iban": {
+                    "type": "string"
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "type": {
+                    "$ref": "#/definitions/immudb_internal_models.AccountType"
+                }
+            }
+        },
+        "immudb_internal_events.Envelope": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/immudb_internal_events.Account"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "occurred_at": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                },
+                "type": {
+                    "$ref": "#/definitions/immudb_internal_models.EventType"
+                }
+            }
+        },
+        "immudb_internal_models.AccountType": {
+            "type": "integer",
             "enum": [
                 1,
                 2
@@ -231,7 +1219,186 @@ const docTemplate = `{
                 "Receiving"
             ]
         },
-        "internal_handlers.AccountInfoDto": {
+        "immudb_internal_models.EventType": {
+            "type": "string",
+            "enum": [
+                "account.created",
+                "account.updated"
+            ],
+            "x-enum-varnames": [
+                "AccountCreated",
+                "AccountUpdated"
+            ]
+        },
+        "internal_handlers.AccountDiffDto": {
+            "type": "object",
+            "properties": {
+                "account_number": {
+                    "type": "integer"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_handlers.FieldChangeDto"
+                    }
+                },
+                "document_id": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_handlers.AccountInfoDto": {
+            "type": "object",
+            "properties": {
+                "account_name": {
+                    "type": "string"
+                },
+                "account_number": {
+                    "type": "integer"
+                },
+                "address": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "document_id": {
+                    "description": "read only, assigned by the vault",
+                    "type": "string"
+                },
+                "iban": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/immudb_internal_models.AccountType"
+                }
+            }
+        },
+        "internal_handlers.AccountStatsDto": {
+            "type": "object",
+            "properties": {
+                "accounts": {
+                    "type": "integer"
+                },
+                "average_amount": {
+                    "type": "number"
+                },
+                "last_change": {
+                    "type": "string"
+                },
+                "revisions": {
+                    "type": "integer"
+                },
+                "total_amount": {
+                    "type": "number"
+                },
+                "type": {
+                    "$ref": "#/definitions/immudb_internal_models.AccountType"
+                }
+            }
+        },
+        "internal_handlers.AuditFailureDto": {
+            "type": "object",
+            "properties": {
+                "check": {
+                    "type": "string"
+                },
+                "document_id": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_handlers.AuditReportDto": {
+            "type": "object",
+            "properties": {
+                "documents_sampled": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "failures": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_handlers.AuditFailureDto"
+                    }
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "from_transaction_id": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "to_transaction_id": {
+                    "type": "integer"
+                },
+                "transactions_checked": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_handlers.AuditStatusDto": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "reports": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_handlers.AuditReportDto"
+                    }
+                },
+                "runs": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_handlers.BatchItemDto": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/internal_handlers.CreatedAccountInfoDto"
+                },
+                "error_message": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "invalid_params": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/immudb_internal_errors.InvalidParam"
+                    }
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_handlers.CreatedAccountInfoDto": {
             "type": "object",
             "properties": {
                 "account_name": {
@@ -246,14 +1413,92 @@ const docTemplate = `{
                 "amount": {
                     "type": "number"
                 },
+                "document_id": {
+                    "description": "read only, assigned by the vault",
+                    "type": "string"
+                },
                 "iban": {
                     "type": "string"
                 },
+                "receipt": {
+                    "$ref": "#/definitions/internal_handlers.ReceiptDto"
+                },
                 "type": {
                     "$ref": "#/definitions/immudb_internal_models.AccountType"
                 }
             }
         },
+        "internal_handlers.FieldChangeDto": {
+            "type": "object",
+            "properties": {
+                "changed_by": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "new_value": {},
+                "old_value": {},
+                "transaction_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_handlers.ImportJobDto": {
+            "type": "object",
+            "properties": {
+                "bytes_read": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "imported": {
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total_bytes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_handlers.ReceiptDto": {
+            "type": "object",
+            "properties": {
+                "document_id": {
+                    "type": "string"
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "internal_handlers.Response-array_internal_handlers_AccountInfoDto": {
             "type": "object",
             "properties": {
@@ -268,6 +1513,73 @@ const docTemplate = `{
                 }
             }
         },
+        "internal_handlers.Response-array_internal_handlers_AccountStatsDto": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_handlers.AccountStatsDto"
+                    }
+                },
+                "error_message": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers.Response-array_internal_handlers_BatchItemDto": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_handlers.BatchItemDto"
+                    }
+                },
+                "error_message": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers.Response-array_internal_handlers_WebhookDeliveryDto": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_handlers.WebhookDeliveryDto"
+                    }
+                },
+                "error_message": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers.Response-array_internal_handlers_WebhookDto": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_handlers.WebhookDto"
+                    }
+                },
+                "error_message": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers.Response-internal_handlers_AccountDiffDto": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/internal_handlers.AccountDiffDto"
+                },
+                "error_message": {
+                    "type": "string"
+                }
+            }
+        },
         "internal_handlers.Response-internal_handlers_AccountInfoDto": {
             "type": "object",
             "properties": {
@@ -279,6 +1591,72 @@ const docTemplate = `{
                 }
             }
         },
+        "internal_handlers.Response-internal_handlers_AuditStatusDto": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/internal_handlers.AuditStatusDto"
+                },
+                "error_message": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers.Response-internal_handlers_CreatedAccountInfoDto": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/internal_handlers.CreatedAccountInfoDto"
+                },
+                "error_message": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers.Response-internal_handlers_ImportJobDto": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/internal_handlers.ImportJobDto"
+                },
+                "error_message": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers.Response-internal_handlers_VerifiedAccountInfoDto": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/internal_handlers.VerifiedAccountInfoDto"
+                },
+                "error_message": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers.Response-internal_handlers_WebhookDeliveryDto": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/internal_handlers.WebhookDeliveryDto"
+                },
+                "error_message": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers.Response-internal_handlers_WebhookDto": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/internal_handlers.WebhookDto"
+                },
+                "error_message": {
+                    "type": "string"
+                }
+            }
+        },
         "internal_handlers.Response-string": {
             "type": "object",
             "properties": {
@@ -289,6 +1667,143 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "internal_handlers.VerificationDto": {
+            "type": "object",
+            "properties": {
+                "document_id": {
+                    "type": "string"
+                },
+                "ledger_transaction_id": {
+                    "type": "integer"
+                },
+                "root_hash": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                },
+                "verified": {
+                    "type": "boolean"
+                },
+                "verified_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers.VerifiedAccountInfoDto": {
+            "type": "object",
+            "properties": {
+                "account_name": {
+                    "type": "string"
+                },
+                "account_number": {
+                    "type": "integer"
+                },
+                "address": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "document_id": {
+                    "description": "read only, assigned by the vault",
+                    "type": "string"
+                },
+                "iban": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/immudb_internal_models.AccountType"
+                },
+                "verification": {
+                    "$ref": "#/definitions/internal_handlers.VerificationDto"
+                }
+            }
+        },
+        "internal_handlers.WebhookDeliveryDto": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_status_code": {
+                    "type": "integer"
+                },
+                "webhook_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers.WebhookDto": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "only returned when it is set",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers.WebhookInputDto": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "true when not given",
+                    "type": "boolean"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "description": "generated on create when not given, kept on update",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
         }
     },
     "externalDocs": {
@@ -0,0 +1,190 @@
+// Package audit runs a background tamper check over the ledger backing the accounts.
+package audit
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"immudb/internal/persistance"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	CheckConsistency = "consistency"
+	CheckInclusion   = "inclusion"
+
+	StatusPassed = "passed"
+	StatusFailed = "failed" // a proof did not verify, the ledger might have been tampered with
+	StatusError  = "error"  // the audit could not run, nothing is known about the ledger
+
+	// how many reports are kept for the status endpoint
+	historySize = 10
+)
+
+type Failure struct {
+	DocumentId    string
+	TransactionId uint64
+	Check         string
+	Error         string
+}
+
+type Report struct {
+	StartedAt           time.Time
+	FinishedAt          time.Time
+	Status              string
+	Error               string
+	FromTransactionId   uint64
+	ToTransactionId     uint64
+	TransactionsChecked int
+	DocumentsSampled    int
+	Failures            []Failure
+}
+
+type Status struct {
+	Enabled  bool
+	Interval time.Duration
+	Runs     int
+	Reports  []Report // newest first
+}
+
+// Auditor walks the recent transactions on a schedule, checks their consistency proofs
+// and samples some of the documents for inclusion proofs
+type Auditor struct {
+	db         persistance.AuditableDB
+	verifier   *persistance.Verifier
+	interval   time.Duration
+	window     int
+	sampleSize int
+
+	mx      sync.Mutex
+	runs    int
+	reports []Report
+}
+
+func NewAuditor(db persistance.AuditableDB, verifier *persistance.Verifier, interval time.Duration, window, sampleSize int) *Auditor {
+	return &Auditor{
+		db:         db,
+		verifier:   verifier,
+		interval:   interval,
+		window:     window,
+		sampleSize: sampleSize,
+	}
+}
+
+// Start runs the audit until the context is cancelled, the first run starts right away
+func (a *Auditor) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+		for {
+			a.Run(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Run executes a single audit and records its report
+func (a *Auditor) Run(ctx context.Context) Report {
+	report := a.audit(ctx)
+	logger := logrus.WithFields(logrus.Fields{
+		"status":              report.Status,
+		"transactionsChecked": report.TransactionsChecked,
+		"documentsSampled":    report.DocumentsSampled,
+		"failures":            len(report.Failures),
+	})
+	switch report.Status {
+	case StatusFailed:
+		logger.Error("ledger audit failed")
+	case StatusError:
+		logger.WithField("error", report.Error).Warn("ledger audit could not run")
+	default:
+		logger.Info("ledger audit passed")
+	}
+
+	a.mx.Lock()
+	defer a.mx.Unlock()
+	a.runs++
+	a.reports = append([]Report{report}, a.reports...)
+	if len(a.reports) > historySize {
+		a.reports = a.reports[:historySize]
+	}
+	return report
+}
+
+func (a *Auditor) Status() Status {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+	return Status{
+		Enabled:  true,
+		Interval: a.interval,
+		Runs:     a.runs,
+		Reports:  append([]Report(nil), a.reports...),
+	}
+}
+
+func (a *Auditor) audit(ctx context.Context) Report {
+	report := Report{StartedAt: time.Now().UTC(), Status: StatusPassed}
+	defer func() {
+		report.FinishedAt = time.Now().UTC()
+	}()
+
+	refs, err := a.db.RecentRevisions(ctx, a.window)
+	if err != nil {
+		report.Status = StatusError
+		report.Error = err.Error()
+		return report
+	}
+	// walk oldest to newest so the trusted state only moves forward
+	sort.SliceStable(refs, func(i, j int) bool {
+		return refs[i].TransactionID < refs[j].TransactionID
+	})
+
+	var proofs []*persistance.DocumentProof
+	for _, ref := range refs {
+		proof, err := a.db.GetDocumentProof(ctx, ref.DocumentID, ref.TransactionID)
+		if err != nil {
+			report.Status = StatusError
+			report.Error = err.Error()
+			return report
+		}
+		report.TransactionsChecked++
+		if report.FromTransactionId == 0 {
+			report.FromTransactionId = ref.TransactionID
+		}
+		report.ToTransactionId = proof.State.TransactionID
+		err = a.verifier.CheckConsistency(proof.State, proof.ConsistencyProof)
+		if err != nil {
+			report.addFailure(ref, CheckConsistency, err)
+			continue
+		}
+		proofs = append(proofs, proof)
+	}
+
+	for _, i := range rand.Perm(len(proofs))[:min(a.sampleSize, len(proofs))] {
+		proof := proofs[i]
+		report.DocumentsSampled++
+		_, err = a.verifier.VerifyDocument(proof, nil)
+		if err != nil {
+			transactionId, _ := strconv.ParseUint(proof.TransactionID, 10, 64)
+			report.addFailure(persistance.RevisionRef{DocumentID: proof.DocumentID, TransactionID: transactionId}, CheckInclusion, err)
+		}
+	}
+	return report
+}
+
+func (r *Report) addFailure(ref persistance.RevisionRef, check string, err error) {
+	r.Status = StatusFailed
+	r.Failures = append(r.Failures, Failure{
+		DocumentId:    ref.DocumentID,
+		TransactionId: ref.TransactionID,
+		Check:         check,
+		Error:         err.Error(),
+	})
+}
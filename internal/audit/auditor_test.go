@@ -0,0 +1,121 @@
+package audit
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"immudb/internal/merkle"
+	"immudb/internal/persistance"
+	"strconv"
+	"testing"
+)
+
+// fakeLedger serves proofs from an in memory merkle tree
+type fakeLedger struct {
+	documents []string
+	tree      *merkle.Tree
+	err       error
+}
+
+func newFakeLedger(size int) *fakeLedger {
+	ledger := &fakeLedger{tree: merkle.NewTree()}
+	for i := 1; i <= size; i++ {
+		document := fmt.Sprintf(`{"_id":"doc%d","id":%d}`, i, i)
+		ledger.documents = append(ledger.documents, document)
+		ledger.tree.Append(merkle.LeafHash([]byte(document)))
+	}
+	return ledger
+}
+
+func (l *fakeLedger) RecentRevisions(ctx context.Context, limit int) ([]persistance.RevisionRef, error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+	var refs []persistance.RevisionRef
+	for i := len(l.documents); i > 0 && len(refs) < limit; i-- {
+		refs = append(refs, persistance.RevisionRef{DocumentID: fmt.Sprintf("doc%d", i), TransactionID: uint64(i)})
+	}
+	return refs, nil
+}
+
+func (l *fakeLedger) GetDocumentProof(ctx context.Context, documentId string, transactionId uint64) (*persistance.DocumentProof, error) {
+	size := l.tree.Size()
+	root, _ := l.tree.RootAt(size)
+	inclusion, _ := l.tree.InclusionProof(transactionId-1, size)
+	return &persistance.DocumentProof{
+		DocumentID:      documentId,
+		TransactionID:   strconv.FormatUint(transactionId, 10),
+		EncodedDocument: base64.StdEncoding.EncodeToString([]byte(l.documents[transactionId-1])),
+		State:           persistance.LedgerState{TransactionID: size, RootHash: hex.EncodeToString(root)},
+		InclusionProof:  encode(inclusion),
+	}, nil
+}
+
+func encode(hashes [][]byte) []string {
+	var result []string
+	for _, hash := range hashes {
+		result = append(result, hex.EncodeToString(hash))
+	}
+	return result
+}
+
+func TestAuditor_Run(t *testing.T) {
+	tests := []struct {
+		name                string
+		ledger              *fakeLedger
+		trustedState        *persistance.LedgerState
+		expectedStatus      string
+		expectedChecked     int
+		expectedSampled     int
+		expectedFailedCheck string
+	}{
+		{
+			name:            "Test_Validity",
+			ledger:          newFakeLedger(8),
+			expectedStatus:  StatusPassed,
+			expectedChecked: 5,
+			expectedSampled: 2,
+		},
+		{
+			name:                "Test_Forked_Ledger",
+			ledger:              newFakeLedger(8),
+			trustedState:        &persistance.LedgerState{TransactionID: 8, RootHash: hex.EncodeToString(merkle.LeafHash([]byte("fork")))},
+			expectedStatus:      StatusFailed,
+			expectedChecked:     5,
+			expectedFailedCheck: CheckConsistency,
+		},
+		{
+			name:           "Test_Ledger_Unavailable",
+			ledger:         &fakeLedger{err: fmt.Errorf("test error")},
+			expectedStatus: StatusError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			verifier := persistance.NewVerifier(persistance.NewMemoryStateStore())
+			if tt.trustedState != nil {
+				assert.NoError(t, verifier.Store.Save(*tt.trustedState))
+			}
+			auditor := NewAuditor(tt.ledger, verifier, 0, 5, 2)
+
+			// action
+			report := auditor.Run(context.Background())
+
+			// assertions
+			assert.Equal(t, tt.expectedStatus, report.Status)
+			assert.Equal(t, tt.expectedChecked, report.TransactionsChecked)
+			assert.Equal(t, tt.expectedSampled, report.DocumentsSampled)
+			if tt.expectedFailedCheck != "" {
+				assert.NotEmpty(t, report.Failures)
+				assert.Equal(t, tt.expectedFailedCheck, report.Failures[0].Check)
+			}
+			status := auditor.Status()
+			assert.Equal(t, 1, status.Runs)
+			assert.Equal(t, []Report{report}, status.Reports)
+		})
+	}
+}
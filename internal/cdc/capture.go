@@ -0,0 +1,199 @@
+package cdc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"immudb/internal/events"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"slices"
+	"sync"
+	"time"
+)
+
+const defaultBatchSize = 100
+
+// Handler gets the events of one subscriber in ledger order. An error stops the subscriber until the next poll,
+// which offers the failed event again, so a handler must cope with seeing an event twice.
+type Handler func(ctx context.Context, event models.AccountEvent) error
+
+// ChangeHandler gets the changes with their position in the feed, like a Handler gets their events
+type ChangeHandler func(ctx context.Context, change persistance.Change) error
+
+type subscriber struct {
+	name    string
+	handle  ChangeHandler
+	durable bool
+	cursor  persistance.ChangeCursor
+	started bool // the cursor was loaded from the checkpoint or set to the head of the ledger
+}
+
+// Capture polls the change feed of the ledger for the revisions committed by any instance and hands them to its subscribers.
+// Every subscriber has its own position in the feed, a durable one checkpoints it after every batch so a restart resumes where it stopped.
+type Capture struct {
+	feed        persistance.ChangeFeed
+	checkpoints CheckpointStore
+	interval    time.Duration
+	BatchSize   int
+
+	mx          sync.Mutex
+	subscribers []*subscriber
+	polling     sync.Mutex // one poll at a time, it owns the cursors of the subscribers
+}
+
+func NewCapture(feed persistance.ChangeFeed, checkpoints CheckpointStore, interval time.Duration) *Capture {
+	return &Capture{
+		feed:        feed,
+		checkpoints: checkpoints,
+		interval:    interval,
+		BatchSize:   defaultBatchSize,
+	}
+}
+
+// Subscribe adds a durable subscriber, the first time it runs it starts at the head of the ledger
+func (c *Capture) Subscribe(name string, handler Handler) {
+	handle := func(ctx context.Context, change persistance.Change) error {
+		return handler(ctx, events.EventOf(change.Revision))
+	}
+	c.add(&subscriber{name: name, handle: handle, durable: true})
+}
+
+// SubscribeLive adds a subscriber that starts at the head of the ledger every time the process starts
+func (c *Capture) SubscribeLive(name string, handler ChangeHandler) {
+	c.add(&subscriber{name: name, handle: handler})
+}
+
+func (c *Capture) add(s *subscriber) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.subscribers = append(c.subscribers, s)
+}
+
+// Start polls the change feed until the context is cancelled, the first poll starts right away
+func (c *Capture) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			delivered, err := c.RunOnce(ctx)
+			if err != nil {
+				logrus.WithError(err).Warn("change data capture failed, it is retried on the next poll")
+			} else if delivered > 0 {
+				logrus.WithField("events", delivered).Debug("change data capture delivered")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce catches every subscriber up with the ledger and returns how many events were handed out.
+// A failing subscriber doesn't hold the others back.
+func (c *Capture) RunOnce(ctx context.Context) (int, error) {
+	c.polling.Lock()
+	defer c.polling.Unlock()
+	c.mx.Lock()
+	subscribers := slices.Clone(c.subscribers)
+	c.mx.Unlock()
+
+	// subscribers are mostly at the same position, they share what was read from the feed in this poll
+	pages := map[persistance.ChangeCursor][]persistance.Change{}
+	fetch := func(cursor persistance.ChangeCursor) ([]persistance.Change, error) {
+		if page, ok := pages[cursor]; ok {
+			return page, nil
+		}
+		page, err := c.feed.ChangesSince(ctx, cursor, c.BatchSize)
+		if err != nil {
+			return nil, err
+		}
+		pages[cursor] = page
+		return page, nil
+	}
+
+	delivered := 0
+	var errs []error
+	for _, s := range subscribers {
+		err := c.start(ctx, s)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+			continue
+		}
+		handed, err := c.catchUp(ctx, s, fetch)
+		delivered += handed
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+		}
+	}
+	return delivered, errors.Join(errs...)
+}
+
+// start positions a subscriber on its first poll
+func (c *Capture) start(ctx context.Context, s *subscriber) error {
+	if s.started {
+		return nil
+	}
+	if s.durable {
+		cursor, ok, err := c.checkpoints.Load(s.name)
+		if err != nil {
+			return err
+		}
+		if ok {
+			s.cursor = cursor
+			s.started = true
+			return nil
+		}
+	}
+	cursor, err := c.feed.Head(ctx)
+	if err != nil {
+		return err
+	}
+	if s.durable {
+		err = c.checkpoints.Save(s.name, cursor)
+		if err != nil {
+			return err
+		}
+	}
+	s.cursor = cursor
+	s.started = true
+	return nil
+}
+
+// catchUp hands the subscriber the events after its cursor, the checkpoint is saved after every batch and before returning on a failure
+func (c *Capture) catchUp(ctx context.Context, s *subscriber, fetch func(cursor persistance.ChangeCursor) ([]persistance.Change, error)) (int, error) {
+	handed := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return handed, ctx.Err()
+		default:
+		}
+		changes, err := fetch(s.cursor)
+		if err != nil {
+			return handed, err
+		}
+		checkpoint := s.cursor
+		for _, change := range changes {
+			err = s.handle(ctx, change)
+			if err != nil {
+				break
+			}
+			s.cursor = change.Cursor
+			handed++
+		}
+		if s.durable && s.cursor != checkpoint {
+			saveErr := c.checkpoints.Save(s.name, s.cursor)
+			if saveErr != nil {
+				// the events since the last checkpoint are handed out again after a restart
+				logrus.WithError(saveErr).WithField("subscriber", s.name).Error("failed to save the change data capture checkpoint")
+			}
+		}
+		if err != nil || len(changes) < c.BatchSize {
+			return handed, err
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package cdc
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func createAccounts(t *testing.T, db *persistance.MemoryDB, names ...string) {
+	for _, name := range names {
+		_, err := db.CreateAccountInfo(context.Background(), models.AccountInfo{Name: name, Iban: "AL47", Type: persistance.AddPointer(models.Sending)})
+		assert.NoError(t, err)
+	}
+}
+
+// recorder collects the names of the accounts it is handed, failing once on the account named in failOn
+type recorder struct {
+	names  []string
+	failOn string
+}
+
+func (r *recorder) handle(ctx context.Context, event models.AccountEvent) error {
+	if event.Account.Name == r.failOn {
+		r.failOn = ""
+		return errors.New("subscriber unavailable")
+	}
+	r.names = append(r.names, event.Account.Name)
+	return nil
+}
+
+func (r *recorder) handleChange(ctx context.Context, change persistance.Change) error {
+	return r.handle(ctx, models.AccountEvent{Account: *change.Revision})
+}
+
+func TestCapture_RunOnce(t *testing.T) {
+	// setup
+	ctx := context.Background()
+	db := persistance.NewMemoryDB()
+	createAccounts(t, db, "old")
+	checkpoints := NewFileCheckpoints(filepath.Join(t.TempDir(), "checkpoints.json"))
+	capture := NewCapture(db, checkpoints, 0)
+	capture.BatchSize = 2
+	healthy := &recorder{}
+	flaky := &recorder{failOn: "c"}
+	capture.Subscribe("healthy", healthy.handle)
+	capture.Subscribe("flaky", flaky.handle)
+
+	// action
+	delivered, err := capture.RunOnce(ctx)
+
+	// assertions
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered, "new subscribers start at the head of the ledger")
+
+	// action
+	createAccounts(t, db, "a", "b", "c", "d", "e")
+	delivered, err = capture.RunOnce(ctx)
+
+	// assertions
+	assert.EqualError(t, err, "flaky: subscriber unavailable")
+	assert.Equal(t, 7, delivered)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, healthy.names)
+	assert.Equal(t, []string{"a", "b"}, flaky.names)
+
+	// action
+	delivered, err = capture.RunOnce(ctx)
+
+	// assertions
+	assert.NoError(t, err)
+	assert.Equal(t, 3, delivered)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, flaky.names)
+}
+
+func TestCapture_Restart(t *testing.T) {
+	// setup
+	ctx := context.Background()
+	db := persistance.NewMemoryDB()
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+	first := NewCapture(db, NewFileCheckpoints(path), 0)
+	durable := &recorder{failOn: "b"}
+	first.Subscribe("durable", durable.handle)
+	first.SubscribeLive("live", (&recorder{}).handleChange)
+	_, err := first.RunOnce(ctx)
+	assert.NoError(t, err)
+	createAccounts(t, db, "a", "b")
+	_, err = first.RunOnce(ctx)
+	assert.Error(t, err)
+	createAccounts(t, db, "c")
+
+	// action
+	restarted := NewCapture(db, NewFileCheckpoints(path), 0)
+	resumed := &recorder{}
+	live := &recorder{}
+	restarted.Subscribe("durable", resumed.handle)
+	restarted.SubscribeLive("live", live.handleChange)
+	_, err = restarted.RunOnce(ctx)
+
+	// assertions
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, durable.names)
+	assert.Equal(t, []string{"b", "c"}, resumed.names, "the durable subscriber resumes after its checkpoint")
+	assert.Empty(t, live.names, "the live subscriber starts at the head again")
+}
+
+func TestFileCheckpoints(t *testing.T) {
+	// setup
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+	checkpoints := NewFileCheckpoints(path)
+
+	// action
+	_, found, err := checkpoints.Load("webhooks")
+	assert.NoError(t, err)
+	assert.False(t, found)
+	assert.NoError(t, checkpoints.Save("webhooks", persistance.ChangeCursor{TransactionId: 42}))
+	cursor, found, err := NewFileCheckpoints(path).Load("webhooks")
+
+	// assertions
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uint64(42), cursor.TransactionId)
+
+	// action
+	assert.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+	_, _, err = NewFileCheckpoints(path).Load("webhooks")
+
+	// assertions
+	assert.ErrorContains(t, err, "corrupted change data capture checkpoints")
+}
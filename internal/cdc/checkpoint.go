@@ -0,0 +1,106 @@
+package cdc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"immudb/internal/persistance"
+	"os"
+	"sync"
+)
+
+// CheckpointStore keeps the position of every durable subscriber in the change feed
+type CheckpointStore interface {
+	// Load returns false for a subscriber that never saved a checkpoint
+	Load(name string) (persistance.ChangeCursor, bool, error)
+	Save(name string, cursor persistance.ChangeCursor) error
+}
+
+type MemoryCheckpoints struct {
+	mx          sync.Mutex
+	checkpoints map[string]persistance.ChangeCursor
+}
+
+func NewMemoryCheckpoints() *MemoryCheckpoints {
+	return &MemoryCheckpoints{checkpoints: map[string]persistance.ChangeCursor{}}
+}
+
+func (m *MemoryCheckpoints) Load(name string) (persistance.ChangeCursor, bool, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	cursor, ok := m.checkpoints[name]
+	return cursor, ok, nil
+}
+
+func (m *MemoryCheckpoints) Save(name string, cursor persistance.ChangeCursor) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	m.checkpoints[name] = cursor
+	return nil
+}
+
+// FileCheckpoints keeps the checkpoints of all subscribers in one JSON file, replaced atomically on every save
+type FileCheckpoints struct {
+	mx          sync.Mutex
+	path        string
+	checkpoints map[string]persistance.ChangeCursor // nil until the file was read
+}
+
+func NewFileCheckpoints(path string) *FileCheckpoints {
+	return &FileCheckpoints{path: path}
+}
+
+func (f *FileCheckpoints) Load(name string) (persistance.ChangeCursor, bool, error) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	err := f.read()
+	if err != nil {
+		return persistance.ChangeCursor{}, false, err
+	}
+	cursor, ok := f.checkpoints[name]
+	return cursor, ok, nil
+}
+
+func (f *FileCheckpoints) Save(name string, cursor persistance.ChangeCursor) error {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	err := f.read()
+	if err != nil {
+		return err
+	}
+	previous, existed := f.checkpoints[name]
+	f.checkpoints[name] = cursor
+	data, err := json.Marshal(f.checkpoints)
+	if err == nil {
+		err = persistance.WriteFileAtomic(f.path, data)
+	}
+	if err != nil {
+		if existed {
+			f.checkpoints[name] = previous
+		} else {
+			delete(f.checkpoints, name)
+		}
+		return err
+	}
+	return nil
+}
+
+// read must be called with the lock held
+func (f *FileCheckpoints) read() error {
+	if f.checkpoints != nil {
+		return nil
+	}
+	checkpoints := map[string]persistance.ChangeCursor{}
+	data, err := os.ReadFile(f.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &checkpoints)
+		if err != nil {
+			return fmt.Errorf("corrupted change data capture checkpoints %s: %w", f.path, err)
+		}
+	}
+	f.checkpoints = checkpoints
+	return nil
+}
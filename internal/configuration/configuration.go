@@ -4,14 +4,53 @@ import (
 	"errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+	"immudb/internal/persistance"
+	"immudb/internal/readmodel"
+	"os"
+	"strings"
+	"time"
 )
 
+// legacyKeys are the top-level keys of older versions and the settings of the backend they moved to.
+// Their environment variables are legacy too, but IMMUDBAPIKEY, which still sets the api key of the vault.
+var legacyKeys = []struct {
+	key, movedTo string
+	envInUse     bool
+}{
+	{key: "ImmuDbUrl", movedTo: "Backends." + persistance.BackendImmudbVault + ".Url"},
+	{key: "ImmudbSearchUrl", movedTo: "Backends." + persistance.BackendImmudbVault + ".SearchUrl"},
+	{key: "ImmuDbApiKey", movedTo: "Backends." + persistance.BackendImmudbVault + ".ApiKey", envInUse: true},
+	{key: "DbConnectionString", movedTo: "Backends." + readmodel.BackendSQLReadThrough + ".ConnectionString"},
+}
+
 type ApplicationConfiguration struct {
-	DbConnectionString string
-	Port               string
-	ImmuDbUrl          string
-	ImmudbSearchUrl    string
-	ImmuDbApiKey       string // for simplicity i am taking this from here but normally with would be taken from env variable or some other  secret
+	Backend              string                    // the registered backend accounts are stored in, immudb-vault when empty
+	Backends             persistance.BackendBlocks // the settings block of every backend by name, only the chosen one is used
+	Port                 string
+	GrpcPort             string        // the gRPC api listens here, empty disables it
+	MetricsPort          string        // /debug/vars is served here, keep it off the public network, empty disables it
+	TamperAlarm          bool          // the backend must check its ledger against the trusted state, the startup fails on one that can't
+	AuditInterval        time.Duration // how often the background tamper audit runs, 0 disables it
+	AuditWindow          int           // how many recent transactions every audit walks
+	AuditSampleSize      int           // how many of the walked documents get their inclusion proof checked
+	AllowUpdates         bool          // accounts can be updated with PUT, guarded by If-Match
+	CacheSize            int           // how many accounts the lookup cache keeps, 0 disables it
+	CacheTTL             time.Duration // how long a cached account is served before it is read again
+	CoalesceReads        bool          // concurrent identical reads share one call to the backend
+	ImportDir            string        // where uploaded CSV imports and their error reports are kept, empty disables imports
+	GraphqlMaxDepth      int           // how deep the fields of a GraphQL query may nest, 0 uses the default of 8
+	GraphqlMaxComplexity int           // the highest cost of a GraphQL query, list fields count once per item, 0 uses the default of 2500
+	WebhookDir           string        // where webhook subscriptions and dead letters are kept, empty disables webhooks
+	WebhookMaxAttempts   int           // attempts before a delivery goes to the dead letters, 0 uses the default of 5
+	WebhookBackoff       time.Duration // wait after the first failed delivery, doubled after every further one, 0 uses the default of 10s
+	WebhookAllowedHosts  []string      // hosts, addresses and CIDR ranges webhooks may call although they are loopback, private or link-local
+	CdcInterval          time.Duration // how often the change data capture polls the ledger for writes of every instance, 0 disables it
+	CdcCheckpointPath    string        // where the change data capture keeps the position of its subscribers, empty keeps it in memory
+	EventReplayWindow    time.Duration // how old the first event a client resuming the event stream missed may be, 0 uses the default of 1h
+	OutboxPath           string        // the journal of the events waiting to be relayed, empty disables the outbox
+	OutboxSink           string        // where the outbox relays to: log, file or webhook
+	OutboxTarget         string        // the path of the file sink or the url of the webhook sink
+	OutboxSecret         string        // signs the payloads of the webhook sink, empty sends them unsigned
 }
 
 func LoadConfiguration() (*ApplicationConfiguration, error) {
@@ -29,10 +68,82 @@ func LoadConfiguration() (*ApplicationConfiguration, error) {
 		}
 	}
 	v.AutomaticEnv()
+	// the api key is a secret, it is kept out of the config file
+	err := v.BindEnv("Backends."+persistance.BackendImmudbVault+".ApiKey", "IMMUDBAPIKEY")
+	if err != nil {
+		return nil, err
+	}
+	return unmarshal(v)
+}
+
+// unmarshal refuses unknown keys, only the keys of older versions are moved to where they belong now
+func unmarshal(v *viper.Viper) (*ApplicationConfiguration, error) {
 	var configurations ApplicationConfiguration
-	err := v.UnmarshalExact(&configurations)
+	err := withoutLegacyKeys(v).UnmarshalExact(&configurations)
 	if err != nil {
 		return nil, err
 	}
 	return &configurations, nil
 }
+
+// withoutLegacyKeys copies the configuration with the value of every legacy key moved to its new key,
+// unless that one has a value of its own. Viper can't unset a key, so the copy is made without them.
+func withoutLegacyKeys(v *viper.Viper) *viper.Viper {
+	clean := viper.New()
+	for _, key := range v.AllKeys() {
+		if !isLegacyKey(key) {
+			clean.Set(key, v.Get(key))
+		}
+	}
+	for _, legacy := range legacyKeys {
+		_, inEnv := os.LookupEnv(strings.ToUpper(legacy.key))
+		if !v.InConfig(legacy.key) && (!inEnv || legacy.envInUse) {
+			continue
+		}
+		log := logrus.WithFields(logrus.Fields{"key": legacy.key, "movedTo": legacy.movedTo})
+		if v.GetString(legacy.movedTo) != "" {
+			log.Warn("the deprecated configuration key is ignored, its new key is set")
+			continue
+		}
+		log.Warn("the configuration key is deprecated, move it to its new key")
+		clean.Set(legacy.movedTo, v.Get(legacy.key))
+	}
+	return clean
+}
+
+func isLegacyKey(key string) bool {
+	for _, legacy := range legacyKeys {
+		if strings.EqualFold(key, legacy.key) {
+			return true
+		}
+	}
+	return false
+}
+
+// BackendName is the chosen backend, immudb-vault when none is configured
+func (c *ApplicationConfiguration) BackendName() string {
+	if c.Backend == "" {
+		return persistance.BackendImmudbVault
+	}
+	return c.Backend
+}
+
+// SetBackendSettings replaces the settings block of a backend, for overrides like the dev mode
+func (c *ApplicationConfiguration) SetBackendSettings(name string, settings map[string]interface{}) {
+	if c.Backends == nil {
+		c.Backends = persistance.BackendBlocks{}
+	}
+	c.Backends[name] = settings
+}
+
+// Validate is called once every override, like the dev mode, is applied
+func (c *ApplicationConfiguration) Validate() error {
+	_, err := c.Backends.Settings(c.BackendName())
+	if err != nil {
+		return err
+	}
+	if c.CacheSize > 0 && c.CacheTTL <= 0 {
+		return errors.New("CacheTTL must be positive when the cache is enabled")
+	}
+	return nil
+}
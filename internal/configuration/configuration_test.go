@@ -0,0 +1,73 @@
+package configuration
+
+import (
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshal_LegacyKeys(t *testing.T) {
+	tests := []struct {
+		name            string
+		config          string
+		expectedUrl     string
+		expectedApiKey  string
+		expectedSql     interface{}
+		isErrorExpected bool
+	}{
+		{
+			name: "Test_Moved",
+			config: `
+Port: 8080
+ImmuDbUrl: "https://old/document"
+ImmudbSearchUrl: "https://old/search"
+ImmuDbApiKey: "old-key"
+DbConnectionString: "user@tcp(db)/accounts"
+`,
+			expectedUrl:    "https://old/document",
+			expectedApiKey: "old-key",
+			expectedSql:    "user@tcp(db)/accounts",
+		},
+		{
+			name: "Test_New_Key_Wins",
+			config: `
+ImmuDbUrl: "https://old/document"
+ImmuDbApiKey: "old-key"
+Backends:
+  immudb-vault:
+    Url: "https://new/document"
+    ApiKey: ""
+`,
+			expectedUrl:    "https://new/document",
+			expectedApiKey: "old-key",
+		},
+		{
+			name:            "Test_Unknown_Key",
+			config:          `ImmuDbPort: 8080`,
+			isErrorExpected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			v := viper.New()
+			v.SetConfigType("yaml")
+			assert.NoError(t, v.ReadConfig(strings.NewReader(tt.config)))
+
+			// action
+			config, err := unmarshal(v)
+
+			// assertions
+			if tt.isErrorExpected {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			vault := config.Backends["immudb-vault"]
+			assert.Equal(t, tt.expectedUrl, vault["url"])
+			assert.Equal(t, tt.expectedApiKey, vault["apikey"])
+			assert.Equal(t, tt.expectedSql, config.Backends["sql-readthrough"]["connectionstring"])
+		})
+	}
+}
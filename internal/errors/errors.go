@@ -1,16 +1,53 @@
 package errors
 
-import "fmt"
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// problem types are relative uris, they are not meant to be dereferenced
+const (
+	TypeValidation        = "/problems/validation-error"
+	TypeProofVerification = "/problems/proof-verification-failed"
+)
+
+// InvalidParam describes a single request field that failed validation.
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
 
 type ServiceError struct {
-	msg    string
-	Status int
+	msg           string
+	Status        int
+	Type          string
+	InvalidParams []InvalidParam
 }
 
 func (r *ServiceError) Error() string {
-	return fmt.Sprintf("validation error: %v", r.msg)
+	return r.msg
 }
 
 func NewServiceError(msg string, status int) *ServiceError {
 	return &ServiceError{msg: msg, Status: status}
 }
+
+// NewValidationError builds a ServiceError that carries every invalid field, so callers can report all of them at once
+func NewValidationError(status int, params ...InvalidParam) *ServiceError {
+	reasons := make([]string, 0, len(params))
+	for _, param := range params {
+		reasons = append(reasons, param.Reason)
+	}
+	return &ServiceError{msg: strings.Join(reasons, "; "), Status: status, Type: TypeValidation, InvalidParams: params}
+}
+
+// NewVerificationError is returned when the ledger proofs do not match the data we received,
+// the data might have been tampered with so it must never be served as verified
+func NewVerificationError(format string, args ...interface{}) *ServiceError {
+	return &ServiceError{
+		msg:    fmt.Sprintf("proof verification failed: %s", fmt.Sprintf(format, args...)),
+		Status: http.StatusBadGateway,
+		Type:   TypeProofVerification,
+	}
+}
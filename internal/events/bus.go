@@ -0,0 +1,69 @@
+package events
+
+import (
+	"fmt"
+	"immudb/internal/models"
+	"sync"
+	"time"
+)
+
+// Publisher takes the events of committed writes
+type Publisher interface {
+	Publish(event models.AccountEvent)
+}
+
+// Bus hands every published event to the subscribers of this process
+type Bus struct {
+	mx          sync.RWMutex
+	subscribers map[int]func(models.AccountEvent)
+	next        int
+}
+
+func NewBus() *Bus {
+	return &Bus{subscribers: map[int]func(models.AccountEvent){}}
+}
+
+// Subscribe registers handler until the returned function is called.
+// Handlers run on the goroutine of the writer, they must hand slow work off instead of blocking.
+func (b *Bus) Subscribe(handler func(models.AccountEvent)) func() {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	id := b.next
+	b.next++
+	b.subscribers[id] = handler
+	return func() {
+		b.mx.Lock()
+		defer b.mx.Unlock()
+		delete(b.subscribers, id)
+	}
+}
+
+func (b *Bus) Publish(event models.AccountEvent) {
+	b.mx.RLock()
+	defer b.mx.RUnlock()
+	for _, handler := range b.subscribers {
+		handler(event)
+	}
+}
+
+// NewAccountEvent is the event of the revision the account was written as
+func NewAccountEvent(eventType models.EventType, account models.AccountInfo) models.AccountEvent {
+	event := models.AccountEvent{Id: EventId(account), Type: eventType, OccurredAt: time.Now().UTC(), Account: account}
+	if account.Meta != nil && !account.Meta.Timestamp.IsZero() {
+		event.OccurredAt = account.Meta.Timestamp
+	}
+	return event
+}
+
+// EventId is derived from the account and its revision, the same on every instance and every time the revision is read.
+// A revision the backend didn't report is told apart by its document and ledger time, which don't change either.
+func EventId(account models.AccountInfo) string {
+	if account.Meta != nil && account.Meta.Revision > 0 {
+		return fmt.Sprintf("%d-%d", account.Id, account.Meta.Revision)
+	}
+	var committedAt int64
+	if account.Meta != nil && !account.Meta.Timestamp.IsZero() {
+		committedAt = account.Meta.Timestamp.Unix()
+	}
+	return fmt.Sprintf("%d-%s-%d", account.Id, account.DocumentId, committedAt)
+}
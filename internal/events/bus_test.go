@@ -0,0 +1,73 @@
+package events
+
+import (
+	"github.com/stretchr/testify/assert"
+	"immudb/internal/models"
+	"testing"
+	"time"
+)
+
+func TestBus_Subscribe(t *testing.T) {
+	// setup
+	bus := NewBus()
+	var first, second []string
+	unsubscribe := bus.Subscribe(func(event models.AccountEvent) {
+		first = append(first, event.Id)
+	})
+	bus.Subscribe(func(event models.AccountEvent) {
+		second = append(second, event.Id)
+	})
+
+	// action
+	bus.Publish(models.AccountEvent{Id: "1-1"})
+	unsubscribe()
+	bus.Publish(models.AccountEvent{Id: "1-2"})
+
+	// assertions
+	assert.Equal(t, []string{"1-1"}, first)
+	assert.Equal(t, []string{"1-1", "1-2"}, second)
+}
+
+func TestNewAccountEvent(t *testing.T) {
+	committedAt := time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name               string
+		account            models.AccountInfo
+		expectedId         string
+		expectedOccurredAt time.Time
+	}{
+		{
+			name:               "Test_Revision",
+			account:            models.AccountInfo{Id: 3, Meta: &models.DocumentMeta{Revision: 2, Timestamp: committedAt}},
+			expectedId:         "3-2",
+			expectedOccurredAt: committedAt,
+		},
+		{
+			name:               "Test_Without_Revision",
+			account:            models.AccountInfo{Id: 3, DocumentId: "66af5b39", Meta: &models.DocumentMeta{Timestamp: committedAt}},
+			expectedId:         "3-66af5b39-1722506400",
+			expectedOccurredAt: committedAt,
+		},
+		{
+			name:       "Test_Without_Meta",
+			account:    models.AccountInfo{Id: 3, DocumentId: "66af5b39"},
+			expectedId: "3-66af5b39-0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// action
+			event := NewAccountEvent(models.AccountUpdated, tt.account)
+
+			// assertions
+			assert.Equal(t, models.AccountUpdated, event.Type)
+			assert.Equal(t, tt.expectedId, event.Id)
+			assert.Equal(t, tt.expectedId, NewAccountEvent(models.AccountUpdated, tt.account).Id, "the id must be the same every time")
+			if tt.expectedOccurredAt.IsZero() {
+				assert.False(t, event.OccurredAt.IsZero())
+				return
+			}
+			assert.Equal(t, tt.expectedOccurredAt, event.OccurredAt)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package events
+
+import (
+	"immudb/internal/models"
+	"time"
+)
+
+// Envelope is the JSON form events are sent out in
+type Envelope struct {
+	Id            string           `json:"id"`
+	Type          models.EventType `json:"type"`
+	OccurredAt    time.Time        `json:"occurred_at"`
+	TransactionId uint64           `json:"transaction_id,omitempty"`
+	Data          Account          `json:"data"`
+}
+
+// Account has the fields of the account api, with the revision the event is about
+type Account struct {
+	AccountNumber uint                `json:"account_number"`
+	DocumentId    string              `json:"document_id,omitempty"`
+	AccountName   string              `json:"account_name"`
+	Iban          string              `json:"iban"`
+	Address       *string             `json:"address"`
+	Amount        float64             `json:"amount"`
+	Type          *models.AccountType `json:"type"`
+	Revision      uint64              `json:"revision,omitempty"`
+}
+
+func NewEnvelope(event models.AccountEvent) Envelope {
+	account := event.Account
+	envelope := Envelope{
+		Id:         event.Id,
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+		Data: Account{
+			AccountNumber: account.Id,
+			DocumentId:    account.DocumentId,
+			AccountName:   account.Name,
+			Iban:          account.Iban,
+			Address:       account.Address,
+			Amount:        account.Amount,
+			Type:          account.Type,
+		},
+	}
+	if account.Meta != nil {
+		envelope.TransactionId = account.Meta.TransactionId
+		envelope.Data.Revision = account.Meta.Revision
+	}
+	return envelope
+}
@@ -0,0 +1,215 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	batchSize = 100
+	// listenerBuffer events a listener may fall behind before it is dropped
+	listenerBuffer = 256
+	// defaultReplayWindow how far back a client may resume when none is configured
+	defaultReplayWindow = time.Hour
+)
+
+// ErrReplayTooOld the client missed events older than the replay window, they are not replayed anymore
+var ErrReplayTooOld = errors.New("the events after the Last-Event-ID are older than the replay window, reconnect without it and reload the accounts")
+
+// Stream hands account events to live listeners. They are published by the change data capture only,
+// so listeners get the writes of every instance in ledger order and the id of an event is its position in the feed.
+type Stream struct {
+	feed         persistance.ChangeFeed
+	now          func() time.Time
+	ReplayWindow time.Duration // how old the first missed event of a resuming client may be
+
+	mx        sync.Mutex
+	listeners map[int]*listener
+	next      int
+}
+
+type listener struct {
+	types  []models.EventType // all types when empty
+	events chan StreamEvent
+}
+
+// StreamEvent is an event of the stream and the position of its revision in the change feed
+type StreamEvent struct {
+	models.AccountEvent
+	Cursor persistance.ChangeCursor
+}
+
+// NewStream replays missed events from feed
+func NewStream(feed persistance.ChangeFeed) *Stream {
+	return &Stream{
+		feed:         feed,
+		now:          time.Now,
+		ReplayWindow: defaultReplayWindow,
+		listeners:    map[int]*listener{},
+	}
+}
+
+// Publish hands a change of the change data capture to the listeners
+func (s *Stream) Publish(change persistance.Change) {
+	event := StreamEvent{AccountEvent: EventOf(change.Revision), Cursor: change.Cursor}
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	for id, l := range s.listeners {
+		if !matches(l.types, event.Type) {
+			continue
+		}
+		select {
+		case l.events <- event:
+		default:
+			// the listener can resume from its last event id
+			logrus.WithField("event", event.Id).Warn("event stream listener fell behind and was dropped")
+			delete(s.listeners, id)
+			close(l.events)
+		}
+	}
+}
+
+// Subscribe returns the live events of the given types, all types when none are given, until the returned function is called.
+// A listener that falls too far behind is dropped, its channel is closed.
+func (s *Stream) Subscribe(types []models.EventType) (<-chan StreamEvent, func()) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	id := s.next
+	s.next++
+	l := &listener{types: types, events: make(chan StreamEvent, listenerBuffer)}
+	s.listeners[id] = l
+	return l.events, func() {
+		s.mx.Lock()
+		defer s.mx.Unlock()
+		if _, ok := s.listeners[id]; ok {
+			delete(s.listeners, id)
+			close(l.events)
+		}
+	}
+}
+
+// Resumable fails with ErrReplayTooOld when the first change after the cursor is older than the replay window,
+// so a client can't make the stream read the whole ledger, the bare transaction id 0 included
+func (s *Stream) Resumable(ctx context.Context, cursor persistance.ChangeCursor) error {
+	changes, err := s.feed.ChangesSince(ctx, cursor, 1)
+	if err != nil {
+		return err
+	}
+	if len(changes) > 0 && changes[0].Cursor.Timestamp.Before(s.now().Add(-s.ReplayWindow)) {
+		return ErrReplayTooOld
+	}
+	return nil
+}
+
+// Replay writes the events of the given types committed after the cursor, oldest first,
+// and returns the cursor of the last revision it read
+func (s *Stream) Replay(ctx context.Context, cursor persistance.ChangeCursor, types []models.EventType, write func(event StreamEvent) error) (persistance.ChangeCursor, error) {
+	for {
+		changes, err := s.feed.ChangesSince(ctx, cursor, batchSize)
+		if err != nil {
+			return cursor, err
+		}
+		for _, change := range changes {
+			event := StreamEvent{AccountEvent: EventOf(change.Revision), Cursor: change.Cursor}
+			cursor = change.Cursor
+			if !matches(types, event.Type) {
+				continue
+			}
+			err = write(event)
+			if err != nil {
+				return cursor, err
+			}
+		}
+		if len(changes) < batchSize {
+			return cursor, nil
+		}
+	}
+}
+
+// StreamId is the id of an event in the stream, the position of its revision in the change feed: transaction,
+// ledger time, revision, the time the vault feed settled at and the document id, the times in nanoseconds.
+// The ledger time lets the vault search only what changed since when a client resumes from it.
+func StreamId(cursor persistance.ChangeCursor) string {
+	return fmt.Sprintf("%d-%d-%d-%d-%s", cursor.TransactionId, unixNano(cursor.Timestamp), cursor.Revision, unixNano(cursor.Since), cursor.DocumentId)
+}
+
+// ParseStreamId reads the cursor back from a stream id. The bare transaction id older streams sent stands for
+// every document of that transaction, it is read as the cursor before the first document of the next one.
+// The transaction, ledger time and document id they sent later stand for every revision of the document in it.
+func ParseStreamId(id string) (persistance.ChangeCursor, error) {
+	parts := strings.SplitN(id, "-", 5)
+	transactionId, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return persistance.ChangeCursor{}, err
+	}
+	if len(parts) == 1 {
+		return persistance.ChangeCursor{TransactionId: transactionId + 1}, nil
+	}
+	if len(parts) != 3 && len(parts) != 5 || parts[len(parts)-1] == "" {
+		return persistance.ChangeCursor{}, errors.New("a stream id is the transaction, the ledger time, the revision, the settled time and the document id")
+	}
+	cursor := persistance.ChangeCursor{TransactionId: transactionId, DocumentId: parts[len(parts)-1], Revision: math.MaxUint64}
+	cursor.Timestamp, err = parseUnixNano(parts[1])
+	if err != nil {
+		return persistance.ChangeCursor{}, err
+	}
+	if len(parts) == 3 {
+		return cursor, nil
+	}
+	cursor.Revision, err = strconv.ParseUint(parts[2], 10, 64)
+	if err != nil {
+		return persistance.ChangeCursor{}, err
+	}
+	cursor.Since, err = parseUnixNano(parts[3])
+	if err != nil {
+		return persistance.ChangeCursor{}, err
+	}
+	return cursor, nil
+}
+
+// unixNano is 0 for the zero time, which has no nanoseconds since 1970 that fit
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func parseUnixNano(value string) (time.Time, error) {
+	nanos, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || nanos == 0 {
+		return time.Time{}, err
+	}
+	return time.Unix(0, nanos).UTC(), nil
+}
+
+// EventOf is the event a revision of the change feed stands for, the first revision created the account
+func EventOf(revision *models.AccountInfo) models.AccountEvent {
+	eventType := models.AccountUpdated
+	if revision.Meta == nil || revision.Meta.Revision <= 1 {
+		eventType = models.AccountCreated
+	}
+	return NewAccountEvent(eventType, *revision)
+}
+
+// TransactionId of the revision the event is about, 0 when the backend didn't report it
+func TransactionId(event models.AccountEvent) uint64 {
+	if event.Account.Meta == nil {
+		return 0
+	}
+	return event.Account.Meta.TransactionId
+}
+
+func matches(types []models.EventType, eventType models.EventType) bool {
+	return len(types) == 0 || slices.Contains(types, eventType)
+}
@@ -0,0 +1,216 @@
+package events
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"math"
+	"testing"
+	"time"
+)
+
+func newAccount(name string) models.AccountInfo {
+	return models.AccountInfo{Name: name, Iban: "AL47", Type: persistance.AddPointer(models.Sending)}
+}
+
+func TestStream_Publish(t *testing.T) {
+	// setup
+	ctx := context.Background()
+	db := persistance.NewMemoryDB()
+	stream := NewStream(db)
+	live, unsubscribe := stream.Subscribe(nil)
+	defer unsubscribe()
+	updates, unsubscribeUpdates := stream.Subscribe([]models.EventType{models.AccountUpdated})
+	defer unsubscribeUpdates()
+	local, err := db.CreateAccountInfo(ctx, newAccount("local"))
+	assert.NoError(t, err)
+	remote, err := db.UpdateAccountInfo(ctx, local.Id, 1, newAccount("remote"))
+	assert.NoError(t, err)
+
+	// action
+	stream.Publish(persistance.Change{Cursor: persistance.CursorOf(local), Revision: local})
+	stream.Publish(persistance.Change{Cursor: persistance.CursorOf(remote), Revision: remote})
+
+	// assertions
+	assert.Equal(t, []string{"local", "remote"}, drain(live))
+	assert.Equal(t, []string{"remote"}, drain(updates))
+}
+
+func TestStream_Replay(t *testing.T) {
+	// setup
+	ctx := context.Background()
+	db := persistance.NewMemoryDB()
+	var created []*models.AccountInfo
+	for _, name := range []string{"first", "second", "third"} {
+		account, err := db.CreateAccountInfo(ctx, newAccount(name))
+		assert.NoError(t, err)
+		created = append(created, account)
+	}
+	_, err := db.UpdateAccountInfo(ctx, created[0].Id, 1, newAccount("first again"))
+	assert.NoError(t, err)
+	stream := NewStream(db)
+	tests := []struct {
+		name     string
+		cursor   persistance.ChangeCursor
+		types    []models.EventType
+		expected []string
+	}{
+		{
+			name:     "Test_All",
+			cursor:   persistance.CursorOf(created[0]),
+			expected: []string{"second", "third", "first again"},
+		},
+		{
+			name:     "Test_Filtered",
+			cursor:   persistance.CursorOf(created[0]),
+			types:    []models.EventType{models.AccountCreated},
+			expected: []string{"second", "third"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// action
+			var names []string
+			last, err := stream.Replay(ctx, tt.cursor, tt.types, func(event StreamEvent) error {
+				names = append(names, event.Account.Name)
+				return nil
+			})
+
+			// assertions
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, names)
+			assert.True(t, persistance.CursorOf(created[2]).Before(last), "the cursor must move past filtered events too")
+		})
+	}
+}
+
+func TestStream_Resumable(t *testing.T) {
+	// setup
+	ctx := context.Background()
+	db := persistance.NewMemoryDB()
+	var created []*models.AccountInfo
+	for _, name := range []string{"first", "second"} {
+		account, err := db.CreateAccountInfo(ctx, newAccount(name))
+		assert.NoError(t, err)
+		created = append(created, account)
+	}
+	stream := NewStream(db)
+	stream.now = func() time.Time { return created[1].Meta.Timestamp.Add(stream.ReplayWindow) }
+	tests := []struct {
+		name          string
+		cursor        persistance.ChangeCursor
+		expectedError error
+	}{
+		{
+			name:   "Test_Missed_Events_In_The_Window",
+			cursor: persistance.CursorOf(created[0]),
+		},
+		{
+			name:   "Test_Nothing_Missed",
+			cursor: persistance.CursorOf(created[1]),
+		},
+		{
+			// the bare transaction id 0 would replay the whole ledger
+			name:          "Test_Whole_Ledger",
+			cursor:        persistance.ChangeCursor{TransactionId: 1},
+			expectedError: ErrReplayTooOld,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// action
+			err := stream.Resumable(ctx, tt.cursor)
+
+			// assertions
+			assert.Equal(t, tt.expectedError, err)
+		})
+	}
+}
+
+func TestParseStreamId(t *testing.T) {
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	account := models.AccountInfo{Id: 7, DocumentId: "65a1b2c3", Meta: &models.DocumentMeta{Revision: 2, TransactionId: 12, Timestamp: timestamp}}
+	vault := persistance.ChangeCursor{DocumentId: "65a1b2c3", Timestamp: timestamp, Revision: 3, Since: timestamp.Add(-time.Second)}
+	tests := []struct {
+		name            string
+		id              string
+		expected        persistance.ChangeCursor
+		isErrorExpected bool
+	}{
+		{
+			name:     "Test_Validity",
+			id:       StreamId(persistance.CursorOf(&account)),
+			expected: persistance.CursorOf(&account),
+		},
+		{
+			name:     "Test_Vault_Position",
+			id:       StreamId(vault),
+			expected: vault,
+		},
+		{
+			// ids sent before the revision was part of them stand for the whole document in that transaction
+			name:     "Test_Without_Revision",
+			id:       "12-1704164645000000006-65a1b2c3",
+			expected: persistance.ChangeCursor{TransactionId: 12, DocumentId: "65a1b2c3", Timestamp: timestamp, Revision: math.MaxUint64},
+		},
+		{
+			name:     "Test_Transaction_Id",
+			id:       "12",
+			expected: persistance.ChangeCursor{TransactionId: 13},
+		},
+		{
+			name:            "Test_No_Document",
+			id:              "12-1704164645000000006",
+			isErrorExpected: true,
+		},
+		{
+			name:            "Test_Invalid",
+			id:              "yesterday",
+			isErrorExpected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// action
+			cursor, err := ParseStreamId(tt.id)
+
+			// assertions
+			assert.Equal(t, tt.isErrorExpected, err != nil, err)
+			assert.Equal(t, tt.expected, cursor)
+		})
+	}
+}
+
+func TestStream_DropsSlowListeners(t *testing.T) {
+	// setup
+	stream := NewStream(persistance.NewMemoryDB())
+	live, unsubscribe := stream.Subscribe(nil)
+	defer unsubscribe()
+
+	// action
+	for i := 0; i <= listenerBuffer; i++ {
+		stream.Publish(persistance.Change{Revision: &models.AccountInfo{Id: uint(i + 1)}})
+	}
+
+	// assertions
+	assert.Len(t, drain(live), listenerBuffer)
+	_, open := <-live
+	assert.False(t, open)
+}
+
+// drain reads what is buffered without waiting for more
+func drain(events <-chan StreamEvent) []string {
+	var names []string
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return names
+			}
+			names = append(names, event.Account.Name)
+		default:
+			return names
+		}
+	}
+}
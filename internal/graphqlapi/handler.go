@@ -0,0 +1,103 @@
+package graphqlapi
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"immudb/internal/services"
+	"net/http"
+)
+
+type request struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// NewHandler serves POST with a json body, and GET with query parameters for queries only
+func NewHandler(service services.Service, limits Limits) (gin.HandlerFunc, error) {
+	schema, err := NewSchema(service)
+	if err != nil {
+		return nil, err
+	}
+	limits = limits.withDefaults()
+	return func(c *gin.Context) {
+		body, err := readRequest(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+			return
+		}
+		document, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{
+			Body: []byte(body.Query),
+			Name: "GraphQL request",
+		})})
+		if err != nil {
+			c.JSON(http.StatusBadRequest, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+			return
+		}
+		validation := graphql.ValidateDocument(&schema, document, nil)
+		if !validation.IsValid {
+			c.JSON(http.StatusBadRequest, &graphql.Result{Errors: validation.Errors})
+			return
+		}
+		err = limits.check(document, body.OperationName, body.Variables)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+			return
+		}
+		if c.Request.Method == http.MethodGet && isMutation(document, body.OperationName) {
+			c.Header("Allow", http.MethodPost)
+			c.JSON(http.StatusMethodNotAllowed, &graphql.Result{Errors: gqlerrors.FormatErrors(errors.New("mutations need a POST"))})
+			return
+		}
+		result := graphql.Execute(graphql.ExecuteParams{
+			Schema:        schema,
+			AST:           document,
+			OperationName: body.OperationName,
+			Args:          body.Variables,
+			Context:       c.Request.Context(),
+		})
+		c.JSON(http.StatusOK, result)
+	}, nil
+}
+
+func readRequest(c *gin.Context) (*request, error) {
+	body := &request{}
+	if c.Request.Method == http.MethodGet {
+		body.Query = c.Query("query")
+		body.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			err := json.Unmarshal([]byte(variables), &body.Variables)
+			if err != nil {
+				return nil, errors.New("variables must be a json object")
+			}
+		}
+	} else {
+		err := json.NewDecoder(c.Request.Body).Decode(body)
+		if err != nil {
+			return nil, errors.New("the body must be a json object with a query")
+		}
+	}
+	if body.Query == "" {
+		return nil, errors.New("query is required")
+	}
+	return body, nil
+}
+
+func isMutation(document *ast.Document, operationName string) bool {
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if operationName == "" || (operation.Name != nil && operation.Name.Value == operationName) {
+			return operation.Operation == ast.OperationTypeMutation
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package graphqlapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"immudb/internal/persistance"
+	"immudb/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+type response struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func newRouter(t *testing.T) *gin.Engine {
+	service, err := services.NewService(persistance.NewMemoryDB())
+	assert.NoError(t, err)
+	handler, err := NewHandler(service, Limits{MaxDepth: 5, MaxComplexity: 200})
+	assert.NoError(t, err)
+	router := gin.New()
+	router.GET("/graphql", handler)
+	router.POST("/graphql", handler)
+	return router
+}
+
+func post(t *testing.T, router *gin.Engine, query string, variables map[string]interface{}) (int, response) {
+	body, err := json.Marshal(request{Query: query, Variables: variables})
+	assert.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)))
+	var result response
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+	return recorder.Code, result
+}
+
+const createMutation = `mutation($name: String!) {
+  createAccount(input: {accountName: $name, iban: "AL47", amount: 10.5, type: SENDING}) { accountNumber accountName type revision { revision } }
+}`
+
+func TestHandler_CreateAndQuery(t *testing.T) {
+	// setup
+	router := newRouter(t)
+	var accountNumbers []string
+	for _, name := range []string{"first", "second", "third"} {
+		status, result := post(t, router, createMutation, map[string]interface{}{"name": name})
+		assert.Equal(t, http.StatusOK, status)
+		assert.Empty(t, result.Errors)
+		created := result.Data["createAccount"].(map[string]interface{})
+		assert.Equal(t, name, created["accountName"])
+		assert.Equal(t, "SENDING", created["type"])
+		accountNumbers = append(accountNumbers, created["accountNumber"].(string))
+	}
+
+	// action
+	_, firstPage := post(t, router, `{ accounts(first: 2) { edges { node { accountName } } pageInfo { hasNextPage hasPreviousPage endCursor } } }`, nil)
+	pageInfo := firstPage.Data["accounts"].(map[string]interface{})["pageInfo"].(map[string]interface{})
+	_, secondPage := post(t, router, `query($after: String) { accounts(first: 2, after: $after) { edges { node { accountName } } pageInfo { hasNextPage hasPreviousPage } } }`,
+		map[string]interface{}{"after": pageInfo["endCursor"]})
+	_, single := post(t, router, `query($id: ID!) { account(accountNumber: $id) { accountName history { revision { revision } } } }`,
+		map[string]interface{}{"id": accountNumbers[1]})
+	_, missing := post(t, router, `{ account(accountNumber: "42") { accountName } }`, nil)
+
+	// assertions
+	assert.Equal(t, []string{"first", "second"}, names(firstPage))
+	assert.Equal(t, true, pageInfo["hasNextPage"])
+	assert.Equal(t, false, pageInfo["hasPreviousPage"])
+	assert.Equal(t, []string{"third"}, names(secondPage))
+	secondInfo := secondPage.Data["accounts"].(map[string]interface{})["pageInfo"].(map[string]interface{})
+	assert.Equal(t, false, secondInfo["hasNextPage"])
+	assert.Equal(t, true, secondInfo["hasPreviousPage"])
+	account := single.Data["account"].(map[string]interface{})
+	assert.Equal(t, "second", account["accountName"])
+	assert.Len(t, account["history"], 1)
+	assert.Empty(t, missing.Errors)
+	assert.Nil(t, missing.Data["account"])
+}
+
+func names(result response) []string {
+	var output []string
+	for _, edge := range result.Data["accounts"].(map[string]interface{})["edges"].([]interface{}) {
+		output = append(output, edge.(map[string]interface{})["node"].(map[string]interface{})["accountName"].(string))
+	}
+	return output
+}
+
+func TestHandler_Errors(t *testing.T) {
+	router := newRouter(t)
+	tests := []struct {
+		name            string
+		query           string
+		expectedStatus  int
+		expectedMessage string
+		expectedCode    interface{}
+	}{
+		{
+			name:            "Test_Invalid_Account",
+			query:           `mutation { createAccount(input: {accountName: "John", iban: "", type: SENDING}) { accountNumber } }`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: "invalid iban for the account",
+			expectedCode:    "BAD_USER_INPUT",
+		},
+		{
+			name:            "Test_Filter_Without_Read_Model",
+			query:           `{ accounts(filter: {text: "john"}) { edges { cursor } } }`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: "search needs the read model, it is not configured",
+			expectedCode:    "NOT_IMPLEMENTED",
+		},
+		{
+			name:            "Test_First_Too_Big",
+			query:           `{ accounts(first: 51) { pageInfo { hasNextPage } } }`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: "first must be between 1 and 50",
+			expectedCode:    "BAD_USER_INPUT",
+		},
+		{
+			name:            "Test_Invalid_Cursor",
+			query:           `{ accounts(after: "nope") { pageInfo { hasNextPage } } }`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: "after is not a cursor of this connection",
+			expectedCode:    "BAD_USER_INPUT",
+		},
+		{
+			name:            "Test_Unknown_Field",
+			query:           `{ accounts { total } }`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: `Cannot query field "total" on type "AccountConnection".`,
+		},
+		{
+			name:            "Test_Too_Deep",
+			query:           `{ account(accountNumber: "1") { history { history { history { history { accountName } } } } } }`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "the query is 6 levels deep, the limit is 5",
+		},
+		{
+			name:            "Test_Too_Complex",
+			query:           `{ accounts(first: 50) { edges { node { accountName iban } } } }`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "the query has a complexity of 201, the limit is 200",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// action
+			status, result := post(t, router, tt.query, nil)
+
+			// assertions
+			assert.Equal(t, tt.expectedStatus, status)
+			assert.Len(t, result.Errors, 1)
+			assert.Equal(t, tt.expectedMessage, result.Errors[0].Message)
+			assert.Equal(t, tt.expectedCode, result.Errors[0].Extensions["code"])
+		})
+	}
+}
+
+func TestHandler_GetRefusesMutations(t *testing.T) {
+	// setup
+	router := newRouter(t)
+	recorder := httptest.NewRecorder()
+	query := url.Values{"query": {`mutation { createAccount(input: {accountName: "a", iban: "b", type: SENDING}) { accountNumber } }`}}
+
+	// action
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/graphql?"+query.Encode(), nil))
+
+	// assertions
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	assert.Equal(t, http.MethodPost, recorder.Header().Get("Allow"))
+}
@@ -0,0 +1,129 @@
+package graphqlapi
+
+import (
+	"fmt"
+	"github.com/graphql-go/graphql/language/ast"
+	"strconv"
+	"strings"
+)
+
+const (
+	// historyCost is what the children of a history are multiplied by, accounts rarely have more revisions
+	historyCost          = 10
+	defaultMaxDepth      = 8
+	defaultMaxComplexity = 2500
+)
+
+// Limits reject expensive queries before anything is resolved, a limit of 0 uses the default
+type Limits struct {
+	MaxDepth      int // how deep fields may nest, the top level fields are at depth 1
+	MaxComplexity int // every field costs 1, the fields of a list cost once per expected item
+}
+
+func (l Limits) withDefaults() Limits {
+	if l.MaxDepth <= 0 {
+		l.MaxDepth = defaultMaxDepth
+	}
+	if l.MaxComplexity <= 0 {
+		l.MaxComplexity = defaultMaxComplexity
+	}
+	return l
+}
+
+// check measures the operation that will run, introspection fields are free so tools can load the schema
+func (l Limits) check(document *ast.Document, operationName string, variables map[string]interface{}) error {
+	fragments := map[string]*ast.FragmentDefinition{}
+	var operation *ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operation = definition
+			}
+		}
+	}
+	if operation == nil {
+		return nil
+	}
+	measure := &measure{fragments: fragments, variables: variables}
+	complexity, depth := measure.selections(operation.SelectionSet, 1)
+	if depth > l.MaxDepth {
+		return fmt.Errorf("the query is %d levels deep, the limit is %d", depth, l.MaxDepth)
+	}
+	if complexity > l.MaxComplexity {
+		return fmt.Errorf("the query has a complexity of %d, the limit is %d", complexity, l.MaxComplexity)
+	}
+	return nil
+}
+
+type measure struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// selections returns the cost of the set and the depth of its deepest field, depth is the one of the fields in it
+func (m *measure) selections(set *ast.SelectionSet, depth int) (int, int) {
+	if set == nil {
+		return 0, depth - 1
+	}
+	complexity, deepest := 0, depth-1
+	for _, selection := range set.Selections {
+		var cost, reached int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name.Value, "__") {
+				continue
+			}
+			childCost, childDepth := m.selections(selection.SelectionSet, depth+1)
+			cost, reached = 1+m.multiplier(selection)*childCost, max(depth, childDepth)
+		case *ast.InlineFragment:
+			cost, reached = m.selections(selection.SelectionSet, depth)
+		case *ast.FragmentSpread:
+			fragment, ok := m.fragments[selection.Name.Value]
+			if !ok {
+				continue
+			}
+			cost, reached = m.selections(fragment.SelectionSet, depth)
+		}
+		complexity += cost
+		deepest = max(deepest, reached)
+	}
+	return complexity, deepest
+}
+
+// multiplier is how many times the children of the field are expected to be resolved
+func (m *measure) multiplier(field *ast.Field) int {
+	switch field.Name.Value {
+	case "accounts":
+		first := defaultFirst
+		for _, argument := range field.Arguments {
+			if argument.Name.Value == "first" {
+				first = m.intValue(argument.Value, first)
+			}
+		}
+		return max(first, 1)
+	case "history":
+		return historyCost
+	}
+	return 1
+}
+
+func (m *measure) intValue(value ast.Value, fallback int) int {
+	switch value := value.(type) {
+	case *ast.IntValue:
+		parsed, err := strconv.Atoi(value.Value)
+		if err == nil {
+			return parsed
+		}
+	case *ast.Variable:
+		switch variable := m.variables[value.Name.Value].(type) {
+		case float64:
+			return int(variable)
+		case int:
+			return variable
+		}
+	}
+	return fallback
+}
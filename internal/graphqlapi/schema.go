@@ -0,0 +1,426 @@
+// Package graphqlapi serves account queries over GraphQL on top of the account service,
+// so clients pick the fields they need and only pay for the history when they ask for it.
+package graphqlapi
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/graphql-go/graphql"
+	"github.com/sirupsen/logrus"
+	cerror "immudb/internal/errors"
+	"immudb/internal/models"
+	"immudb/internal/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultFirst = 20
+	// maxFirst keeps first+1, the page that tells if there is a next one, within the search page size
+	maxFirst     = 50
+	cursorPrefix = "offset:"
+)
+
+// NewSchema builds the schema, the resolvers call service
+func NewSchema(service services.Service) (graphql.Schema, error) {
+	r := &resolver{service: service}
+
+	accountType := graphql.NewEnum(graphql.EnumConfig{
+		Name: "AccountType",
+		Values: graphql.EnumValueConfigMap{
+			"SENDING":   &graphql.EnumValueConfig{Value: models.Sending},
+			"RECEIVING": &graphql.EnumValueConfig{Value: models.Receiving},
+		},
+	})
+	revision := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Revision",
+		Description: "Ledger metadata of the revision an account was read as",
+		Fields: graphql.Fields{
+			"revision":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"transactionId": &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"timestamp":     &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"creator":       &graphql.Field{Type: graphql.String},
+		},
+	})
+	var account *graphql.Object
+	account = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Account",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"accountNumber": &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+				"documentId":    &graphql.Field{Type: graphql.String},
+				"accountName":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"iban":          &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"address":       &graphql.Field{Type: graphql.String},
+				"amount":        &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+				"type":          &graphql.Field{Type: accountType},
+				"revision":      &graphql.Field{Type: revision},
+				"history": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(account))),
+					Description: "Every revision of the account, oldest first",
+					Resolve:     r.history,
+				},
+			}
+		}),
+	})
+	pageInfo := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage":     &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"hasPreviousPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"startCursor":     &graphql.Field{Type: graphql.String},
+			"endCursor":       &graphql.Field{Type: graphql.String},
+		},
+	})
+	edge := graphql.NewObject(graphql.ObjectConfig{
+		Name: "AccountEdge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"node":   &graphql.Field{Type: graphql.NewNonNull(account)},
+		},
+	})
+	connection := graphql.NewObject(graphql.ObjectConfig{
+		Name: "AccountConnection",
+		Fields: graphql.Fields{
+			"edges":    &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edge)))},
+			"pageInfo": &graphql.Field{Type: graphql.NewNonNull(pageInfo)},
+		},
+	})
+	filter := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "AccountFilter",
+		Description: "Filters need the read model, like the search endpoint",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"text":      &graphql.InputObjectFieldConfig{Type: graphql.String, Description: "Full text over name, iban and address"},
+			"type":      &graphql.InputObjectFieldConfig{Type: accountType},
+			"minAmount": &graphql.InputObjectFieldConfig{Type: graphql.Float},
+			"maxAmount": &graphql.InputObjectFieldConfig{Type: graphql.Float},
+		},
+	})
+	createInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "CreateAccountInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"accountName": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"iban":        &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"address":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"amount":      &graphql.InputObjectFieldConfig{Type: graphql.Float},
+			"type":        &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(accountType)},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"accounts": &graphql.Field{
+				Type:        graphql.NewNonNull(connection),
+				Description: fmt.Sprintf("Accounts in the order they were created, first is at most %d", maxFirst),
+				Args: graphql.FieldConfigArgument{
+					"first":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultFirst},
+					"after":  &graphql.ArgumentConfig{Type: graphql.String},
+					"filter": &graphql.ArgumentConfig{Type: filter},
+				},
+				Resolve: r.accounts,
+			},
+			"account": &graphql.Field{
+				Type: account,
+				Args: graphql.FieldConfigArgument{
+					"accountNumber": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: r.account,
+			},
+		},
+	})
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createAccount": &graphql.Field{
+				Type: graphql.NewNonNull(account),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(createInput)},
+				},
+				Resolve: r.createAccount,
+			},
+		},
+	})
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+type resolver struct {
+	service services.Service
+}
+
+// accountResult is what the Account fields resolve from
+type accountResult struct {
+	AccountNumber string          `json:"accountNumber"`
+	DocumentId    string          `json:"documentId"`
+	AccountName   string          `json:"accountName"`
+	Iban          string          `json:"iban"`
+	Address       *string         `json:"address"`
+	Amount        float64         `json:"amount"`
+	Type          interface{}     `json:"type"`
+	Revision      *revisionResult `json:"revision"`
+	id            uint
+}
+
+type revisionResult struct {
+	Revision      uint64      `json:"revision"`
+	TransactionId string      `json:"transactionId"`
+	Timestamp     interface{} `json:"timestamp"`
+	Creator       string      `json:"creator"`
+}
+
+type edgeResult struct {
+	Cursor string         `json:"cursor"`
+	Node   *accountResult `json:"node"`
+}
+
+type pageInfoResult struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor"`
+	EndCursor       *string `json:"endCursor"`
+}
+
+type connectionResult struct {
+	Edges    []edgeResult   `json:"edges"`
+	PageInfo pageInfoResult `json:"pageInfo"`
+}
+
+func (r *resolver) accounts(p graphql.ResolveParams) (interface{}, error) {
+	first, _ := p.Args["first"].(int)
+	if first < 1 || first > maxFirst {
+		return nil, newInputError(cerror.InvalidParam{Name: "first", Reason: fmt.Sprintf("first must be between 1 and %d", maxFirst)})
+	}
+	offset := 0
+	if after, ok := p.Args["after"].(string); ok {
+		position, err := decodeCursor(after)
+		if err != nil {
+			return nil, newInputError(cerror.InvalidParam{Name: "after", Reason: "after is not a cursor of this connection"})
+		}
+		offset = position + 1
+	}
+	fetch := func(page, pageSize int) ([]*models.AccountInfo, error) {
+		return r.service.GetAllAccountInfos(p.Context, page, pageSize)
+	}
+	if filter, ok := p.Args["filter"].(map[string]interface{}); ok {
+		search := toAccountSearch(filter)
+		fetch = func(page, pageSize int) ([]*models.AccountInfo, error) {
+			search.Page, search.PageSize = page, pageSize
+			return r.service.SearchAccountInfos(p.Context, search)
+		}
+	}
+	// one more than asked tells if there is a next page
+	items, err := window(fetch, offset, first+1)
+	if err != nil {
+		return nil, toGraphqlError(err, "failed to load accountInfos")
+	}
+	result := connectionResult{Edges: []edgeResult{}, PageInfo: pageInfoResult{HasPreviousPage: offset > 0}}
+	if len(items) > first {
+		items = items[:first]
+		result.PageInfo.HasNextPage = true
+	}
+	for i, item := range items {
+		result.Edges = append(result.Edges, edgeResult{Cursor: encodeCursor(offset + i), Node: toAccountResult(item)})
+	}
+	if len(result.Edges) > 0 {
+		result.PageInfo.StartCursor = &result.Edges[0].Cursor
+		result.PageInfo.EndCursor = &result.Edges[len(result.Edges)-1].Cursor
+	}
+	return result, nil
+}
+
+func (r *resolver) account(p graphql.ResolveParams) (interface{}, error) {
+	id, err := parseAccountNumber(p.Args["accountNumber"])
+	if err != nil {
+		return nil, err
+	}
+	result, err := r.service.GetAccountInfoById(p.Context, id)
+	var serviceError *cerror.ServiceError
+	if errors.As(err, &serviceError) && serviceError.Status == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, toGraphqlError(err, "failed to load accountInfo")
+	}
+	return toAccountResult(result), nil
+}
+
+func (r *resolver) history(p graphql.ResolveParams) (interface{}, error) {
+	parent, ok := p.Source.(*accountResult)
+	if !ok {
+		return nil, errors.New("history needs an account")
+	}
+	revisions, err := r.service.GetAccountInfoHistory(p.Context, parent.id)
+	if err != nil {
+		return nil, toGraphqlError(err, "failed to load the history")
+	}
+	output := make([]*accountResult, 0, len(revisions))
+	for _, revision := range revisions {
+		output = append(output, toAccountResult(revision))
+	}
+	return output, nil
+}
+
+func (r *resolver) createAccount(p graphql.ResolveParams) (interface{}, error) {
+	input, _ := p.Args["input"].(map[string]interface{})
+	data := &models.AccountInfo{}
+	data.Name, _ = input["accountName"].(string)
+	data.Iban, _ = input["iban"].(string)
+	data.Amount, _ = input["amount"].(float64)
+	if address, ok := input["address"].(string); ok {
+		data.Address = &address
+	}
+	if accountType, ok := input["type"].(models.AccountType); ok {
+		data.Type = &accountType
+	}
+	created, err := r.service.CreateAccountInfo(p.Context, data)
+	if err != nil {
+		return nil, toGraphqlError(err, "failed to create accountInfo")
+	}
+	return toAccountResult(created), nil
+}
+
+// window returns limit items from offset on, reading the pages of that size that overlap it
+func window(fetch func(page, pageSize int) ([]*models.AccountInfo, error), offset, limit int) ([]*models.AccountInfo, error) {
+	page, skip := offset/limit+1, offset%limit
+	items, err := fetch(page, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) <= skip {
+		return nil, nil
+	}
+	full := len(items) == limit
+	items = items[skip:]
+	if skip > 0 && full {
+		next, err := fetch(page+1, limit)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, next...)
+	}
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func toAccountSearch(filter map[string]interface{}) models.AccountSearch {
+	search := models.AccountSearch{}
+	search.Text, _ = filter["text"].(string)
+	if accountType, ok := filter["type"].(models.AccountType); ok {
+		search.Type = &accountType
+	}
+	if minAmount, ok := filter["minAmount"].(float64); ok {
+		search.MinAmount = &minAmount
+	}
+	if maxAmount, ok := filter["maxAmount"].(float64); ok {
+		search.MaxAmount = &maxAmount
+	}
+	return search
+}
+
+func toAccountResult(info *models.AccountInfo) *accountResult {
+	output := &accountResult{
+		AccountNumber: strconv.FormatUint(uint64(info.Id), 10),
+		DocumentId:    info.DocumentId,
+		AccountName:   info.Name,
+		Iban:          info.Iban,
+		Address:       info.Address,
+		Amount:        info.Amount,
+		id:            info.Id,
+	}
+	if info.Type != nil {
+		output.Type = *info.Type
+	}
+	if info.Meta != nil {
+		output.Revision = &revisionResult{
+			Revision:      info.Meta.Revision,
+			TransactionId: strconv.FormatUint(info.Meta.TransactionId, 10),
+			Timestamp:     info.Meta.Timestamp,
+			Creator:       info.Meta.Creator,
+		}
+	}
+	return output
+}
+
+func parseAccountNumber(value interface{}) (uint, error) {
+	text, _ := value.(string)
+	id, err := strconv.ParseUint(text, 10, 0)
+	if err != nil || id == 0 {
+		return 0, newInputError(cerror.InvalidParam{Name: "accountNumber", Reason: "accountNumber must be a positive number"})
+	}
+	return uint(id), nil
+}
+
+func encodeCursor(position int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(position)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	position, ok := strings.CutPrefix(string(decoded), cursorPrefix)
+	if !ok {
+		return 0, errors.New("unknown cursor")
+	}
+	value, err := strconv.Atoi(position)
+	if err != nil || value < 0 {
+		return 0, errors.New("unknown cursor")
+	}
+	return value, nil
+}
+
+// graphqlError carries a code, and the invalid fields of validation errors, in the extensions of the error
+type graphqlError struct {
+	message    string
+	extensions map[string]interface{}
+}
+
+func (e *graphqlError) Error() string {
+	return e.message
+}
+
+func (e *graphqlError) Extensions() map[string]interface{} {
+	return e.extensions
+}
+
+func newInputError(params ...cerror.InvalidParam) error {
+	return toGraphqlError(cerror.NewValidationError(http.StatusBadRequest, params...), "")
+}
+
+// toGraphqlError shows service errors like AbortWithMessage does, anything else is logged and replaced by message
+func toGraphqlError(err error, message string) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	var serviceError *cerror.ServiceError
+	if !errors.As(err, &serviceError) {
+		logrus.WithError(err).Error(message)
+		return &graphqlError{message: message, extensions: map[string]interface{}{"code": "INTERNAL_SERVER_ERROR"}}
+	}
+	extensions := map[string]interface{}{"code": errorCode(serviceError.Status)}
+	if len(serviceError.InvalidParams) > 0 {
+		extensions["invalidParams"] = serviceError.InvalidParams
+	}
+	return &graphqlError{message: serviceError.Error(), extensions: extensions}
+}
+
+func errorCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "BAD_USER_INPUT"
+	case http.StatusNotFound:
+		return "NOT_FOUND"
+	case http.StatusNotImplemented:
+		return "NOT_IMPLEMENTED"
+	}
+	if status >= http.StatusInternalServerError {
+		return "INTERNAL_SERVER_ERROR"
+	}
+	return strings.ToUpper(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+}
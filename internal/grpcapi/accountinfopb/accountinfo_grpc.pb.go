@@ -0,0 +1,249 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v3.5.1-go
+// source: accountinfo.proto
+
+package accountinfopb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	AccountInfoService_CreateAccountInfo_FullMethodName     = "/accountinfo.v1.AccountInfoService/CreateAccountInfo"
+	AccountInfoService_GetAccountInfo_FullMethodName        = "/accountinfo.v1.AccountInfoService/GetAccountInfo"
+	AccountInfoService_ListAccountInfos_FullMethodName      = "/accountinfo.v1.AccountInfoService/ListAccountInfos"
+	AccountInfoService_GetAccountInfoHistory_FullMethodName = "/accountinfo.v1.AccountInfoService/GetAccountInfoHistory"
+)
+
+// AccountInfoServiceClient is the client API for AccountInfoService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// AccountInfoService mirrors the account endpoints of the REST api, it is served from the same service
+type AccountInfoServiceClient interface {
+	// CreateAccountInfo validates and stores a new account, invalid fields are listed in a BadRequest detail
+	CreateAccountInfo(ctx context.Context, in *CreateAccountInfoRequest, opts ...grpc.CallOption) (*AccountInfo, error)
+	GetAccountInfo(ctx context.Context, in *GetAccountInfoRequest, opts ...grpc.CallOption) (*AccountInfo, error)
+	// ListAccountInfos streams one page, or every account when page is 0
+	ListAccountInfos(ctx context.Context, in *ListAccountInfosRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AccountInfo], error)
+	// GetAccountInfoHistory returns every revision of the account, oldest first
+	GetAccountInfoHistory(ctx context.Context, in *GetAccountInfoHistoryRequest, opts ...grpc.CallOption) (*GetAccountInfoHistoryResponse, error)
+}
+
+type accountInfoServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAccountInfoServiceClient(cc grpc.ClientConnInterface) AccountInfoServiceClient {
+	return &accountInfoServiceClient{cc}
+}
+
+func (c *accountInfoServiceClient) CreateAccountInfo(ctx context.Context, in *CreateAccountInfoRequest, opts ...grpc.CallOption) (*AccountInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AccountInfo)
+	err := c.cc.Invoke(ctx, AccountInfoService_CreateAccountInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountInfoServiceClient) GetAccountInfo(ctx context.Context, in *GetAccountInfoRequest, opts ...grpc.CallOption) (*AccountInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AccountInfo)
+	err := c.cc.Invoke(ctx, AccountInfoService_GetAccountInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountInfoServiceClient) ListAccountInfos(ctx context.Context, in *ListAccountInfosRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AccountInfo], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AccountInfoService_ServiceDesc.Streams[0], AccountInfoService_ListAccountInfos_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListAccountInfosRequest, AccountInfo]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AccountInfoService_ListAccountInfosClient = grpc.ServerStreamingClient[AccountInfo]
+
+func (c *accountInfoServiceClient) GetAccountInfoHistory(ctx context.Context, in *GetAccountInfoHistoryRequest, opts ...grpc.CallOption) (*GetAccountInfoHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAccountInfoHistoryResponse)
+	err := c.cc.Invoke(ctx, AccountInfoService_GetAccountInfoHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AccountInfoServiceServer is the server API for AccountInfoService service.
+// All implementations must embed UnimplementedAccountInfoServiceServer
+// for forward compatibility.
+//
+// AccountInfoService mirrors the account endpoints of the REST api, it is served from the same service
+type AccountInfoServiceServer interface {
+	// CreateAccountInfo validates and stores a new account, invalid fields are listed in a BadRequest detail
+	CreateAccountInfo(context.Context, *CreateAccountInfoRequest) (*AccountInfo, error)
+	GetAccountInfo(context.Context, *GetAccountInfoRequest) (*AccountInfo, error)
+	// ListAccountInfos streams one page, or every account when page is 0
+	ListAccountInfos(*ListAccountInfosRequest, grpc.ServerStreamingServer[AccountInfo]) error
+	// GetAccountInfoHistory returns every revision of the account, oldest first
+	GetAccountInfoHistory(context.Context, *GetAccountInfoHistoryRequest) (*GetAccountInfoHistoryResponse, error)
+	mustEmbedUnimplementedAccountInfoServiceServer()
+}
+
+// UnimplementedAccountInfoServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAccountInfoServiceServer struct{}
+
+func (UnimplementedAccountInfoServiceServer) CreateAccountInfo(context.Context, *CreateAccountInfoRequest) (*AccountInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAccountInfo not implemented")
+}
+func (UnimplementedAccountInfoServiceServer) GetAccountInfo(context.Context, *GetAccountInfoRequest) (*AccountInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccountInfo not implemented")
+}
+func (UnimplementedAccountInfoServiceServer) ListAccountInfos(*ListAccountInfosRequest, grpc.ServerStreamingServer[AccountInfo]) error {
+	return status.Errorf(codes.Unimplemented, "method ListAccountInfos not implemented")
+}
+func (UnimplementedAccountInfoServiceServer) GetAccountInfoHistory(context.Context, *GetAccountInfoHistoryRequest) (*GetAccountInfoHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccountInfoHistory not implemented")
+}
+func (UnimplementedAccountInfoServiceServer) mustEmbedUnimplementedAccountInfoServiceServer() {}
+func (UnimplementedAccountInfoServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafeAccountInfoServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AccountInfoServiceServer will
+// result in compilation errors.
+type UnsafeAccountInfoServiceServer interface {
+	mustEmbedUnimplementedAccountInfoServiceServer()
+}
+
+func RegisterAccountInfoServiceServer(s grpc.ServiceRegistrar, srv AccountInfoServiceServer) {
+	// If the following call pancis, it indicates UnimplementedAccountInfoServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AccountInfoService_ServiceDesc, srv)
+}
+
+func _AccountInfoService_CreateAccountInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAccountInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountInfoServiceServer).CreateAccountInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountInfoService_CreateAccountInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountInfoServiceServer).CreateAccountInfo(ctx, req.(*CreateAccountInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountInfoService_GetAccountInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAccountInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountInfoServiceServer).GetAccountInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountInfoService_GetAccountInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountInfoServiceServer).GetAccountInfo(ctx, req.(*GetAccountInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccountInfoService_ListAccountInfos_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListAccountInfosRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AccountInfoServiceServer).ListAccountInfos(m, &grpc.GenericServerStream[ListAccountInfosRequest, AccountInfo]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AccountInfoService_ListAccountInfosServer = grpc.ServerStreamingServer[AccountInfo]
+
+func _AccountInfoService_GetAccountInfoHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAccountInfoHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountInfoServiceServer).GetAccountInfoHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountInfoService_GetAccountInfoHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountInfoServiceServer).GetAccountInfoHistory(ctx, req.(*GetAccountInfoHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AccountInfoService_ServiceDesc is the grpc.ServiceDesc for AccountInfoService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AccountInfoService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "accountinfo.v1.AccountInfoService",
+	HandlerType: (*AccountInfoServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateAccountInfo",
+			Handler:    _AccountInfoService_CreateAccountInfo_Handler,
+		},
+		{
+			MethodName: "GetAccountInfo",
+			Handler:    _AccountInfoService_GetAccountInfo_Handler,
+		},
+		{
+			MethodName: "GetAccountInfoHistory",
+			Handler:    _AccountInfoService_GetAccountInfoHistory_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListAccountInfos",
+			Handler:       _AccountInfoService_ListAccountInfos_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "accountinfo.proto",
+}
@@ -0,0 +1,47 @@
+package grpcapi
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"immudb/internal/grpcapi/accountinfopb"
+	"immudb/internal/models"
+)
+
+func convertAccountInfoToProto(info *models.AccountInfo) *accountinfopb.AccountInfo {
+	if info == nil {
+		return nil
+	}
+	output := &accountinfopb.AccountInfo{
+		AccountNumber: uint64(info.Id),
+		DocumentId:    info.DocumentId,
+		AccountName:   info.Name,
+		Iban:          info.Iban,
+		Address:       info.Address,
+		Amount:        info.Amount,
+	}
+	if info.Type != nil {
+		output.Type = accountinfopb.AccountType(*info.Type)
+	}
+	if info.Meta != nil {
+		output.Revision = &accountinfopb.Revision{
+			Revision:      info.Meta.Revision,
+			TransactionId: info.Meta.TransactionId,
+			Timestamp:     timestamppb.New(info.Meta.Timestamp),
+		}
+	}
+	return output
+}
+
+// convertCreateRequestToModel leaves an unspecified type unset, so the validation reports it like on REST
+func convertCreateRequestToModel(request *accountinfopb.CreateAccountInfoRequest) *models.AccountInfo {
+	output := &models.AccountInfo{
+		Name:    request.GetAccountName(),
+		Iban:    request.GetIban(),
+		Address: request.Address,
+		Amount:  request.GetAmount(),
+	}
+	if request.GetType() != accountinfopb.AccountType_ACCOUNT_TYPE_UNSPECIFIED {
+		accountType := models.AccountType(request.GetType())
+		output.Type = &accountType
+	}
+	return output
+}
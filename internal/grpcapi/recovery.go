@@ -0,0 +1,39 @@
+package grpcapi
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"runtime/debug"
+)
+
+// recoverUnary turns a panic in a call into an Internal error, like gin.Recovery does for REST,
+// so one bad request can't take the process and every other call down with it
+func recoverUnary(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (response interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = recovery(info.FullMethod, recovered)
+		}
+	}()
+	return handler(ctx, request)
+}
+
+func recoverStream(server interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = recovery(info.FullMethod, recovered)
+		}
+	}()
+	return handler(server, stream)
+}
+
+func recovery(method string, recovered interface{}) error {
+	logrus.WithFields(logrus.Fields{
+		"method": method,
+		"panic":  recovered,
+		"stack":  string(debug.Stack()),
+	}).Error("gRPC call panicked")
+	return status.Error(codes.Internal, "internal error")
+}
@@ -0,0 +1,162 @@
+// Package grpcapi serves the account service over gRPC, next to the REST api and from the same service instance
+// so both share the validation and the backend.
+package grpcapi
+
+//go:generate protoc -I accountinfopb --go_out=accountinfopb --go_opt=paths=source_relative --go-grpc_out=accountinfopb --go-grpc_opt=paths=source_relative accountinfo.proto
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"immudb/internal/grpcapi/accountinfopb"
+	"immudb/internal/handlers"
+	"immudb/internal/models"
+	"immudb/internal/services"
+	"net"
+	"time"
+)
+
+// healthInterval is how often the health service runs the dependency checks
+const healthInterval = 10 * time.Second
+
+type Server struct {
+	accountinfopb.UnimplementedAccountInfoServiceServer
+	service services.Service
+}
+
+// NewGrpcServer registers the account service together with reflection and a health service,
+// the health status is set from checks once, Start keeps it up to date
+func NewGrpcServer(service services.Service, checks ...handlers.HealthChecker) (*grpc.Server, *health.Server) {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(recoverUnary), grpc.ChainStreamInterceptor(recoverStream))
+	accountinfopb.RegisterAccountInfoServiceServer(server, &Server{service: service})
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+	updateHealth(healthServer, checks)
+	return server, healthServer
+}
+
+// Start listens on address and serves in the background, the caller stops the server with Shutdown
+func Start(address string, service services.Service, checks ...handlers.HealthChecker) (*grpc.Server, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	server, healthServer := NewGrpcServer(service, checks...)
+	if len(checks) > 0 {
+		go func() {
+			ticker := time.NewTicker(healthInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				updateHealth(healthServer, checks)
+			}
+		}()
+	}
+	go func() {
+		err := server.Serve(listener)
+		if err != nil {
+			logrus.WithError(err).Error("gRPC server stopped")
+		}
+	}()
+	return server, nil
+}
+
+// Shutdown lets the calls in flight finish, the ones still running after timeout are cancelled
+func Shutdown(server *grpc.Server, timeout time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		logrus.Warn("gRPC calls still running after the shutdown timeout are cancelled")
+		server.Stop()
+	}
+}
+
+func updateHealth(healthServer *health.Server, checks []handlers.HealthChecker) {
+	serving := healthpb.HealthCheckResponse_SERVING
+	for _, check := range checks {
+		if err := check.Healthy(); err != nil {
+			logrus.WithError(err).Warn("gRPC health check failed")
+			serving = healthpb.HealthCheckResponse_NOT_SERVING
+			break
+		}
+	}
+	healthServer.SetServingStatus("", serving)
+	healthServer.SetServingStatus(accountinfopb.AccountInfoService_ServiceDesc.ServiceName, serving)
+}
+
+func (s *Server) CreateAccountInfo(ctx context.Context, request *accountinfopb.CreateAccountInfoRequest) (*accountinfopb.AccountInfo, error) {
+	created, err := s.service.CreateAccountInfo(ctx, convertCreateRequestToModel(request))
+	if err != nil {
+		return nil, toStatus(err, "failed to create accountInfo")
+	}
+	return convertAccountInfoToProto(created), nil
+}
+
+func (s *Server) GetAccountInfo(ctx context.Context, request *accountinfopb.GetAccountInfoRequest) (*accountinfopb.AccountInfo, error) {
+	if request.GetAccountNumber() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "account_number is required")
+	}
+	result, err := s.service.GetAccountInfoById(ctx, uint(request.GetAccountNumber()))
+	if err != nil {
+		return nil, toStatus(err, "failed to load accountInfo")
+	}
+	return convertAccountInfoToProto(result), nil
+}
+
+func (s *Server) ListAccountInfos(request *accountinfopb.ListAccountInfosRequest, stream accountinfopb.AccountInfoService_ListAccountInfosServer) error {
+	send := func(page []*models.AccountInfo) error {
+		for _, account := range page {
+			err := stream.Send(convertAccountInfoToProto(account))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	ctx := stream.Context()
+	if request.GetPage() == 0 {
+		err := s.service.ExportAccountInfos(ctx, nil, send)
+		if err != nil {
+			return toStatus(err, "failed to load accountInfos")
+		}
+		return nil
+	}
+	pageSize := int(request.GetPageSize())
+	if pageSize == 0 {
+		pageSize = handlers.DefaultPageSize
+	}
+	page, err := s.service.GetAllAccountInfos(ctx, int(request.GetPage()), pageSize)
+	if err != nil {
+		return toStatus(err, "failed to load accountInfos")
+	}
+	err = send(page)
+	if err != nil {
+		return toStatus(err, "failed to load accountInfos")
+	}
+	return nil
+}
+
+func (s *Server) GetAccountInfoHistory(ctx context.Context, request *accountinfopb.GetAccountInfoHistoryRequest) (*accountinfopb.GetAccountInfoHistoryResponse, error) {
+	if request.GetAccountNumber() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "account_number is required")
+	}
+	revisions, err := s.service.GetAccountInfoHistory(ctx, uint(request.GetAccountNumber()))
+	if err != nil {
+		return nil, toStatus(err, "failed to load the history")
+	}
+	response := &accountinfopb.GetAccountInfoHistoryResponse{}
+	for _, revision := range revisions {
+		response.Revisions = append(response.Revisions, convertAccountInfoToProto(revision))
+	}
+	return response, nil
+}
@@ -0,0 +1,248 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"immudb/internal/grpcapi/accountinfopb"
+	"immudb/internal/handlers"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"immudb/internal/services"
+	"io"
+	"net"
+	"testing"
+)
+
+type failingCheck struct{}
+
+func (failingCheck) Healthy() error {
+	return errors.New("ledger state is stale")
+}
+
+// panickingDB panics on every read, like a backend with a nil dereference would
+type panickingDB struct {
+	persistance.AccountDB
+}
+
+func (panickingDB) GetAccountInfoById(ctx context.Context, Id uint) (*models.AccountInfo, error) {
+	panic("nil dereference")
+}
+
+func (panickingDB) GetAllAccountInfos(ctx context.Context, pageNr, pageSize int) ([]*models.AccountInfo, error) {
+	panic("nil dereference")
+}
+
+// dial serves a fresh memory backed service in process and returns a connection to it
+func dial(t *testing.T, checks ...handlers.HealthChecker) *grpc.ClientConn {
+	return dialDB(t, persistance.NewMemoryDB(), checks...)
+}
+
+func dialDB(t *testing.T, db persistance.AccountDB, checks ...handlers.HealthChecker) *grpc.ClientConn {
+	service, err := services.NewService(db)
+	assert.NoError(t, err)
+	service.AllowUpdates = true
+	listener := bufconn.Listen(1 << 20)
+	server, _ := NewGrpcServer(service, checks...)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestServer_CreateAndGet(t *testing.T) {
+	// setup
+	client := accountinfopb.NewAccountInfoServiceClient(dial(t))
+	ctx := context.Background()
+	address := "Tirana"
+
+	// action
+	created, err := client.CreateAccountInfo(ctx, &accountinfopb.CreateAccountInfoRequest{
+		AccountName: "John", Iban: "AL47", Address: &address, Amount: 10.5, Type: accountinfopb.AccountType_ACCOUNT_TYPE_RECEIVING,
+	})
+
+	// assertions
+	assert.NoError(t, err)
+	assert.NotZero(t, created.GetAccountNumber())
+	assert.Equal(t, uint64(1), created.GetRevision().GetRevision())
+	found, err := client.GetAccountInfo(ctx, &accountinfopb.GetAccountInfoRequest{AccountNumber: created.GetAccountNumber()})
+	assert.NoError(t, err)
+	assert.Equal(t, "John", found.GetAccountName())
+	assert.Equal(t, "Tirana", found.GetAddress())
+	assert.Equal(t, accountinfopb.AccountType_ACCOUNT_TYPE_RECEIVING, found.GetType())
+	history, err := client.GetAccountInfoHistory(ctx, &accountinfopb.GetAccountInfoHistoryRequest{AccountNumber: created.GetAccountNumber()})
+	assert.NoError(t, err)
+	assert.Len(t, history.GetRevisions(), 1)
+}
+
+func TestServer_Errors(t *testing.T) {
+	client := accountinfopb.NewAccountInfoServiceClient(dial(t))
+	tests := []struct {
+		name               string
+		call               func() error
+		expectedCode       codes.Code
+		expectedMessage    string
+		expectedViolations []string
+	}{
+		{
+			name: "Test_Invalid_Create",
+			call: func() error {
+				_, err := client.CreateAccountInfo(context.Background(), &accountinfopb.CreateAccountInfoRequest{AccountName: "John"})
+				return err
+			},
+			expectedCode:       codes.InvalidArgument,
+			expectedMessage:    "invalid iban for the account; invalid type for the account",
+			expectedViolations: []string{"iban", "type"},
+		},
+		{
+			name: "Test_Not_Found",
+			call: func() error {
+				_, err := client.GetAccountInfo(context.Background(), &accountinfopb.GetAccountInfoRequest{AccountNumber: 42})
+				return err
+			},
+			expectedCode:    codes.NotFound,
+			expectedMessage: "account info not found",
+		},
+		{
+			name: "Test_Missing_Account_Number",
+			call: func() error {
+				_, err := client.GetAccountInfoHistory(context.Background(), &accountinfopb.GetAccountInfoHistoryRequest{})
+				return err
+			},
+			expectedCode:    codes.InvalidArgument,
+			expectedMessage: "account_number is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// action
+			err := tt.call()
+
+			// assertions
+			result, ok := status.FromError(err)
+			assert.True(t, ok)
+			assert.Equal(t, tt.expectedCode, result.Code())
+			assert.Equal(t, tt.expectedMessage, result.Message())
+			var violations []string
+			for _, detail := range result.Details() {
+				if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+					for _, violation := range badRequest.GetFieldViolations() {
+						violations = append(violations, violation.GetField())
+					}
+				}
+			}
+			assert.Equal(t, tt.expectedViolations, violations)
+		})
+	}
+}
+
+func TestServer_ListAccountInfos(t *testing.T) {
+	// setup
+	client := accountinfopb.NewAccountInfoServiceClient(dial(t))
+	ctx := context.Background()
+	for _, name := range []string{"first", "second", "third"} {
+		_, err := client.CreateAccountInfo(ctx, &accountinfopb.CreateAccountInfoRequest{
+			AccountName: name, Iban: "AL47", Type: accountinfopb.AccountType_ACCOUNT_TYPE_SENDING,
+		})
+		assert.NoError(t, err)
+	}
+	tests := []struct {
+		name          string
+		request       *accountinfopb.ListAccountInfosRequest
+		expectedNames []string
+	}{
+		{
+			name:          "Test_Every_Account",
+			request:       &accountinfopb.ListAccountInfosRequest{},
+			expectedNames: []string{"first", "second", "third"},
+		},
+		{
+			name:          "Test_One_Page",
+			request:       &accountinfopb.ListAccountInfosRequest{Page: 2, PageSize: 2},
+			expectedNames: []string{"third"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// action
+			stream, err := client.ListAccountInfos(ctx, tt.request)
+			assert.NoError(t, err)
+			var names []string
+			for {
+				account, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				assert.NoError(t, err)
+				names = append(names, account.GetAccountName())
+			}
+
+			// assertions
+			assert.Equal(t, tt.expectedNames, names)
+		})
+	}
+}
+
+func TestServer_Health(t *testing.T) {
+	tests := []struct {
+		name           string
+		checks         []handlers.HealthChecker
+		expectedStatus healthpb.HealthCheckResponse_ServingStatus
+	}{
+		{
+			name:           "Test_Serving",
+			expectedStatus: healthpb.HealthCheckResponse_SERVING,
+		},
+		{
+			name:           "Test_Failing_Check",
+			checks:         []handlers.HealthChecker{failingCheck{}},
+			expectedStatus: healthpb.HealthCheckResponse_NOT_SERVING,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			client := healthpb.NewHealthClient(dial(t, tt.checks...))
+
+			// action
+			response, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{
+				Service: accountinfopb.AccountInfoService_ServiceDesc.ServiceName,
+			})
+
+			// assertions
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, response.GetStatus())
+		})
+	}
+}
+
+func TestServer_Recovery(t *testing.T) {
+	// setup
+	client := accountinfopb.NewAccountInfoServiceClient(dialDB(t, panickingDB{AccountDB: persistance.NewMemoryDB()}))
+	ctx := context.Background()
+
+	// action
+	_, getErr := client.GetAccountInfo(ctx, &accountinfopb.GetAccountInfoRequest{AccountNumber: 1})
+	stream, err := client.ListAccountInfos(ctx, &accountinfopb.ListAccountInfosRequest{Page: 1})
+	assert.NoError(t, err)
+	_, listErr := stream.Recv()
+	created, createErr := client.CreateAccountInfo(ctx, &accountinfopb.CreateAccountInfoRequest{AccountName: "John", Iban: "AL47", Type: accountinfopb.AccountType_ACCOUNT_TYPE_SENDING})
+
+	// assertions
+	assert.Equal(t, codes.Internal, status.Code(getErr))
+	assert.Equal(t, codes.Internal, status.Code(listErr))
+	assert.NoError(t, createErr, "the server must keep serving after a panic")
+	assert.NotZero(t, created.GetAccountNumber())
+}
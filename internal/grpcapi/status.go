@@ -0,0 +1,68 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	cerror "immudb/internal/errors"
+	"net/http"
+)
+
+// codes for the statuses the service errors carry, as the gRPC http mapping has them
+var httpCodes = map[int]codes.Code{
+	http.StatusBadRequest:           codes.InvalidArgument,
+	http.StatusUnauthorized:         codes.Unauthenticated,
+	http.StatusForbidden:            codes.PermissionDenied,
+	http.StatusNotFound:             codes.NotFound,
+	http.StatusConflict:             codes.AlreadyExists,
+	http.StatusPreconditionFailed:   codes.FailedPrecondition,
+	http.StatusFailedDependency:     codes.FailedPrecondition,
+	http.StatusPreconditionRequired: codes.FailedPrecondition,
+	http.StatusTooManyRequests:      codes.ResourceExhausted,
+	http.StatusNotImplemented:       codes.Unimplemented,
+	http.StatusBadGateway:           codes.Unavailable,
+	http.StatusServiceUnavailable:   codes.Unavailable,
+	http.StatusGatewayTimeout:       codes.DeadlineExceeded,
+}
+
+// toStatus converts the error like AbortWithMessage does for REST: service errors keep their message
+// and invalid fields go into a BadRequest detail, anything else is logged and only message is returned
+func toStatus(err error, message string) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, context.Canceled) {
+		return status.Error(codes.Canceled, err.Error())
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	var serviceError *cerror.ServiceError
+	if !errors.As(err, &serviceError) {
+		logrus.WithError(err).Error(message)
+		return status.Error(codes.Internal, message)
+	}
+	code, ok := httpCodes[serviceError.Status]
+	if !ok {
+		code = codes.Unknown
+		if serviceError.Status >= http.StatusInternalServerError {
+			code = codes.Internal
+		}
+	}
+	result := status.New(code, serviceError.Error())
+	if len(serviceError.InvalidParams) == 0 {
+		return result.Err()
+	}
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(serviceError.InvalidParams))
+	for _, param := range serviceError.InvalidParams {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: param.Name, Description: param.Reason})
+	}
+	detailed, detailErr := result.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if detailErr != nil {
+		return result.Err()
+	}
+	return detailed.Err()
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"immudb/internal/models"
 	"net/http"
+	"time"
 )
 
 const (
@@ -14,6 +15,7 @@ const (
 
 type AccountInfoDto struct {
 	AccountNumber uint                `json:"account_number"`
+	DocumentId    string              `json:"document_id,omitempty"` // read only, assigned by the vault
 	AccountName   string              `json:"account_name"`
 	Iban          string              `json:"iban"`
 	Address       *string             `json:"address"`
@@ -21,16 +23,50 @@ type AccountInfoDto struct {
 	Type          *models.AccountType `json:"type"`
 }
 
+// ReceiptDto is the vault proof of submission, callers can store it to prove the account was written
+type ReceiptDto struct {
+	DocumentId    string    `json:"document_id"`
+	TransactionId uint64    `json:"transaction_id"`
+	Revision      uint64    `json:"revision"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+type CreatedAccountInfoDto struct {
+	AccountInfoDto
+	Receipt *ReceiptDto `json:"receipt,omitempty"`
+}
+
+type VerificationDto struct {
+	Verified            bool      `json:"verified"`
+	DocumentId          string    `json:"document_id"`
+	TransactionId       uint64    `json:"transaction_id"`
+	LedgerTransactionId uint64    `json:"ledger_transaction_id"`
+	RootHash            string    `json:"root_hash"`
+	VerifiedAt          time.Time `json:"verified_at"`
+}
+
+type VerifiedAccountInfoDto struct {
+	AccountInfoDto
+	Verification *VerificationDto `json:"verification,omitempty"`
+}
+
 // GetAccountInfos lists all existing accounts
 //
 // @Summary      Get all account infos
-// @Description  Retrieve a paginated list of all account information
+// @Description  Retrieve a paginated list of all account information.
+// @Description  With asOf every account is returned as it was at that point, accounts created later are left out.
+// @Description  The pages are pages of the accounts that existed then, only the last one is short.
 // @ID           get-all-account-infos
 // @Accept       json
 // @Produce      json
+// @Produce      text/csv
+// @Produce      application/msgpack
 // @Param        page     query    int    false  "Page number"       default(1)
 // @Param        pageSize query    int    false  "Page size"         default(10)
+// @Param        asOf     query    string false  "Transaction id or RFC 3339 timestamp"
 // @Success      200      {object}   Response[[]AccountInfoDto] "List of account info"
+// @Failure      400      {object} Response[string]  "Bad request, invalid asOf"
+// @Failure      406      {object} Response[string]  "None of the accepted types is supported"
 // @Failure      500      {object} Response[string]  "Internal server error"
 // @Router       /account-info [get]
 func (h *Handler) GetAccountInfos(c *gin.Context) {
@@ -42,8 +78,18 @@ func (h *Handler) GetAccountInfos(c *gin.Context) {
 	if err != nil {
 		pageSize = DefaultPageSize
 	}
+	asOf, err := getQueryParamAsOf(c, "asOf")
+	if err != nil {
+		AbortWithMessage(c, http.StatusBadRequest, err, "invalid asOf")
+		return
+	}
 
-	result, err := h.Service.GetAllAccountInfos(c.Request.Context(), page, pageSize)
+	var result []*models.AccountInfo
+	if asOf != nil {
+		result, err = h.Service.GetAllAccountInfosAsOf(c.Request.Context(), page, pageSize, *asOf)
+	} else {
+		result, err = h.Service.GetAllAccountInfos(c.Request.Context(), page, pageSize)
+	}
 	if err != nil {
 		AbortWithMessage(c, http.StatusInternalServerError, err, "failed to load accountInfos")
 		return
@@ -58,14 +104,26 @@ func (h *Handler) GetAccountInfos(c *gin.Context) {
 // GetAccountInfo
 //
 // @Summary      Get account info by ID
-// @Description  Retrieve account information for a specific account by ID
+// @Description  Retrieve account information for a specific account by ID.
+// @Description  With verify=true the document inclusion proof is checked against the locally trusted ledger state.
+// @Description  With asOf the revision that was current at that point is returned.
 // @ID           get-account-info-by-id
 // @Accept       json
 // @Produce      json
-// @Param        id   path      int  true  "Account ID"
-// @Success      200  {object}  Response[AccountInfoDto] "Account information"
+// @Produce      text/csv
+// @Produce      application/msgpack
+// @Param        id      path      int     true   "Account ID"
+// @Param        verify  query     bool    false  "Verify the document against the ledger proofs"  default(false)
+// @Param        asOf    query     string  false  "Transaction id or RFC 3339 timestamp"
+// @Param        If-None-Match  header  string  false  "ETag of the revision the client already has, ignored with asOf"
+// @Success      200  {object}  Response[VerifiedAccountInfoDto] "Account information, verification is only set on verified reads"
+// @Success      304  "The revision didn't change"
 // @Failure      400  {object}  Response[string]  "Bad request, ID is required"
+// @Failure      404  {object}  Response[string]  "Account not found"
+// @Failure      406  {object}  Response[string]  "None of the accepted types is supported"
 // @Failure      500  {object}  Response[string]  "Internal server error"
+// @Failure      501  {object}  Response[string]  "Verified reads are not supported by the backend"
+// @Failure      502  {object}  Response[string]  "Proof verification failed"
 // @Router       /account-info/{id} [get]
 func (h *Handler) GetAccountInfo(c *gin.Context) {
 	id, err := getParamUInt(c, "id")
@@ -73,23 +131,81 @@ func (h *Handler) GetAccountInfo(c *gin.Context) {
 		AbortWithMessage(c, http.StatusBadRequest, fmt.Errorf("please specify id"), "id is required")
 		return
 	}
-	result, err := h.Service.GetAccountInfoById(c.Request.Context(), id)
+	verify, err := getQueryParamBool(c, "verify")
+	if err != nil {
+		AbortWithMessage(c, http.StatusBadRequest, err, "verify must be a boolean")
+		return
+	}
+	asOf, err := getQueryParamAsOf(c, "asOf")
+	if err != nil {
+		AbortWithMessage(c, http.StatusBadRequest, err, "invalid asOf")
+		return
+	}
+	if verify && asOf != nil {
+		AbortWithMessage(c, http.StatusBadRequest, fmt.Errorf("verify and asOf can't be combined"), "verify and asOf can't be combined")
+		return
+	}
+	if verify {
+		result, verification, err := h.Service.GetVerifiedAccountInfoById(c.Request.Context(), id)
+		if err != nil {
+			AbortWithMessage(c, http.StatusInternalServerError, err, "failed to verify accountInfo")
+			return
+		}
+		returnOk(c, http.StatusOK, convertVerifiedAccountInfoToDTO(result, verification))
+		return
+	}
+	var result *models.AccountInfo
+	if asOf != nil {
+		result, err = h.Service.GetAccountInfoByIdAsOf(c.Request.Context(), id, *asOf)
+	} else {
+		result, err = h.Service.GetAccountInfoById(c.Request.Context(), id)
+	}
 	if err != nil {
 		AbortWithMessage(c, http.StatusInternalServerError, err, "failed to load accountIndo")
 		return
 	}
+	// a past revision is not the current resource, its etag would validate the wrong representation
+	if asOf == nil && setETag(c, result) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	returnOk(c, http.StatusOK, convertAccountInfoToDTO(result))
+}
+
+// GetAccountInfoByDocument
+//
+// @Summary      Get account info by document ID
+// @Description  Retrieve the latest revision of an account by the document id the vault assigned to it
+// @ID           get-account-info-by-document-id
+// @Accept       json
+// @Produce      json
+// @Produce      text/csv
+// @Produce      application/msgpack
+// @Param        documentId   path      string  true  "Vault document ID"
+// @Success      200  {object}  Response[AccountInfoDto] "Account information"
+// @Failure      404  {object}  Response[string]  "Account not found"
+// @Failure      406  {object}  Response[string]  "None of the accepted types is supported"
+// @Failure      500  {object}  Response[string]  "Internal server error"
+// @Router       /account-info/document/{documentId} [get]
+func (h *Handler) GetAccountInfoByDocument(c *gin.Context) {
+	documentId := c.Param("documentId")
+	result, err := h.Service.GetAccountInfoByDocumentId(c.Request.Context(), documentId)
+	if err != nil {
+		AbortWithMessage(c, http.StatusInternalServerError, err, "failed to load accountInfo")
+		return
+	}
 	returnOk(c, http.StatusOK, convertAccountInfoToDTO(result))
 }
 
 // CreateAccountInfo
 //
 // @Summary      Create account info
-// @Description  Add a new account information entry
+// @Description  Add a new account information entry, the response carries the vault receipt of the write
 // @ID           create-account-info
 // @Accept       json
 // @Produce      json
 // @Param        account  body      AccountInfoDto  true  "Account information"
-// @Success      201      {object}   Response[AccountInfoDto] "Created account info"
+// @Success      201      {object}   Response[CreatedAccountInfoDto] "Created account info with the vault receipt"
 // @Failure      400      {object}  Response[string]  "Bad request, invalid input"
 // @Failure      500      {object}  Response[string]  "Internal server error"
 // @Router       /account-info [post]
@@ -106,7 +222,7 @@ func (h *Handler) CreateAccountInfo(c *gin.Context) {
 		AbortWithMessage(c, http.StatusInternalServerError, err, "failed to create author")
 		return
 	}
-	returnOk(c, http.StatusCreated, convertAccountInfoToDTO(data))
+	returnOk(c, http.StatusCreated, convertCreatedAccountInfoToDTO(data))
 }
 
 // DeleteAccountInfo
@@ -126,16 +242,44 @@ func (h *Handler) DeleteAccountInfo(c *gin.Context) {
 // UpdateAccountInfo
 //
 // @Summary      Update account info
-// @Description  Update account information for a specific account by ID (currently not allowed)
+// @Description  Store a new revision of the account. If-Match must carry the ETag of the revision being updated,
+// @Description  if somebody else updated the account in the meantime the update is rejected with 412.
 // @ID           update-account-info
 // @Accept       json
 // @Produce      json
-// @Param        id      path      int          true  "Account ID"
-// @Param        account body      AccountInfoDto  true  "Updated account information"
-// @Failure      405     {object}  Response[string] "Method not allowed"
+// @Param        id        path      int             true  "Account ID"
+// @Param        If-Match  header    string          true  "ETag of the revision being updated"
+// @Param        account   body      AccountInfoDto  true  "Updated account information"
+// @Success      200       {object}  Response[AccountInfoDto] "Updated account info"
+// @Failure      400       {object}  Response[string] "Bad request, invalid input or an If-Match that is not a single strong etag"
+// @Failure      405       {object}  Response[string] "Updates are not allowed"
+// @Failure      412       {object}  Response[string] "The account was modified since the ETag was issued"
+// @Failure      428       {object}  Response[string] "If-Match is required"
 // @Router       /account-info/{id} [put]
 func (h *Handler) UpdateAccountInfo(c *gin.Context) {
-	AbortWithMessage(c, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"), "update  is not allowed")
+	id, err := getParamUInt(c, "id")
+	if err != nil {
+		AbortWithMessage(c, http.StatusBadRequest, fmt.Errorf("please specify id"), "id is required")
+		return
+	}
+	revision, err := getIfMatchRevision(c)
+	if err != nil {
+		// 412 is for a valid etag of another revision, this one can't be read at all
+		AbortWithMessage(c, http.StatusBadRequest, err, err.Error())
+		return
+	}
+	input, err := bindToAccountInfo(c)
+	if err != nil {
+		AbortWithMessage(c, http.StatusBadRequest, err, "error binding to json")
+		return
+	}
+	data, err := h.Service.UpdateAccountInfo(c.Request.Context(), id, input, revision)
+	if err != nil {
+		AbortWithMessage(c, http.StatusInternalServerError, err, "failed to update accountInfo")
+		return
+	}
+	setETag(c, data)
+	returnOk(c, http.StatusOK, convertAccountInfoToDTO(data))
 }
 
 func bindToAccountInfo(c *gin.Context) (*models.AccountInfo, error) {
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"immudb/internal/audit"
+	"net/http"
+	"time"
+)
+
+// AuditStatusProvider is implemented by the background auditor
+type AuditStatusProvider interface {
+	Status() audit.Status
+}
+
+type AuditFailureDto struct {
+	DocumentId    string `json:"document_id"`
+	TransactionId uint64 `json:"transaction_id"`
+	Check         string `json:"check"`
+	Error         string `json:"error"`
+}
+
+type AuditReportDto struct {
+	StartedAt           time.Time         `json:"started_at"`
+	FinishedAt          time.Time         `json:"finished_at"`
+	Status              string            `json:"status"`
+	Error               string            `json:"error,omitempty"`
+	FromTransactionId   uint64            `json:"from_transaction_id"`
+	ToTransactionId     uint64            `json:"to_transaction_id"`
+	TransactionsChecked int               `json:"transactions_checked"`
+	DocumentsSampled    int               `json:"documents_sampled"`
+	Failures            []AuditFailureDto `json:"failures"`
+}
+
+type AuditStatusDto struct {
+	Enabled  bool             `json:"enabled"`
+	Interval string           `json:"interval,omitempty"`
+	Runs     int              `json:"runs"`
+	Reports  []AuditReportDto `json:"reports"`
+}
+
+// GetAuditStatus
+//
+// @Summary      Get the tamper audit status
+// @Description  Results of the latest background audits of the ledger, newest first
+// @ID           get-audit-status
+// @Produce      json
+// @Produce      text/csv
+// @Produce      application/msgpack
+// @Success      200  {object}  Response[AuditStatusDto] "Audit status"
+// @Failure      406  {object}  Response[string]  "None of the accepted types is supported"
+// @Router       /audit/status [get]
+func (h *Handler) GetAuditStatus(c *gin.Context) {
+	if h.Auditor == nil {
+		returnOk(c, http.StatusOK, AuditStatusDto{Enabled: false, Reports: []AuditReportDto{}})
+		return
+	}
+	returnOk(c, http.StatusOK, convertAuditStatusToDTO(h.Auditor.Status()))
+}
+
+func convertAuditStatusToDTO(status audit.Status) AuditStatusDto {
+	output := AuditStatusDto{
+		Enabled:  status.Enabled,
+		Interval: status.Interval.String(),
+		Runs:     status.Runs,
+		Reports:  make([]AuditReportDto, 0, len(status.Reports)),
+	}
+	for _, report := range status.Reports {
+		failures := make([]AuditFailureDto, 0, len(report.Failures))
+		for _, failure := range report.Failures {
+			failures = append(failures, AuditFailureDto{
+				DocumentId:    failure.DocumentId,
+				TransactionId: failure.TransactionId,
+				Check:         failure.Check,
+				Error:         failure.Error,
+			})
+		}
+		output.Reports = append(output.Reports, AuditReportDto{
+			StartedAt:           report.StartedAt,
+			FinishedAt:          report.FinishedAt,
+			Status:              report.Status,
+			Error:               report.Error,
+			FromTransactionId:   report.FromTransactionId,
+			ToTransactionId:     report.ToTransactionId,
+			TransactionsChecked: report.TransactionsChecked,
+			DocumentsSampled:    report.DocumentsSampled,
+			Failures:            failures,
+		})
+	}
+	return output
+}
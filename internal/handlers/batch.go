@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	errors2 "immudb/internal/errors"
+	"immudb/internal/models"
+	"net/http"
+	"strconv"
+)
+
+const batchAction = ":batch"
+
+type BatchItemDto struct {
+	Index         int                    `json:"index"`
+	Status        int                    `json:"status"`
+	Data          *CreatedAccountInfoDto `json:"data,omitempty"`
+	Err           string                 `json:"error_message,omitempty"`
+	InvalidParams []errors2.InvalidParam `json:"invalid_params,omitempty"`
+}
+
+// AccountInfoAction serves POST /account-info:{action}, gin has no way to register the colon literally
+// so the action arrives as a path parameter starting with it
+func (h *Handler) AccountInfoAction(c *gin.Context) {
+	switch c.Param("action") {
+	case batchAction:
+		h.CreateAccountInfoBatch(c)
+	default:
+		AbortWithMessage(c, http.StatusNotFound, fmt.Errorf("unknown action %q", c.Param("action")), "unknown action")
+	}
+}
+
+// CreateAccountInfoBatch
+//
+// @Summary      Create account infos in a batch
+// @Description  Validates every account and writes the valid ones with as few vault calls as possible, every item reports its own status.
+// @Description  With atomic=true an invalid account rejects the whole batch, a write that fails halfway still keeps what was written before it.
+// @ID           create-account-info-batch
+// @Accept       json
+// @Produce      json
+// @Param        accounts  body      []AccountInfoDto  true   "Accounts to create, at most 500"
+// @Param        atomic    query     bool              false  "Reject the whole batch if any account is invalid"
+// @Success      201       {object}  Response[[]BatchItemDto]  "Every account was created"
+// @Success      207       {object}  Response[[]BatchItemDto]  "Some accounts were not created, see the status of every item"
+// @Failure      400       {object}  Response[[]BatchItemDto]  "Bad request, the atomic batch was rejected or the body is invalid"
+// @Failure      500       {object}  Response[string]  "Internal server error"
+// @Router       /account-info:batch [post]
+func (h *Handler) CreateAccountInfoBatch(c *gin.Context) {
+	atomic := false
+	if value := c.Query("atomic"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			AbortWithMessage(c, http.StatusBadRequest, errors2.NewValidationError(http.StatusBadRequest,
+				errors2.InvalidParam{Name: "atomic", Reason: "atomic must be true or false"}), "invalid batch")
+			return
+		}
+		atomic = parsed
+	}
+	var input []*AccountInfoDto
+	err := c.BindJSON(&input)
+	if err != nil {
+		AbortWithMessage(c, http.StatusBadRequest, err, "error binding to json")
+		return
+	}
+	accounts := make([]*models.AccountInfo, 0, len(input))
+	for _, item := range input {
+		accounts = append(accounts, convertDtoToAccountInfo(item))
+	}
+
+	result, err := h.Service.CreateAccountInfos(c.Request.Context(), accounts, atomic)
+	if err != nil {
+		AbortWithMessage(c, http.StatusInternalServerError, err, "failed to create the batch")
+		return
+	}
+	output, created := convertBatchItemsToDTO(result)
+	switch {
+	case created == len(output):
+		returnOk(c, http.StatusCreated, output)
+	case atomic && created == 0:
+		renderJSON(c, http.StatusBadRequest, Response[[]BatchItemDto]{Data: output, Err: "the batch was rejected, at least one account is invalid"})
+	default:
+		returnOk(c, http.StatusMultiStatus, output)
+	}
+}
+
+func convertBatchItemsToDTO(items []models.BatchItem) ([]BatchItemDto, int) {
+	output := make([]BatchItemDto, 0, len(items))
+	created := 0
+	for i, item := range items {
+		dto := BatchItemDto{Index: i, Status: http.StatusCreated}
+		if item.Err == nil {
+			dto.Data = convertCreatedAccountInfoToDTO(item.Account)
+			created++
+			output = append(output, dto)
+			continue
+		}
+		// like single creates, only service errors are shown to the caller as they are
+		dto.Status = http.StatusInternalServerError
+		dto.Err = "failed to create accountInfo"
+		var serviceError *errors2.ServiceError
+		if errors.As(item.Err, &serviceError) {
+			dto.Status = serviceError.Status
+			dto.Err = serviceError.Error()
+			dto.InvalidParams = serviceError.InvalidParams
+		} else {
+			logrus.WithError(item.Err).WithField("index", i).Error("failed to create accountInfo of the batch")
+		}
+		output = append(output, dto)
+	}
+	return output, created
+}
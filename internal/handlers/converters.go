@@ -8,6 +8,7 @@ func convertAccountInfoToDTO(sc *models.AccountInfo) *AccountInfoDto {
 	}
 	return &AccountInfoDto{
 		AccountNumber: sc.Id,
+		DocumentId:    sc.DocumentId,
 		AccountName:   sc.Name,
 		Iban:          sc.Iban,
 		Address:       sc.Address,
@@ -29,3 +30,39 @@ func convertDtoToAccountInfo(input *AccountInfoDto) *models.AccountInfo {
 		Type:    input.Type,
 	}
 }
+
+func convertVerifiedAccountInfoToDTO(sc *models.AccountInfo, verification *models.Verification) *VerifiedAccountInfoDto {
+	account := convertAccountInfoToDTO(sc)
+	if account == nil {
+		return nil
+	}
+	result := &VerifiedAccountInfoDto{AccountInfoDto: *account}
+	if verification != nil {
+		result.Verification = &VerificationDto{
+			Verified:            verification.Verified,
+			DocumentId:          verification.DocumentId,
+			TransactionId:       verification.TransactionId,
+			LedgerTransactionId: verification.LedgerTransactionId,
+			RootHash:            verification.RootHash,
+			VerifiedAt:          verification.VerifiedAt,
+		}
+	}
+	return result
+}
+
+func convertCreatedAccountInfoToDTO(sc *models.AccountInfo) *CreatedAccountInfoDto {
+	account := convertAccountInfoToDTO(sc)
+	if account == nil {
+		return nil
+	}
+	result := &CreatedAccountInfoDto{AccountInfoDto: *account}
+	if sc.Meta != nil {
+		result.Receipt = &ReceiptDto{
+			DocumentId:    sc.DocumentId,
+			TransactionId: sc.Meta.TransactionId,
+			Revision:      sc.Meta.Revision,
+			Timestamp:     sc.Meta.Timestamp,
+		}
+	}
+	return result
+}
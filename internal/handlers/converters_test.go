@@ -16,15 +16,17 @@ func TestHandler_ModelToDtoConverters(t *testing.T) {
 		{
 			name: "validity_all_fields",
 			input: &models.AccountInfo{
-				Id:      1,
-				Name:    "tt",
-				Iban:    "iban",
-				Amount:  11.2,
-				Address: persistance.AddPointer("addr"),
-				Type:    persistance.AddPointer(models.Receiving),
+				Id:         1,
+				DocumentId: "doc",
+				Name:       "tt",
+				Iban:       "iban",
+				Amount:     11.2,
+				Address:    persistance.AddPointer("addr"),
+				Type:       persistance.AddPointer(models.Receiving),
 			},
 			expected: &AccountInfoDto{
 				AccountNumber: 1,
+				DocumentId:    "doc",
 				AccountName:   "tt",
 				Iban:          "iban",
 				Amount:        11.2,
@@ -116,3 +118,26 @@ func TestHandler_DtoToModelConverters(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_AccountDiffToJSONPatch(t *testing.T) {
+	// setup
+	diff := &models.AccountDiff{
+		Changes: []models.FieldChange{
+			{Field: "Name", OldValue: "John", NewValue: "John Doe"},
+			{Field: "Address", OldValue: nil, NewValue: "street"},
+			{Field: "Type", OldValue: models.Sending, NewValue: nil},
+			{Field: "Amount", OldValue: float64(10), NewValue: float64(0)},
+		},
+	}
+
+	// action
+	result := convertAccountDiffToJSONPatch(diff)
+
+	//assert
+	assert.Equal(t, []JSONPatchOperationDto{
+		{Op: "replace", Path: "/account_name", Value: "John Doe"},
+		{Op: "add", Path: "/address", Value: "street"},
+		{Op: "remove", Path: "/type"},
+		{Op: "replace", Path: "/amount", Value: float64(0)},
+	}, result)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	errors2 "immudb/internal/errors"
+	"immudb/internal/models"
+	"net/http"
+	"strconv"
+)
+
+const MIMEJSONPatch = "application/json-patch+json"
+
+// accountFieldNames maps the model fields to the names the api exposes them with
+var accountFieldNames = map[string]string{
+	"Name":    "account_name",
+	"Iban":    "iban",
+	"Address": "address",
+	"Amount":  "amount",
+	"Type":    "type",
+}
+
+type FieldChangeDto struct {
+	Field         string      `json:"field"`
+	OldValue      interface{} `json:"old_value"`
+	NewValue      interface{} `json:"new_value"`
+	ChangedBy     string      `json:"changed_by"`
+	TransactionId uint64      `json:"transaction_id"`
+}
+
+type AccountDiffDto struct {
+	AccountNumber uint             `json:"account_number"`
+	DocumentId    string           `json:"document_id"`
+	From          uint64           `json:"from"`
+	To            uint64           `json:"to"`
+	Changes       []FieldChangeDto `json:"changes"`
+}
+
+// JSONPatchOperationDto is a single RFC 6902 operation
+type JSONPatchOperationDto struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// GetAccountInfoDiff
+//
+// @Summary      Diff two revisions of an account
+// @Description  Field level changes between two revisions, with format=json-patch an RFC 6902 patch document is returned instead
+// @ID           get-account-info-diff
+// @Accept       json
+// @Produce      json,application/json-patch+json,text/csv,application/msgpack
+// @Param        id      path      int     true   "Account ID"
+// @Param        from    query     int     true   "Revision to diff from"
+// @Param        to      query     int     true   "Revision to diff to"
+// @Param        format  query     string  false  "Output format"  Enums(changes, json-patch)  default(changes)
+// @Success      200  {object}  Response[AccountDiffDto] "Changes between the revisions"
+// @Failure      400  {object}  Response[string]  "Bad request, invalid revisions"
+// @Failure      404  {object}  Response[string]  "Account or revision not found"
+// @Failure      406  {object}  Response[string]  "None of the accepted types is supported"
+// @Failure      500  {object}  Response[string]  "Internal server error"
+// @Router       /account-info/{id}/diff [get]
+func (h *Handler) GetAccountInfoDiff(c *gin.Context) {
+	id, err := getParamUInt(c, "id")
+	if err != nil {
+		AbortWithMessage(c, http.StatusBadRequest, fmt.Errorf("please specify id"), "id is required")
+		return
+	}
+	from, fromErr := strconv.ParseUint(c.Query("from"), 10, 64)
+	to, toErr := strconv.ParseUint(c.Query("to"), 10, 64)
+	if fromErr != nil || toErr != nil {
+		AbortWithMessage(c, http.StatusBadRequest, errors2.NewValidationError(http.StatusBadRequest,
+			errors2.InvalidParam{Name: "from", Reason: "from and to revisions are required"},
+		), "invalid revisions")
+		return
+	}
+	format := c.DefaultQuery("format", "changes")
+	if format != "changes" && format != "json-patch" {
+		AbortWithMessage(c, http.StatusBadRequest, errors2.NewValidationError(http.StatusBadRequest,
+			errors2.InvalidParam{Name: "format", Reason: "format must be changes or json-patch"},
+		), "invalid format")
+		return
+	}
+
+	result, err := h.Service.DiffAccountInfo(c.Request.Context(), id, from, to)
+	if err != nil {
+		AbortWithMessage(c, http.StatusInternalServerError, err, "failed to diff accountInfo")
+		return
+	}
+	if format == "json-patch" {
+		// a patch document is the bare array, so it can be applied as is
+		c.Header("Content-Type", MIMEJSONPatch)
+		c.JSON(http.StatusOK, convertAccountDiffToJSONPatch(result))
+		return
+	}
+	returnOk(c, http.StatusOK, convertAccountDiffToDTO(result))
+}
+
+func convertAccountDiffToDTO(diff *models.AccountDiff) *AccountDiffDto {
+	if diff == nil {
+		return nil
+	}
+	output := &AccountDiffDto{
+		AccountNumber: diff.Id,
+		DocumentId:    diff.DocumentId,
+		From:          diff.FromRevision,
+		To:            diff.ToRevision,
+		Changes:       make([]FieldChangeDto, 0, len(diff.Changes)),
+	}
+	for _, change := range diff.Changes {
+		output.Changes = append(output.Changes, FieldChangeDto{
+			Field:         accountFieldNames[change.Field],
+			OldValue:      change.OldValue,
+			NewValue:      change.NewValue,
+			ChangedBy:     change.ChangedBy,
+			TransactionId: change.TransactionId,
+		})
+	}
+	return output
+}
+
+func convertAccountDiffToJSONPatch(diff *models.AccountDiff) []JSONPatchOperationDto {
+	output := make([]JSONPatchOperationDto, 0, len(diff.Changes))
+	for _, change := range diff.Changes {
+		operation := JSONPatchOperationDto{Op: "replace", Path: "/" + accountFieldNames[change.Field], Value: change.NewValue}
+		switch {
+		case change.NewValue == nil:
+			operation = JSONPatchOperationDto{Op: "remove", Path: operation.Path}
+		case change.OldValue == nil:
+			operation.Op = "add"
+		}
+		output = append(output, operation)
+	}
+	return output
+}
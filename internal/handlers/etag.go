@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"immudb/internal/models"
+	"strconv"
+	"strings"
+)
+
+// revisionETag is a strong etag of the document revision, empty when the backend didn't report the revision
+func revisionETag(info *models.AccountInfo) string {
+	if info == nil || info.Meta == nil || info.Meta.Revision == 0 {
+		return ""
+	}
+	return fmt.Sprintf(`"%d"`, info.Meta.Revision)
+}
+
+// setETag sets the etag of the revision and reports whether the client already has it (If-None-Match)
+func setETag(c *gin.Context, info *models.AccountInfo) bool {
+	etag := revisionETag(info)
+	if etag == "" {
+		return false
+	}
+	c.Header("ETag", etag)
+	for _, candidate := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		// If-None-Match uses the weak comparison
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// getIfMatchRevision reads the revision from If-Match, 0 means the header is missing.
+// Only a single strong etag is accepted since the update is applied to exactly one revision.
+func getIfMatchRevision(c *gin.Context) (uint64, error) {
+	value := strings.TrimSpace(c.GetHeader("If-Match"))
+	if value == "" {
+		return 0, nil
+	}
+	unquoted, err := strconv.Unquote(value)
+	if err != nil || strings.HasPrefix(value, "W/") {
+		return 0, fmt.Errorf("If-Match must be a single strong etag")
+	}
+	revision, err := strconv.ParseUint(unquoted, 10, 64)
+	if err != nil || revision == 0 {
+		return 0, fmt.Errorf("If-Match is not an etag returned by this api")
+	}
+	return revision, nil
+}
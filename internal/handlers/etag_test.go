@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"immudb/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_SetETag(t *testing.T) {
+	tests := []struct {
+		name                string
+		info                *models.AccountInfo
+		ifNoneMatch         string
+		expectedETag        string
+		expectedNotModified bool
+	}{
+		{
+			name:         "Test_Unknown_Revision",
+			info:         &models.AccountInfo{Id: 1},
+			expectedETag: "",
+		},
+		{
+			name:         "Test_No_If_None_Match",
+			info:         &models.AccountInfo{Id: 1, Meta: &models.DocumentMeta{Revision: 2}},
+			expectedETag: `"2"`,
+		},
+		{
+			name:                "Test_Matching_If_None_Match",
+			info:                &models.AccountInfo{Id: 1, Meta: &models.DocumentMeta{Revision: 2}},
+			ifNoneMatch:         `"1", W/"2"`,
+			expectedETag:        `"2"`,
+			expectedNotModified: true,
+		},
+		{
+			name:         "Test_Stale_If_None_Match",
+			info:         &models.AccountInfo{Id: 1, Meta: &models.DocumentMeta{Revision: 3}},
+			ifNoneMatch:  `"2"`,
+			expectedETag: `"3"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/api/account-info/1", nil)
+			c.Request.Header.Set("If-None-Match", tt.ifNoneMatch)
+
+			// action
+			notModified := setETag(c, tt.info)
+
+			// assert
+			assert.Equal(t, tt.expectedNotModified, notModified)
+			assert.Equal(t, tt.expectedETag, recorder.Header().Get("ETag"))
+		})
+	}
+}
+
+func TestHandler_GetIfMatchRevision(t *testing.T) {
+	tests := []struct {
+		name             string
+		ifMatch          string
+		expectedRevision uint64
+		isErrorExpected  bool
+	}{
+		{name: "Test_Missing", expectedRevision: 0},
+		{name: "Test_Strong_ETag", ifMatch: `"4"`, expectedRevision: 4},
+		{name: "Test_Weak_ETag", ifMatch: `W/"4"`, isErrorExpected: true},
+		{name: "Test_Any", ifMatch: `*`, isErrorExpected: true},
+		{name: "Test_Unknown_ETag", ifMatch: `"abc"`, isErrorExpected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPut, "/v1/api/account-info/1", nil)
+			c.Request.Header.Set("If-Match", tt.ifMatch)
+
+			// action
+			revision, err := getIfMatchRevision(c)
+
+			// assert
+			if tt.isErrorExpected {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedRevision, revision)
+		})
+	}
+}
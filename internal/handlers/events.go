@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	errors2 "immudb/internal/errors"
+	"immudb/internal/events"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// keepAliveInterval a comment is sent this often so proxies don't close an idle stream
+const keepAliveInterval = 15 * time.Second
+
+// EventStream is implemented by the event stream
+type EventStream interface {
+	Subscribe(types []models.EventType) (<-chan events.StreamEvent, func())
+	Resumable(ctx context.Context, cursor persistance.ChangeCursor) error
+	Replay(ctx context.Context, cursor persistance.ChangeCursor, types []models.EventType, write func(event events.StreamEvent) error) (persistance.ChangeCursor, error)
+}
+
+// StreamEvents
+//
+// @Summary      Stream account events
+// @Description  Server-Sent Events of the accounts committed by any instance, in ledger order as the change data capture reads them.
+// @Description  The event name is the type and the data the same JSON webhooks get.
+// @Description  The id of every event is its position in the ledger, a client reconnecting with Last-Event-ID first gets what it missed.
+// @Description  A Last-Event-ID whose next event is older than the replay window is refused, the client reconnects without it and reloads the accounts.
+// @Description  A client that falls too far behind is disconnected and resumes the same way.
+// @ID           stream-events
+// @Produce      text/event-stream
+// @Param        types          query   string  false  "Event types separated by commas, all when empty"
+// @Param        Last-Event-ID  header  string  false  "Id of the last event the client got"
+// @Success      200  {object}  events.Envelope "Stream of events"
+// @Failure      400  {object}  Response[string]  "Bad request, unknown event type, invalid Last-Event-ID or one older than the replay window"
+// @Failure      500  {object}  Response[string]  "The change feed could not be read"
+// @Failure      501  {object}  Response[string]  "The change data capture is off or the backend has no change feed"
+// @Router       /events [get]
+func (h *Handler) StreamEvents(c *gin.Context) {
+	if h.Events == nil {
+		AbortWithMessage(c, http.StatusNotImplemented, errors.New("the event stream needs the change data capture of a backend with a change feed"), "the event stream is not configured")
+		return
+	}
+	types, err := getEventTypes(c)
+	if err != nil {
+		AbortWithMessage(c, http.StatusBadRequest, err, "invalid event types")
+		return
+	}
+	cursor, resumed, err := getLastEventId(c)
+	if err != nil {
+		AbortWithMessage(c, http.StatusBadRequest, err, "invalid Last-Event-ID")
+		return
+	}
+	if resumed {
+		err = h.Events.Resumable(c.Request.Context(), cursor)
+		if errors.Is(err, events.ErrReplayTooOld) {
+			AbortWithMessage(c, http.StatusBadRequest, errors2.NewValidationError(http.StatusBadRequest, errors2.InvalidParam{Name: "Last-Event-ID", Reason: err.Error()}), "invalid Last-Event-ID")
+			return
+		}
+		if err != nil {
+			AbortWithMessage(c, http.StatusInternalServerError, err, "failed to read the change feed")
+			return
+		}
+	}
+
+	// subscribed before the replay, so nothing committed in between is missed
+	live, unsubscribe := h.Events.Subscribe(types)
+	defer unsubscribe()
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	ctx := c.Request.Context()
+	if resumed {
+		cursor, err = h.Events.Replay(ctx, cursor, types, func(event events.StreamEvent) error {
+			return writeEvent(c, event)
+		})
+		if err != nil {
+			// the client reconnects from the last event it got
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-live:
+			if !ok {
+				return
+			}
+			// already sent by the replay
+			if resumed && !cursor.Before(event.Cursor) {
+				continue
+			}
+			if writeEvent(c, event) != nil {
+				return
+			}
+		case <-keepAlive.C:
+			_, err = c.Writer.WriteString(": keep-alive\n\n")
+			if err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+func writeEvent(c *gin.Context, event events.StreamEvent) error {
+	err := sse.Encode(c.Writer, sse.Event{
+		Id:    events.StreamId(event.Cursor),
+		Event: string(event.Type),
+		Data:  events.NewEnvelope(event.AccountEvent),
+	})
+	if err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
+
+func getEventTypes(c *gin.Context) ([]models.EventType, error) {
+	var types []models.EventType
+	for _, value := range strings.Split(c.Query("types"), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		eventType := models.EventType(value)
+		if !slices.Contains(models.EventTypes, eventType) {
+			return nil, errors2.NewValidationError(http.StatusBadRequest, errors2.InvalidParam{Name: "types", Reason: "unknown event type " + value})
+		}
+		types = append(types, eventType)
+	}
+	return types, nil
+}
+
+// getLastEventId is not resumed when the client starts fresh
+func getLastEventId(c *gin.Context) (persistance.ChangeCursor, bool, error) {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		return persistance.ChangeCursor{}, false, nil
+	}
+	cursor, err := events.ParseStreamId(value)
+	if err != nil {
+		return persistance.ChangeCursor{}, false, errors2.NewValidationError(http.StatusBadRequest, errors2.InvalidParam{Name: "Last-Event-ID", Reason: "Last-Event-ID must be the id of an event"})
+	}
+	return cursor, true, nil
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"immudb/internal/events"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_StreamEvents(t *testing.T) {
+	// setup
+	db := persistance.NewMemoryDB()
+	var created []*models.AccountInfo
+	for _, name := range []string{"first", "second"} {
+		account, err := db.CreateAccountInfo(context.Background(), models.AccountInfo{Name: name, Iban: "AL47", Type: persistance.AddPointer(models.Sending)})
+		assert.NoError(t, err)
+		created = append(created, account)
+	}
+	second := created[1].Meta.TransactionId
+	resumed := fmt.Sprintf("id:%s\nevent:account.created\ndata:{\"id\":\"%d-1\",", events.StreamId(persistance.CursorOf(created[1])), created[1].Id) +
+		fmt.Sprintf(`"type":"account.created","occurred_at":"%s","transaction_id":%d,"data":{"account_number":%d,`, created[1].Meta.Timestamp.Format(time.RFC3339Nano), second, created[1].Id)
+	tests := []struct {
+		name             string
+		target           string
+		lastEventId      string
+		replayWindow     time.Duration
+		expectedStatus   int
+		expectedContains string
+	}{
+		{
+			name:             "Test_Resume",
+			target:           "/events?types=account.created",
+			lastEventId:      events.StreamId(persistance.CursorOf(created[0])),
+			expectedStatus:   http.StatusOK,
+			expectedContains: resumed,
+		},
+		{
+			name:             "Test_Resume_From_Transaction_Id",
+			target:           "/events?types=account.created",
+			lastEventId:      fmt.Sprint(created[0].Meta.TransactionId),
+			expectedStatus:   http.StatusOK,
+			expectedContains: resumed,
+		},
+		{
+			name:             "Test_Resume_Outside_The_Replay_Window",
+			target:           "/events",
+			lastEventId:      "0",
+			replayWindow:     time.Nanosecond,
+			expectedStatus:   http.StatusBadRequest,
+			expectedContains: "older than the replay window",
+		},
+		{
+			name:             "Test_Unknown_Type",
+			target:           "/events?types=account.deleted",
+			expectedStatus:   http.StatusBadRequest,
+			expectedContains: "unknown event type account.deleted",
+		},
+		{
+			name:             "Test_Invalid_Last_Event_Id",
+			target:           "/events",
+			lastEventId:      "yesterday",
+			expectedStatus:   http.StatusBadRequest,
+			expectedContains: "Last-Event-ID must be the id of an event",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			stream := events.NewStream(db)
+			if tt.replayWindow > 0 {
+				stream.ReplayWindow = tt.replayWindow
+			}
+			router := gin.New()
+			handler := &Handler{Events: stream}
+			router.GET("/events", handler.StreamEvents)
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			request := httptest.NewRequest(http.MethodGet, tt.target, nil).WithContext(ctx)
+			if tt.lastEventId != "" {
+				request.Header.Set("Last-Event-ID", tt.lastEventId)
+			}
+			recorder := httptest.NewRecorder()
+
+			// action
+			router.ServeHTTP(recorder, request)
+
+			// assertions
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tt.expectedContains)
+			assert.NotContains(t, recorder.Body.String(), `"account_name":"first"`)
+		})
+	}
+}
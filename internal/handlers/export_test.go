@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"testing"
+)
+
+func TestHandler_ExportEncoders(t *testing.T) {
+	accounts := []*models.AccountInfo{
+		{
+			Id:         1,
+			DocumentId: "doc",
+			Name:       "Doe, John",
+			Iban:       "AL47",
+			Address:    persistance.AddPointer("Tirana"),
+			Amount:     10.5,
+			Type:       persistance.AddPointer(models.Receiving),
+		},
+		{Id: 2, Name: "Jane", Iban: "AL48"},
+	}
+	ndjson := func(output *bytes.Buffer) (exportEncoder, error) {
+		return &ndjsonEncoder{encoder: json.NewEncoder(output)}, nil
+	}
+	csvOutput := func(output *bytes.Buffer) (exportEncoder, error) {
+		return newCsvEncoder(output)
+	}
+	tests := []struct {
+		name     string
+		encoder  func(output *bytes.Buffer) (exportEncoder, error)
+		expected string
+	}{
+		{
+			name:    "Test_Ndjson",
+			encoder: ndjson,
+			expected: `{"account_number":1,"document_id":"doc","account_name":"Doe, John","iban":"AL47","address":"Tirana","amount":10.5,"type":2}` + "\n" +
+				`{"account_number":2,"account_name":"Jane","iban":"AL48","address":null,"amount":0,"type":null}` + "\n",
+		},
+		{
+			name:    "Test_Csv",
+			encoder: csvOutput,
+			expected: "account_number,document_id,account_name,iban,address,amount,type\n" +
+				"1,doc,\"Doe, John\",AL47,Tirana,10.5,2\n" +
+				"2,,Jane,AL48,,0,\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			output := &bytes.Buffer{}
+			encoder, err := tt.encoder(output)
+			assert.NoError(t, err)
+
+			// action
+			for _, account := range accounts {
+				assert.NoError(t, encoder.Encode(account))
+			}
+			assert.NoError(t, encoder.Flush())
+
+			// assertions
+			assert.Equal(t, tt.expected, output.String())
+		})
+	}
+}
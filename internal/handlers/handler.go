@@ -5,13 +5,20 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	errors2 "immudb/internal/errors"
+	"immudb/internal/models"
 	"immudb/internal/services"
+	"net/http"
 	"strconv"
+	"time"
 )
 
 type Handler struct {
-	Service services.Service
-	Engine  *gin.Engine
+	Service  services.Service
+	Engine   *gin.Engine
+	Auditor  AuditStatusProvider // nil when the background audit is disabled
+	Imports  ImportJobs          // nil when imports are not configured
+	Webhooks Webhooks            // nil when webhooks are not configured
+	Events   EventStream         // nil when the backend has no change feed
 }
 
 type Response[T any] struct {
@@ -19,21 +26,43 @@ type Response[T any] struct {
 	Err  string `json:"error_message"`
 }
 
-func NewHandler(bookService services.Service, router *gin.Engine) *Handler {
+func NewHandler(bookService services.Service, router *gin.Engine, checks ...HealthChecker) *Handler {
 	handler := &Handler{
 		Service: bookService,
 		Engine:  router,
 	}
-	SetupHealth(router)
+	SetupHealth(router, checks...)
 	v1 := router.Group("/v1/api")
 
-	// register account
-	v1.GET("/account-info", handler.GetAccountInfos)
-	v1.GET("/account-info/:id", handler.GetAccountInfo)
+	// register account, reads that render through returnOk negotiate the format before the backend is called
+	v1.GET("/account-info", negotiate, handler.GetAccountInfos)
+	v1.GET("/account-info/search", negotiate, handler.SearchAccountInfos)
+	v1.GET("/account-info/stats", negotiate, handler.GetAccountStats)
+	v1.GET("/account-info/export", handler.ExportAccountInfos)
+	v1.GET("/account-info/:id", negotiate, handler.GetAccountInfo)
+	v1.GET("/account-info/:id/diff", negotiate, handler.GetAccountInfoDiff)
+	v1.GET("/account-info/document/:documentId", negotiate, handler.GetAccountInfoByDocument)
 	v1.PUT("/account-info/:id", handler.UpdateAccountInfo)
 	v1.POST("/account-info", handler.CreateAccountInfo)
+	v1.POST("/account-info:action", handler.AccountInfoAction)
+	v1.POST("/account-info/import", handler.ImportAccountInfos)
 	v1.DELETE("/account-info/:id", handler.DeleteAccountInfo)
 
+	v1.GET("/imports/:id", negotiate, handler.GetImportJob)
+	v1.GET("/imports/:id/errors", handler.GetImportErrors)
+
+	v1.GET("/audit/status", negotiate, handler.GetAuditStatus)
+
+	v1.GET("/events", handler.StreamEvents)
+
+	v1.POST("/webhooks", handler.CreateWebhook)
+	v1.GET("/webhooks", negotiate, handler.GetWebhooks)
+	v1.GET("/webhooks/:id", negotiate, handler.GetWebhook)
+	v1.PUT("/webhooks/:id", handler.UpdateWebhook)
+	v1.DELETE("/webhooks/:id", handler.DeleteWebhook)
+	v1.GET("/webhooks/:id/dead-letters", negotiate, handler.GetWebhookDeadLetters)
+	v1.POST("/webhooks/:id/dead-letters/:deliveryId/redeliver", handler.RedeliverWebhook)
+
 	return handler
 }
 
@@ -41,10 +70,19 @@ func AbortWithMessage(c *gin.Context, status int, err error, message string) {
 	logrus.WithError(err).Error(message)
 
 	// if custom validation error update status and message
+	var problemType string
+	var invalidParams []errors2.InvalidParam
 	var badRequest *errors2.ServiceError
 	if errors.As(err, &badRequest) {
 		status = badRequest.Status
 		message = err.Error()
+		problemType = badRequest.Type
+		invalidParams = badRequest.InvalidParams
+	}
+
+	if acceptsProblemJson(c) {
+		abortWithProblem(c, status, problemType, message, invalidParams)
+		return
 	}
 
 	c.AbortWithStatusJSON(status, Response[string]{
@@ -64,8 +102,30 @@ func getQueryParamUInt(c *gin.Context, paramName string) (int, error) {
 	return int(idValue), err
 }
 
-func returnOk[T any](c *gin.Context, status int, data T) {
-	c.IndentedJSON(status, Response[T]{
-		Data: data,
-	})
+// getQueryParamBool a missing parameter is false
+func getQueryParamBool(c *gin.Context, paramName string) (bool, error) {
+	value := c.Query(paramName)
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// getQueryParamAsOf accepts either a transaction id or an RFC 3339 timestamp, nil means the current state
+func getQueryParamAsOf(c *gin.Context, paramName string) (*models.AsOf, error) {
+	value := c.Query(paramName)
+	if value == "" {
+		return nil, nil
+	}
+	if transactionId, err := strconv.ParseUint(value, 10, 64); err == nil && transactionId > 0 {
+		return &models.AsOf{TransactionId: transactionId}, nil
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, errors2.NewValidationError(http.StatusBadRequest, errors2.InvalidParam{
+			Name:   paramName,
+			Reason: "asOf must be a transaction id or an RFC 3339 timestamp",
+		})
+	}
+	return &models.AsOf{Timestamp: &timestamp}, nil
 }
@@ -1,8 +1,16 @@
 package handlers
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
 
-func SetupHealth(router *gin.Engine) {
+// HealthChecker reports a problem with one of the dependencies, nil means healthy
+type HealthChecker interface {
+	Healthy() error
+}
+
+func SetupHealth(router *gin.Engine, checks ...HealthChecker) {
 	// now this is a simplifies version of this but in a real life application i would check if we are connected to immudb vault also(typically check all the depenecies)
 	router.GET("/", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -10,4 +18,22 @@ func SetupHealth(router *gin.Engine) {
 		})
 	})
 
+	router.GET("/health", func(c *gin.Context) {
+		var problems []string
+		for _, check := range checks {
+			if err := check.Healthy(); err != nil {
+				problems = append(problems, err.Error())
+			}
+		}
+		if len(problems) > 0 {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status": "unhealthy",
+				"errors": problems,
+			})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"status": "healthy",
+		})
+	})
 }
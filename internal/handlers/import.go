@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	errors2 "immudb/internal/errors"
+	"immudb/internal/importer"
+	"immudb/internal/models"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ImportJobs is implemented by the import manager
+type ImportJobs interface {
+	Start(upload io.Reader, size int64, options importer.Options) (*importer.Job, error)
+	Get(id string) (*importer.Job, error)
+	OpenReport(id string) (io.ReadCloser, error)
+}
+
+type ImportJobDto struct {
+	Id         string     `json:"id"`
+	Status     string     `json:"status"`
+	DryRun     bool       `json:"dry_run"`
+	Rows       int        `json:"rows"`
+	Imported   int        `json:"imported"`
+	Failed     int        `json:"failed"`
+	BytesRead  int64      `json:"bytes_read"`
+	TotalBytes int64      `json:"total_bytes,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// ImportAccountInfos
+//
+// @Summary      Import account infos from CSV
+// @Description  Starts a background import of the uploaded CSV, either the raw request body or the "file" field of a multipart form.
+// @Description  The first line is the header, mapping picks the columns as field=column pairs, for example account_name=Name,iban=IBAN.
+// @Description  Follow the job on the returned Location, rejected rows are listed in the error report of the job.
+// @ID           import-account-infos
+// @Accept       text/csv
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file     formData  file    false  "CSV file, when the body is a multipart form"
+// @Param        dryRun   query     bool    false  "Only validate, nothing is written"
+// @Param        mapping  query     string  false  "Column mapping, field=column pairs separated by commas"
+// @Success      202  {object}  Response[ImportJobDto] "Import started"
+// @Failure      400  {object}  Response[string]  "Bad request, invalid mapping or upload"
+// @Failure      501  {object}  Response[string]  "Imports are not configured"
+// @Router       /account-info/import [post]
+func (h *Handler) ImportAccountInfos(c *gin.Context) {
+	if h.Imports == nil {
+		AbortWithMessage(c, http.StatusNotImplemented, errors.New("imports are not configured"), "imports are not configured")
+		return
+	}
+	options, err := getImportOptions(c)
+	if err != nil {
+		AbortWithMessage(c, http.StatusBadRequest, err, "invalid import")
+		return
+	}
+	upload, size, err := getUpload(c)
+	if err != nil {
+		AbortWithMessage(c, http.StatusBadRequest, err, "invalid upload")
+		return
+	}
+	job, err := h.Imports.Start(upload, size, options)
+	if err != nil {
+		AbortWithMessage(c, http.StatusInternalServerError, err, "failed to start the import")
+		return
+	}
+	state := job.Snapshot()
+	c.Header("Location", fmt.Sprintf("/v1/api/imports/%s", state.Id))
+	returnOk(c, http.StatusAccepted, convertImportJobToDTO(state))
+}
+
+// GetImportJob
+//
+// @Summary      Get an import job
+// @Description  Progress of an import, bytes_read against total_bytes tells how far a raw upload got
+// @ID           get-import-job
+// @Produce      json
+// @Produce      text/csv
+// @Produce      application/msgpack
+// @Param        id   path      string  true  "Job id"
+// @Success      200  {object}  Response[ImportJobDto] "Import job"
+// @Failure      404  {object}  Response[string]  "Job not found"
+// @Failure      406  {object}  Response[string]  "None of the accepted types is supported"
+// @Router       /imports/{id} [get]
+func (h *Handler) GetImportJob(c *gin.Context) {
+	if h.Imports == nil {
+		AbortWithMessage(c, http.StatusNotImplemented, errors.New("imports are not configured"), "imports are not configured")
+		return
+	}
+	job, err := h.Imports.Get(c.Param("id"))
+	if err != nil {
+		AbortWithMessage(c, http.StatusNotFound, err, "import job not found")
+		return
+	}
+	returnOk(c, http.StatusOK, convertImportJobToDTO(job.Snapshot()))
+}
+
+// GetImportErrors
+//
+// @Summary      Download the error report of an import
+// @Description  CSV with line, field and reason for every rejected row, while the job runs it has the rows rejected so far
+// @ID           get-import-errors
+// @Produce      text/csv
+// @Param        id   path      string  true  "Job id"
+// @Success      200  {file}    file    "Error report"
+// @Failure      404  {object}  Response[string]  "Job not found"
+// @Router       /imports/{id}/errors [get]
+func (h *Handler) GetImportErrors(c *gin.Context) {
+	if h.Imports == nil {
+		AbortWithMessage(c, http.StatusNotImplemented, errors.New("imports are not configured"), "imports are not configured")
+		return
+	}
+	report, err := h.Imports.OpenReport(c.Param("id"))
+	if err != nil {
+		AbortWithMessage(c, http.StatusNotFound, err, "import job not found")
+		return
+	}
+	defer report.Close()
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="import-%s-errors.csv"`, c.Param("id")))
+	c.DataFromReader(http.StatusOK, -1, "text/csv", report, nil)
+}
+
+func getImportOptions(c *gin.Context) (importer.Options, error) {
+	options := importer.Options{}
+	if value := c.Query("dryRun"); value != "" {
+		dryRun, err := strconv.ParseBool(value)
+		if err != nil {
+			return options, errors2.NewValidationError(http.StatusBadRequest, errors2.InvalidParam{Name: "dryRun", Reason: "dryRun must be true or false"})
+		}
+		options.DryRun = dryRun
+	}
+	mapping, err := importer.ParseMapping(c.Query("mapping"))
+	if err != nil {
+		return options, errors2.NewValidationError(http.StatusBadRequest, errors2.InvalidParam{Name: "mapping", Reason: err.Error()})
+	}
+	options.Mapping = mapping
+	return options, nil
+}
+
+// getUpload streams the csv out of the request, a multipart form is read part by part instead of being parsed up front
+func getUpload(c *gin.Context) (io.Reader, int64, error) {
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return c.Request.Body, max(c.Request.ContentLength, 0), nil
+	}
+	parts, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, 0, err
+	}
+	for {
+		part, err := parts.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, 0, errors2.NewValidationError(http.StatusBadRequest, errors2.InvalidParam{Name: "file", Reason: "the form has no file field"})
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		if part.FormName() == "file" {
+			return part, 0, nil
+		}
+	}
+}
+
+func convertImportJobToDTO(job models.ImportJob) ImportJobDto {
+	return ImportJobDto{
+		Id:         job.Id,
+		Status:     string(job.Status),
+		DryRun:     job.DryRun,
+		Rows:       job.Rows,
+		Imported:   job.Imported,
+		Failed:     job.Failed,
+		BytesRead:  job.BytesRead,
+		TotalBytes: job.TotalBytes,
+		Error:      job.Error,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/render"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const MIMECSV = "text/csv"
+
+// offeredFormats are the response types of the read endpoints, the first one is the default.
+// Problem details clients get the json body, their errors are the only part that differs.
+var offeredFormats = []string{gin.MIMEJSON, MIMEProblemJSON, MIMECSV, binding.MIMEMSGPACK2, binding.MIMEMSGPACK}
+
+var errNotAcceptable = errors.New("not acceptable")
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// returnOk renders data in the format the Accept header asks for, writes keep answering with json
+func returnOk[T any](c *gin.Context, status int, data T) {
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		renderJSON(c, status, Response[T]{Data: data})
+		return
+	}
+	c.Header("Vary", "Accept")
+	switch c.NegotiateFormat(offeredFormats...) {
+	case gin.MIMEJSON, MIMEProblemJSON:
+		renderJSON(c, status, Response[T]{Data: data})
+	case MIMECSV:
+		renderCSV(c, status, data)
+	case binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+		c.Render(status, render.MsgPack{Data: Response[T]{Data: data}})
+	default:
+		abortNotAcceptable(c)
+	}
+}
+
+// negotiate answers 406 before a read reaches the backend when none of the offered formats is accepted
+func negotiate(c *gin.Context) {
+	if c.NegotiateFormat(offeredFormats...) == "" {
+		abortNotAcceptable(c)
+	}
+}
+
+// renderJSON indents only in debug mode, in release mode the whitespace is just wasted bandwidth
+func renderJSON(c *gin.Context, status int, body any) {
+	if gin.IsDebugging() {
+		c.IndentedJSON(status, body)
+		return
+	}
+	c.JSON(status, body)
+}
+
+func abortNotAcceptable(c *gin.Context) {
+	AbortWithMessage(c, http.StatusNotAcceptable, errNotAcceptable,
+		fmt.Sprintf("supported response types are %s, %s and %s", gin.MIMEJSON, MIMECSV, binding.MIMEMSGPACK2))
+}
+
+// renderCSV writes a struct or a list of structs as rows, without the Response envelope.
+// Other payloads have no table shape and are not acceptable as CSV.
+func renderCSV(c *gin.Context, status int, data any) {
+	header, rows, ok := csvTable(data)
+	if !ok {
+		abortNotAcceptable(c)
+		return
+	}
+	c.Header("Content-Type", MIMECSV+"; charset=utf-8")
+	c.Status(status)
+	writer := csv.NewWriter(c.Writer)
+	_ = writer.Write(header)
+	_ = writer.WriteAll(rows)
+}
+
+// csvTable flattens the json fields of the structs into columns, nested structs become prefix.field columns
+// and lists inside a struct are kept as json in their cell
+func csvTable(data any) ([]string, [][]string, bool) {
+	value := reflect.ValueOf(data)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil, nil, false
+		}
+		value = value.Elem()
+	}
+	var items []reflect.Value
+	itemType := value.Type()
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		itemType = itemType.Elem()
+		for i := 0; i < value.Len(); i++ {
+			items = append(items, value.Index(i))
+		}
+	} else {
+		items = append(items, value)
+	}
+	for itemType.Kind() == reflect.Pointer {
+		itemType = itemType.Elem()
+	}
+	if itemType.Kind() != reflect.Struct || itemType == timeType {
+		return nil, nil, false
+	}
+
+	var columns []csvColumn
+	collectColumns(itemType, nil, "", &columns)
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.name
+	}
+	rows := make([][]string, 0, len(items))
+	for _, item := range items {
+		for item.Kind() == reflect.Pointer && !item.IsNil() {
+			item = item.Elem()
+		}
+		if item.Kind() == reflect.Pointer {
+			continue
+		}
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = csvCell(item, column.index)
+		}
+		rows = append(rows, row)
+	}
+	return header, rows, true
+}
+
+type csvColumn struct {
+	name  string
+	index []int // field index path from the row struct
+}
+
+func collectColumns(structType reflect.Type, index []int, prefix string, columns *[]csvColumn) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		isStruct := fieldType.Kind() == reflect.Struct && fieldType != timeType
+		if field.Anonymous && name == "" && isStruct {
+			collectColumns(fieldType, fieldIndex, prefix, columns)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if isStruct {
+			collectColumns(fieldType, fieldIndex, prefix+name+".", columns)
+			continue
+		}
+		*columns = append(*columns, csvColumn{name: prefix + name, index: fieldIndex})
+	}
+}
+
+func csvCell(item reflect.Value, index []int) string {
+	value := item
+	for _, i := range index {
+		for value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				return ""
+			}
+			value = value.Elem()
+		}
+		value = value.Field(i)
+	}
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.String:
+		return value.String()
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, value.Type().Bits())
+	}
+	if value.Type() == timeType {
+		return value.Interface().(time.Time).Format(time.RFC3339Nano)
+	}
+	encoded, err := json.Marshal(value.Interface())
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/ugorji/go/codec"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_ReturnOk(t *testing.T) {
+	accounts := []*AccountInfoDto{
+		{AccountNumber: 1, DocumentId: "doc", AccountName: "Doe, John", Iban: "AL47", Address: persistance.AddPointer("Tirana"), Amount: 10.5, Type: persistance.AddPointer(models.Receiving)},
+		{AccountNumber: 2, AccountName: "Jane", Iban: "AL48"},
+	}
+	verified := VerifiedAccountInfoDto{
+		AccountInfoDto: *accounts[1],
+		Verification:   &VerificationDto{Verified: true, TransactionId: 7, VerifiedAt: time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	tests := []struct {
+		name                string
+		method              string
+		accept              string
+		release             bool
+		data                any
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "Test_Default_Json",
+			data:                accounts[1:],
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody: `{
+    "data": [
+        {
+            "account_number": 2,
+            "account_name": "Jane",
+            "iban": "AL48",
+            "address": null,
+            "amount": 0,
+            "type": null
+        }
+    ],
+    "error_message": ""
+}`,
+		},
+		{
+			name:                "Test_Compact_Json_In_Release",
+			accept:              "application/json",
+			release:             true,
+			data:                accounts[1:],
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"data":[{"account_number":2,"account_name":"Jane","iban":"AL48","address":null,"amount":0,"type":null}],"error_message":""}`,
+		},
+		{
+			name:                "Test_Problem_Json_Client",
+			accept:              "application/problem+json",
+			release:             true,
+			data:                "ok",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"data":"ok","error_message":""}`,
+		},
+		{
+			name:                "Test_Csv_List",
+			accept:              "text/csv",
+			data:                accounts,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody: "account_number,document_id,account_name,iban,address,amount,type\n" +
+				"1,doc,\"Doe, John\",AL47,Tirana,10.5,2\n" +
+				"2,,Jane,AL48,,0,\n",
+		},
+		{
+			name:                "Test_Csv_Nested_Struct",
+			accept:              "text/csv;q=0.9",
+			data:                verified,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody: "account_number,document_id,account_name,iban,address,amount,type," +
+				"verification.verified,verification.document_id,verification.transaction_id,verification.ledger_transaction_id,verification.root_hash,verification.verified_at\n" +
+				"2,,Jane,AL48,,0,,true,,7,0,,2024-08-01T00:00:00Z\n",
+		},
+		{
+			name:                "Test_Csv_Empty_List",
+			accept:              "text/csv",
+			data:                []*AccountInfoDto{},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody:        "account_number,document_id,account_name,iban,address,amount,type\n",
+		},
+		{
+			name:                "Test_Csv_Not_A_Table",
+			accept:              "text/csv",
+			release:             true,
+			data:                "ok",
+			expectedStatus:      http.StatusNotAcceptable,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"data":"","error_message":"supported response types are application/json, text/csv and application/msgpack"}`,
+		},
+		{
+			name:                "Test_Unsupported_Type",
+			accept:              "application/xml",
+			release:             true,
+			data:                accounts,
+			expectedStatus:      http.StatusNotAcceptable,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"data":"","error_message":"supported response types are application/json, text/csv and application/msgpack"}`,
+		},
+		{
+			name:                "Test_Wildcard",
+			accept:              "application/xml, */*",
+			release:             true,
+			data:                "ok",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"data":"ok","error_message":""}`,
+		},
+		{
+			name:                "Test_Writes_Stay_Json",
+			method:              http.MethodPost,
+			accept:              "text/csv",
+			release:             true,
+			data:                "ok",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"data":"ok","error_message":""}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			if tt.release {
+				gin.SetMode(gin.ReleaseMode)
+				defer gin.SetMode(gin.DebugMode)
+			}
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = httptest.NewRequest(method, "/v1/api/account-info", nil)
+			if tt.accept != "" {
+				c.Request.Header.Set("Accept", tt.accept)
+			}
+
+			// action
+			returnOk(c, http.StatusOK, tt.data)
+
+			// assertions
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedContentType, recorder.Header().Get("Content-Type"))
+			assert.Equal(t, tt.expectedBody, recorder.Body.String())
+		})
+	}
+}
+
+func TestHandler_ReturnOkMsgPack(t *testing.T) {
+	// setup
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/api/account-info/2", nil)
+	c.Request.Header.Set("Accept", "application/msgpack")
+
+	// action
+	returnOk(c, http.StatusOK, &AccountInfoDto{AccountNumber: 2, AccountName: "Jane", Iban: "AL48", Amount: 1.5})
+
+	// assertions
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/msgpack; charset=utf-8", recorder.Header().Get("Content-Type"))
+	handle := &codec.MsgpackHandle{}
+	handle.RawToString = true
+	var decoded map[string]interface{}
+	assert.NoError(t, codec.NewDecoderBytes(recorder.Body.Bytes(), handle).Decode(&decoded))
+	data, ok := decoded["data"].(map[interface{}]interface{})
+	assert.True(t, ok)
+	assert.Equal(t, "Jane", data["account_name"])
+	assert.Equal(t, 1.5, data["amount"])
+}
+
+func TestHandler_Negotiate(t *testing.T) {
+	tests := []struct {
+		name           string
+		accept         string
+		expectedStatus int
+		isReadExpected bool
+	}{
+		{name: "Test_No_Accept", expectedStatus: http.StatusOK, isReadExpected: true},
+		{name: "Test_Csv", accept: "text/csv", expectedStatus: http.StatusOK, isReadExpected: true},
+		{name: "Test_Not_Acceptable", accept: "image/png", expectedStatus: http.StatusNotAcceptable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			read := false
+			router := gin.New()
+			router.GET("/v1/api/account-info", negotiate, func(c *gin.Context) {
+				read = true
+				c.Status(http.StatusOK)
+			})
+			request := httptest.NewRequest(http.MethodGet, "/v1/api/account-info", nil)
+			if tt.accept != "" {
+				request.Header.Set("Accept", tt.accept)
+			}
+			recorder := httptest.NewRecorder()
+
+			// action
+			router.ServeHTTP(recorder, request)
+
+			// assertions
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.isReadExpected, read, "a read the client can't accept must not reach the backend")
+		})
+	}
+}
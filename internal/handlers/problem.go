@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"immudb/internal/errors"
+	"net/http"
+	"strings"
+)
+
+const (
+	MIMEProblemJSON    = "application/problem+json"
+	problemTypeDefault = "about:blank"
+)
+
+// ProblemDetails is the RFC 7807 error body, returned when the client asks for application/problem+json
+type ProblemDetails struct {
+	Type          string                `json:"type"`
+	Title         string                `json:"title"`
+	Status        int                   `json:"status"`
+	Detail        string                `json:"detail,omitempty"`
+	Instance      string                `json:"instance,omitempty"`
+	InvalidParams []errors.InvalidParam `json:"invalid_params,omitempty"`
+}
+
+// acceptsProblemJson the legacy Response shape stays the default, problem details are opt-in
+func acceptsProblemJson(c *gin.Context) bool {
+	for _, accept := range strings.Split(c.GetHeader("Accept"), ",") {
+		mediaType, _, _ := strings.Cut(accept, ";")
+		if strings.EqualFold(strings.TrimSpace(mediaType), MIMEProblemJSON) {
+			return true
+		}
+	}
+	return false
+}
+
+func abortWithProblem(c *gin.Context, status int, problemType, detail string, invalidParams []errors.InvalidParam) {
+	if problemType == "" {
+		problemType = problemTypeDefault
+	}
+	problem := ProblemDetails{
+		Type:          problemType,
+		Title:         http.StatusText(status),
+		Status:        status,
+		Detail:        detail,
+		Instance:      c.Request.URL.RequestURI(),
+		InvalidParams: invalidParams,
+	}
+	if problemType == errors.TypeValidation {
+		problem.Title = "Your request parameters didn't validate."
+	}
+	c.Header("Content-Type", MIMEProblemJSON)
+	c.AbortWithStatusJSON(status, problem)
+}
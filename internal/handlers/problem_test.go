@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	cerror "immudb/internal/errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_AbortWithMessage(t *testing.T) {
+	validationError := cerror.NewValidationError(http.StatusBadRequest,
+		cerror.InvalidParam{Name: "iban", Reason: "invalid iban for the account"},
+		cerror.InvalidParam{Name: "type", Reason: "invalid type for the account"},
+	)
+	tests := []struct {
+		name                string
+		accept              string
+		status              int
+		err                 error
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        interface{}
+	}{
+		{
+			name:                "Test_Legacy_Shape_Is_Default",
+			status:              http.StatusInternalServerError,
+			err:                 validationError,
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        map[string]interface{}{"data": "", "error_message": "invalid iban for the account; invalid type for the account"},
+		},
+		{
+			name:                "Test_Problem_Json_Validation",
+			accept:              "application/problem+json, application/json;q=0.9",
+			status:              http.StatusInternalServerError,
+			err:                 validationError,
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: MIMEProblemJSON,
+			expectedBody: map[string]interface{}{
+				"type":     cerror.TypeValidation,
+				"title":    "Your request parameters didn't validate.",
+				"status":   float64(http.StatusBadRequest),
+				"detail":   "invalid iban for the account; invalid type for the account",
+				"instance": "/v1/api/account-info",
+				"invalid_params": []interface{}{
+					map[string]interface{}{"name": "iban", "reason": "invalid iban for the account"},
+					map[string]interface{}{"name": "type", "reason": "invalid type for the account"},
+				},
+			},
+		},
+		{
+			name:                "Test_Problem_Json_Not_Found",
+			accept:              MIMEProblemJSON,
+			status:              http.StatusInternalServerError,
+			err:                 cerror.NewServiceError("account info not found", http.StatusNotFound),
+			expectedStatus:      http.StatusNotFound,
+			expectedContentType: MIMEProblemJSON,
+			expectedBody: map[string]interface{}{
+				"type":     problemTypeDefault,
+				"title":    "Not Found",
+				"status":   float64(http.StatusNotFound),
+				"detail":   "account info not found",
+				"instance": "/v1/api/account-info",
+			},
+		},
+		{
+			name:                "Test_Problem_Json_Unexpected_Error",
+			accept:              MIMEProblemJSON,
+			status:              http.StatusInternalServerError,
+			err:                 fmt.Errorf("test error"),
+			expectedStatus:      http.StatusInternalServerError,
+			expectedContentType: MIMEProblemJSON,
+			expectedBody: map[string]interface{}{
+				"type":     problemTypeDefault,
+				"title":    "Internal Server Error",
+				"status":   float64(http.StatusInternalServerError),
+				"detail":   "failed to load accountInfos",
+				"instance": "/v1/api/account-info",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = httptest.NewRequest(http.MethodGet, "/v1/api/account-info", nil)
+			if tt.accept != "" {
+				c.Request.Header.Set("Accept", tt.accept)
+			}
+
+			// action
+			AbortWithMessage(c, tt.status, tt.err, "failed to load accountInfos")
+
+			// assert
+			var body map[string]interface{}
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedContentType, recorder.Header().Get("Content-Type"))
+			assert.Equal(t, tt.expectedBody, body)
+		})
+	}
+}
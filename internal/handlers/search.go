@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	errors2 "immudb/internal/errors"
+	"immudb/internal/models"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const DefaultSearchPageSize = 10
+
+type AccountStatsDto struct {
+	Type          *models.AccountType `json:"type"`
+	Accounts      int                 `json:"accounts"`
+	Revisions     int                 `json:"revisions"`
+	TotalAmount   float64             `json:"total_amount"`
+	AverageAmount float64             `json:"average_amount"`
+	LastChange    time.Time           `json:"last_change"`
+}
+
+// SearchAccountInfos
+//
+// @Summary      Search account infos
+// @Description  Full text search over name, iban and address with optional filters, served from the SQL read model.
+// @Description  The read model trails the vault by the projection interval.
+// @ID           search-account-infos
+// @Produce      json
+// @Produce      text/csv
+// @Produce      application/msgpack
+// @Param        q          query  string  false  "Full text query"
+// @Param        type       query  int     false  "Account type"  Enums(1, 2)
+// @Param        minAmount  query  number  false  "Minimum amount"
+// @Param        maxAmount  query  number  false  "Maximum amount"
+// @Param        page       query  int     false  "Page number"  default(1)
+// @Param        pageSize   query  int     false  "Page size"    default(10)
+// @Success      200  {object}  Response[[]AccountInfoDto] "Matching accounts"
+// @Failure      400  {object}  Response[string]  "Bad request, invalid filters"
+// @Failure      406  {object}  Response[string]  "None of the accepted types is supported"
+// @Failure      500  {object}  Response[string]  "Internal server error"
+// @Failure      501  {object}  Response[string]  "The read model is not configured"
+// @Router       /account-info/search [get]
+func (h *Handler) SearchAccountInfos(c *gin.Context) {
+	search, err := getAccountSearch(c)
+	if err != nil {
+		AbortWithMessage(c, http.StatusBadRequest, err, "invalid search")
+		return
+	}
+	result, err := h.Service.SearchAccountInfos(c.Request.Context(), *search)
+	if err != nil {
+		AbortWithMessage(c, http.StatusInternalServerError, err, "failed to search accountInfos")
+		return
+	}
+	output := make([]*AccountInfoDto, 0, len(result))
+	for _, item := range result {
+		output = append(output, convertAccountInfoToDTO(item))
+	}
+	returnOk(c, http.StatusOK, output)
+}
+
+// GetAccountStats
+//
+// @Summary      Account statistics
+// @Description  Number of accounts, revisions and amounts per account type, served from the SQL read model
+// @ID           get-account-stats
+// @Produce      json
+// @Produce      text/csv
+// @Produce      application/msgpack
+// @Success      200  {object}  Response[[]AccountStatsDto] "Statistics per account type"
+// @Failure      406  {object}  Response[string]  "None of the accepted types is supported"
+// @Failure      500  {object}  Response[string]  "Internal server error"
+// @Failure      501  {object}  Response[string]  "The read model is not configured"
+// @Router       /account-info/stats [get]
+func (h *Handler) GetAccountStats(c *gin.Context) {
+	result, err := h.Service.GetAccountStats(c.Request.Context())
+	if err != nil {
+		AbortWithMessage(c, http.StatusInternalServerError, err, "failed to load account stats")
+		return
+	}
+	output := make([]AccountStatsDto, 0, len(result))
+	for _, item := range result {
+		output = append(output, AccountStatsDto(item))
+	}
+	returnOk(c, http.StatusOK, output)
+}
+
+func getAccountSearch(c *gin.Context) (*models.AccountSearch, error) {
+	search := &models.AccountSearch{Text: c.Query("q"), Page: DefaultPage, PageSize: DefaultSearchPageSize}
+	var invalidParams []errors2.InvalidParam
+	if value := c.Query("type"); value != "" {
+		accountType, err := strconv.Atoi(value)
+		if err != nil {
+			invalidParams = append(invalidParams, errors2.InvalidParam{Name: "type", Reason: "type must be a number"})
+		}
+		search.Type = (*models.AccountType)(&accountType)
+	}
+	amounts := []struct {
+		name   string
+		target **float64
+	}{{"minAmount", &search.MinAmount}, {"maxAmount", &search.MaxAmount}}
+	for _, amount := range amounts {
+		name, target := amount.name, amount.target
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			invalidParams = append(invalidParams, errors2.InvalidParam{Name: name, Reason: name + " must be a number"})
+		}
+		*target = &parsed
+	}
+	paging := []struct {
+		name   string
+		target *int
+	}{{"page", &search.Page}, {"pageSize", &search.PageSize}}
+	for _, param := range paging {
+		name, target := param.name, param.target
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			invalidParams = append(invalidParams, errors2.InvalidParam{Name: name, Reason: name + " must be a number"})
+		}
+		*target = number
+	}
+	if len(invalidParams) > 0 {
+		return nil, errors2.NewValidationError(http.StatusBadRequest, invalidParams...)
+	}
+	return search, nil
+}
@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"immudb/internal/models"
+	"net/http"
+	"time"
+)
+
+// Webhooks is implemented by the webhook manager
+type Webhooks interface {
+	Create(webhook models.Webhook) (models.Webhook, error)
+	List() []models.Webhook
+	Get(id string) (models.Webhook, error)
+	Update(id string, webhook models.Webhook) (models.Webhook, error)
+	Delete(id string) error
+	DeadLetters(id string) ([]models.WebhookDelivery, error)
+	Redeliver(id, deliveryId string) (models.WebhookDelivery, error)
+}
+
+type WebhookInputDto struct {
+	Url    string   `json:"url"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`           // true when not given
+	Secret string   `json:"secret,omitempty"` // generated on create when not given, kept on update
+}
+
+type WebhookDto struct {
+	Id        string    `json:"id"`
+	Url       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	Secret    string    `json:"secret,omitempty"` // only returned when it is set
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type WebhookDeliveryDto struct {
+	Id             string     `json:"id"`
+	WebhookId      string     `json:"webhook_id"`
+	EventId        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Attempts       int        `json:"attempts"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+}
+
+// CreateWebhook
+//
+// @Summary      Subscribe to account events
+// @Description  Every event of the subscribed types is posted to the url, signed in X-Webhook-Signature as sha256=HMAC-SHA256(secret, timestamp + "." + body)
+// @Description  with the timestamp of X-Webhook-Timestamp. The secret is only returned here, a failed delivery is retried with backoff.
+// @Description  An url on a loopback, private or link-local address is refused unless its host is allowed in WebhookAllowedHosts.
+// @ID           create-webhook
+// @Accept       json
+// @Produce      json
+// @Param        webhook  body      WebhookInputDto  true  "Url, events (account.created, account.updated) and an optional secret"
+// @Success      201      {object}  Response[WebhookDto] "Created webhook"
+// @Failure      400      {object}  Response[string]  "Bad request, invalid url or events"
+// @Failure      501      {object}  Response[string]  "Webhooks are not configured"
+// @Router       /webhooks [post]
+func (h *Handler) CreateWebhook(c *gin.Context) {
+	if !h.webhooksConfigured(c) {
+		return
+	}
+	input, err := bindToWebhook(c)
+	if err != nil {
+		AbortWithMessage(c, http.StatusBadRequest, err, "error binding to json")
+		return
+	}
+	webhook, err := h.Webhooks.Create(input)
+	if err != nil {
+		AbortWithMessage(c, http.StatusInternalServerError, err, "failed to create the webhook")
+		return
+	}
+	c.Header("Location", fmt.Sprintf("/v1/api/webhooks/%s", webhook.Id))
+	returnOk(c, http.StatusCreated, convertWebhookToDTO(webhook, true))
+}
+
+// GetWebhooks
+//
+// @Summary      List webhooks
+// @ID           get-webhooks
+// @Produce      json
+// @Success      200  {object}  Response[[]WebhookDto] "Webhooks, oldest first"
+// @Failure      501  {object}  Response[string]  "Webhooks are not configured"
+// @Router       /webhooks [get]
+func (h *Handler) GetWebhooks(c *gin.Context) {
+	if !h.webhooksConfigured(c) {
+		return
+	}
+	output := []WebhookDto{}
+	for _, webhook := range h.Webhooks.List() {
+		output = append(output, convertWebhookToDTO(webhook, false))
+	}
+	returnOk(c, http.StatusOK, output)
+}
+
+// GetWebhook
+//
+// @Summary      Get a webhook
+// @ID           get-webhook
+// @Produce      json
+// @Param        id   path      string  true  "Webhook id"
+// @Success      200  {object}  Response[WebhookDto] "Webhook"
+// @Failure      404  {object}  Response[string]  "Webhook not found"
+// @Router       /webhooks/{id} [get]
+func (h *Handler) GetWebhook(c *gin.Context) {
+	if !h.webhooksConfigured(c) {
+		return
+	}
+	webhook, err := h.Webhooks.Get(c.Param("id"))
+	if err != nil {
+		AbortWithMessage(c, http.StatusInternalServerError, err, "failed to load the webhook")
+		return
+	}
+	returnOk(c, http.StatusOK, convertWebhookToDTO(webhook, false))
+}
+
+// UpdateWebhook
+//
+// @Summary      Update a webhook
+// @Description  Replaces url, events and active, the secret is only replaced when one is given
+// @ID           update-webhook
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string           true  "Webhook id"
+// @Param        webhook  body      WebhookInputDto  true  "Url, events, active and an optional new secret"
+// @Success      200      {object}  Response[WebhookDto] "Updated webhook"
+// @Failure      400      {object}  Response[string]  "Bad request, invalid url or events"
+// @Failure      404      {object}  Response[string]  "Webhook not found"
+// @Router       /webhooks/{id} [put]
+func (h *Handler) UpdateWebhook(c *gin.Context) {
+	if !h.webhooksConfigured(c) {
+		return
+	}
+	input, err := bindToWebhook(c)
+	if err != nil {
+		AbortWithMessage(c, http.StatusBadRequest, err, "error binding to json")
+		return
+	}
+	webhook, err := h.Webhooks.Update(c.Param("id"), input)
+	if err != nil {
+		AbortWithMessage(c, http.StatusInternalServerError, err, "failed to update the webhook")
+		return
+	}
+	returnOk(c, http.StatusOK, convertWebhookToDTO(webhook, input.Secret != ""))
+}
+
+// DeleteWebhook
+//
+// @Summary      Delete a webhook
+// @Description  Deliveries under way and the dead letters of the webhook are dropped
+// @ID           delete-webhook
+// @Param        id   path      string  true  "Webhook id"
+// @Success      204  "Deleted"
+// @Failure      404  {object}  Response[string]  "Webhook not found"
+// @Router       /webhooks/{id} [delete]
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+	if !h.webhooksConfigured(c) {
+		return
+	}
+	err := h.Webhooks.Delete(c.Param("id"))
+	if err != nil {
+		AbortWithMessage(c, http.StatusInternalServerError, err, "failed to delete the webhook")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetWebhookDeadLetters
+//
+// @Summary      List the dead letters of a webhook
+// @Description  Deliveries that failed every attempt, oldest first
+// @ID           get-webhook-dead-letters
+// @Produce      json
+// @Param        id   path      string  true  "Webhook id"
+// @Success      200  {object}  Response[[]WebhookDeliveryDto] "Dead letters"
+// @Failure      404  {object}  Response[string]  "Webhook not found"
+// @Router       /webhooks/{id}/dead-letters [get]
+func (h *Handler) GetWebhookDeadLetters(c *gin.Context) {
+	if !h.webhooksConfigured(c) {
+		return
+	}
+	deliveries, err := h.Webhooks.DeadLetters(c.Param("id"))
+	if err != nil {
+		AbortWithMessage(c, http.StatusInternalServerError, err, "failed to load the dead letters")
+		return
+	}
+	output := make([]WebhookDeliveryDto, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		output = append(output, convertWebhookDeliveryToDTO(delivery))
+	}
+	returnOk(c, http.StatusOK, output)
+}
+
+// RedeliverWebhook
+//
+// @Summary      Redeliver a dead letter
+// @Description  Takes the delivery off the dead letters and sends it again with a fresh set of attempts, X-Webhook-Delivery stays the same
+// @ID           redeliver-webhook
+// @Produce      json
+// @Param        id          path      string  true  "Webhook id"
+// @Param        deliveryId  path      string  true  "Delivery id"
+// @Success      202  {object}  Response[WebhookDeliveryDto] "Delivery queued"
+// @Failure      404  {object}  Response[string]  "Webhook or dead letter not found"
+// @Failure      409  {object}  Response[string]  "The webhook is not active"
+// @Failure      503  {object}  Response[string]  "The delivery queue is full"
+// @Router       /webhooks/{id}/dead-letters/{deliveryId}/redeliver [post]
+func (h *Handler) RedeliverWebhook(c *gin.Context) {
+	if !h.webhooksConfigured(c) {
+		return
+	}
+	delivery, err := h.Webhooks.Redeliver(c.Param("id"), c.Param("deliveryId"))
+	if err != nil {
+		AbortWithMessage(c, http.StatusInternalServerError, err, "failed to redeliver")
+		return
+	}
+	returnOk(c, http.StatusAccepted, convertWebhookDeliveryToDTO(delivery))
+}
+
+func (h *Handler) webhooksConfigured(c *gin.Context) bool {
+	if h.Webhooks == nil {
+		AbortWithMessage(c, http.StatusNotImplemented, errors.New("webhooks are not configured"), "webhooks are not configured")
+		return false
+	}
+	return true
+}
+
+func bindToWebhook(c *gin.Context) (models.Webhook, error) {
+	var input WebhookInputDto
+	err := c.BindJSON(&input)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	webhook := models.Webhook{Url: input.Url, Secret: input.Secret, Active: input.Active == nil || *input.Active}
+	for _, event := range input.Events {
+		webhook.Events = append(webhook.Events, models.EventType(event))
+	}
+	return webhook, nil
+}
+
+// convertWebhookToDTO the secret is only shown right after it was set
+func convertWebhookToDTO(webhook models.Webhook, withSecret bool) WebhookDto {
+	output := WebhookDto{
+		Id:        webhook.Id,
+		Url:       webhook.Url,
+		Events:    []string{},
+		Active:    webhook.Active,
+		CreatedAt: webhook.CreatedAt,
+		UpdatedAt: webhook.UpdatedAt,
+	}
+	for _, event := range webhook.Events {
+		output.Events = append(output.Events, string(event))
+	}
+	if withSecret {
+		output.Secret = webhook.Secret
+	}
+	return output
+}
+
+func convertWebhookDeliveryToDTO(delivery models.WebhookDelivery) WebhookDeliveryDto {
+	return WebhookDeliveryDto{
+		Id:             delivery.Id,
+		WebhookId:      delivery.WebhookId,
+		EventId:        delivery.Event.Id,
+		EventType:      string(delivery.Event.Type),
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+		LastAttemptAt:  delivery.LastAttemptAt,
+	}
+}
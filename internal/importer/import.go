@@ -0,0 +1,223 @@
+// Package importer reads accounts from CSV files and creates them through the service, row by row
+// so a file of any size is imported with constant memory.
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	cerror "immudb/internal/errors"
+	"immudb/internal/models"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const defaultBatchSize = 100
+
+// Target is where imported accounts go, the account service implements it
+type Target interface {
+	CreateAccountInfos(ctx context.Context, data []*models.AccountInfo, atomic bool) ([]models.BatchItem, error)
+	ValidateAccountInfo(data *models.AccountInfo) error
+}
+
+type Options struct {
+	Mapping   Mapping
+	DryRun    bool // only validate, nothing is written
+	BatchSize int  // rows per batch create, 100 when 0
+}
+
+// row is a parsed line waiting for its batch
+type row struct {
+	line    int
+	account *models.AccountInfo
+}
+
+// Run imports the CSV from input, progress goes to job and every rejected row to the report as line,field,reason.
+// Invalid rows never stop the import, it only fails when the file can't be read or written to the service at all.
+func Run(ctx context.Context, input io.Reader, target Target, options Options, job *Job, report io.Writer) error {
+	if options.Mapping == nil {
+		options.Mapping = DefaultMapping()
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultBatchSize
+	}
+	counter := &countingReader{reader: input}
+	reader := csv.NewReader(counter)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reports := csv.NewWriter(report)
+	defer reports.Flush()
+	err := reports.Write([]string{"line", "field", "reason"})
+	if err != nil {
+		return err
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read the header: %w", err)
+	}
+	columns, err := options.Mapping.columns(header)
+	if err != nil {
+		return err
+	}
+
+	pending := make([]row, 0, options.BatchSize)
+	flush := func() error {
+		err := importRows(ctx, target, options.DryRun, pending, job, reports)
+		pending = pending[:0]
+		job.progress(counter.read)
+		reports.Flush()
+		if err != nil {
+			return err
+		}
+		return reports.Error()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseError *csv.ParseError
+		if errors.As(err, &parseError) {
+			job.rejected()
+			err = reports.Write([]string{strconv.Itoa(parseError.StartLine), "", parseError.Err.Error()})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		line, _ := reader.FieldPos(0)
+		account, problems := parseRow(record, columns)
+		if len(problems) > 0 {
+			job.rejected()
+			for _, problem := range problems {
+				err = reports.Write([]string{strconv.Itoa(line), problem.Name, problem.Reason})
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		pending = append(pending, row{line: line, account: account})
+		if len(pending) == options.BatchSize {
+			err = flush()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// importRows writes, or on a dry run only validates, one batch and reports the rows the service rejected
+func importRows(ctx context.Context, target Target, dryRun bool, rows []row, job *Job, reports *csv.Writer) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	results := make([]error, len(rows))
+	if dryRun {
+		for i, pending := range rows {
+			results[i] = target.ValidateAccountInfo(pending.account)
+		}
+	} else {
+		accounts := make([]*models.AccountInfo, 0, len(rows))
+		for _, pending := range rows {
+			accounts = append(accounts, pending.account)
+		}
+		items, err := target.CreateAccountInfos(ctx, accounts, false)
+		if err != nil {
+			return err
+		}
+		for i, item := range items {
+			results[i] = item.Err
+		}
+	}
+	for i, err := range results {
+		if err == nil {
+			job.imported()
+			continue
+		}
+		job.rejected()
+		var serviceError *cerror.ServiceError
+		if !errors.As(err, &serviceError) {
+			logrus.WithError(err).WithField("line", rows[i].line).Error("failed to import a row")
+			err = reports.Write([]string{strconv.Itoa(rows[i].line), "", "failed to create accountInfo"})
+		} else if len(serviceError.InvalidParams) == 0 {
+			err = reports.Write([]string{strconv.Itoa(rows[i].line), "", serviceError.Error()})
+		} else {
+			for _, param := range serviceError.InvalidParams {
+				err = reports.Write([]string{strconv.Itoa(rows[i].line), param.Name, param.Reason})
+				if err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseRow converts the mapped columns, what the service validates is left to the service
+func parseRow(record []string, columns map[string]int) (*models.AccountInfo, []cerror.InvalidParam) {
+	value := func(field string) string {
+		index, ok := columns[field]
+		if !ok || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+	account := &models.AccountInfo{Name: value(FieldName), Iban: value(FieldIban)}
+	var problems []cerror.InvalidParam
+	if address := value(FieldAddress); address != "" {
+		account.Address = &address
+	}
+	if amount := value(FieldAmount); amount != "" {
+		parsed, err := strconv.ParseFloat(amount, 64)
+		if err != nil {
+			problems = append(problems, cerror.InvalidParam{Name: FieldAmount, Reason: "amount must be a number"})
+		}
+		account.Amount = parsed
+	}
+	if accountType := value(FieldType); accountType != "" {
+		parsed, ok := parseAccountType(accountType)
+		if !ok {
+			problems = append(problems, cerror.InvalidParam{Name: FieldType, Reason: "type must be 1, 2, sending or receiving"})
+		}
+		account.Type = &parsed
+	}
+	return account, problems
+}
+
+func parseAccountType(value string) (models.AccountType, bool) {
+	switch strings.ToLower(value) {
+	case "1", "sending":
+		return models.Sending, true
+	case "2", "receiving":
+		return models.Receiving, true
+	}
+	return 0, false
+}
+
+type countingReader struct {
+	reader io.Reader
+	read   int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.read += int64(n)
+	return n, err
+}
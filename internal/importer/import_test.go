@@ -0,0 +1,180 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"immudb/internal/services"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMapping(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		expected      Mapping
+		expectedError string
+	}{
+		{
+			name:     "Test_Default",
+			value:    "",
+			expected: DefaultMapping(),
+		},
+		{
+			name:  "Test_Override",
+			value: "account_name = Name, iban=IBAN",
+			expected: Mapping{
+				FieldName: "Name", FieldIban: "IBAN", FieldAddress: FieldAddress, FieldAmount: FieldAmount, FieldType: FieldType,
+			},
+		},
+		{
+			name:          "Test_Unknown_Field",
+			value:         "owner=Name",
+			expectedError: `unknown field "owner" in the column mapping, fields are account_name, iban, address, amount, type`,
+		},
+		{
+			name:          "Test_No_Column",
+			value:         "iban",
+			expectedError: `invalid column mapping "iban", use field=column`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// action
+			mapping, err := ParseMapping(tt.value)
+
+			// assertions
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, mapping)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name             string
+		input            string
+		mapping          string
+		dryRun           bool
+		expectedError    string
+		expectedJob      models.ImportJob
+		expectedReport   string
+		expectedAccounts int
+	}{
+		{
+			name:             "Test_Import",
+			input:            "account_name,iban,address,amount,type\nfirst,AL47,Tirana,10.5,sending\nsecond,AL48,,,2\n",
+			expectedJob:      models.ImportJob{Status: models.ImportSucceeded, Rows: 2, Imported: 2},
+			expectedReport:   "line,field,reason\n",
+			expectedAccounts: 2,
+		},
+		{
+			name:  "Test_Invalid_Rows_Are_Reported",
+			input: "\ufeffaccount_name,iban,amount,type\nfirst,AL47,ten,1\n,,,1\n\"broken,AL49,1,1\nlast,AL50,1,receiving\n",
+			expectedJob: models.ImportJob{
+				Status: models.ImportSucceeded, Rows: 3, Imported: 0, Failed: 3,
+			},
+			expectedReport: "line,field,reason\n" +
+				"2,amount,amount must be a number\n" +
+				"3,iban,invalid iban for the account\n" +
+				"3,account_name,invalid accountName for the account\n" +
+				"4,,\"extraneous or missing \"\" in quoted-field\"\n",
+		},
+		{
+			name:             "Test_Mapping",
+			input:            "Name;IBAN\n",
+			mapping:          "account_name=Name,iban=IBAN",
+			expectedError:    "the header has no column IBAN (for iban), Name (for account_name)",
+			expectedJob:      models.ImportJob{Status: models.ImportFailed},
+			expectedReport:   "line,field,reason\n",
+			expectedAccounts: 0,
+		},
+		{
+			name:             "Test_Dry_Run",
+			input:            "Name,IBAN,type\nfirst,AL47,1\nsecond,AL48,\n",
+			mapping:          "account_name=Name,iban=IBAN",
+			dryRun:           true,
+			expectedJob:      models.ImportJob{Status: models.ImportSucceeded, DryRun: true, Rows: 2, Imported: 1, Failed: 1},
+			expectedReport:   "line,field,reason\n3,type,invalid type for the account\n",
+			expectedAccounts: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			db := persistance.NewMemoryDB()
+			service, err := services.NewService(db)
+			assert.NoError(t, err)
+			mapping, err := ParseMapping(tt.mapping)
+			assert.NoError(t, err)
+			job := NewJob(tt.dryRun, int64(len(tt.input)))
+			report := &bytes.Buffer{}
+
+			// action
+			err = Run(context.Background(), strings.NewReader(tt.input), service, Options{Mapping: mapping, DryRun: tt.dryRun, BatchSize: 1}, job, report)
+			job.Finish(err)
+
+			// assertions
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			state := job.Snapshot()
+			assert.Equal(t, tt.expectedJob.Status, state.Status)
+			assert.Equal(t, tt.expectedJob.DryRun, state.DryRun)
+			assert.Equal(t, tt.expectedJob.Rows, state.Rows)
+			assert.Equal(t, tt.expectedJob.Imported, state.Imported)
+			assert.Equal(t, tt.expectedJob.Failed, state.Failed)
+			assert.Equal(t, tt.expectedReport, report.String())
+			accounts, err := db.GetAllAccountInfos(context.Background(), 1, 100)
+			assert.NoError(t, err)
+			assert.Len(t, accounts, tt.expectedAccounts)
+		})
+	}
+}
+
+func TestManager(t *testing.T) {
+	// setup
+	dir := t.TempDir()
+	service, err := services.NewService(persistance.NewMemoryDB())
+	assert.NoError(t, err)
+	manager, err := NewManager(service, dir)
+	assert.NoError(t, err)
+	input := "account_name,iban,type\nfirst,AL47,1\n,AL48,1\n"
+
+	// action
+	job, err := manager.Start(strings.NewReader(input), int64(len(input)), Options{})
+	assert.NoError(t, err)
+	assert.Eventually(t, job.finished, time.Second, 10*time.Millisecond)
+
+	// assertions
+	found, err := manager.Get(job.Snapshot().Id)
+	assert.NoError(t, err)
+	state := found.Snapshot()
+	assert.Equal(t, models.ImportSucceeded, state.Status)
+	assert.Equal(t, 1, state.Imported)
+	assert.Equal(t, 1, state.Failed)
+	assert.Equal(t, int64(len(input)), state.BytesRead)
+	report, err := manager.OpenReport(state.Id)
+	assert.NoError(t, err)
+	defer report.Close()
+	content := &bytes.Buffer{}
+	_, err = content.ReadFrom(report)
+	assert.NoError(t, err)
+	assert.Equal(t, "line,field,reason\n3,account_name,invalid accountName for the account\n", content.String())
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1, "the upload is removed once it is imported")
+	_, err = manager.Get("unknown")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
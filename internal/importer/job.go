@@ -0,0 +1,193 @@
+package importer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"immudb/internal/models"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// maxJobs finished jobs beyond this are forgotten together with their error report, oldest first
+const maxJobs = 100
+
+// ErrJobNotFound is returned for unknown or forgotten jobs
+var ErrJobNotFound = errors.New("import job not found")
+
+// Job is the progress of one import, it is safe to read while the import runs
+type Job struct {
+	mx         sync.Mutex
+	state      models.ImportJob
+	reportPath string
+}
+
+func NewJob(dryRun bool, totalBytes int64) *Job {
+	return &Job{state: models.ImportJob{
+		Id:         newJobId(),
+		Status:     models.ImportRunning,
+		DryRun:     dryRun,
+		TotalBytes: totalBytes,
+		StartedAt:  time.Now().UTC(),
+	}}
+}
+
+func (j *Job) Snapshot() models.ImportJob {
+	j.mx.Lock()
+	defer j.mx.Unlock()
+	return j.state
+}
+
+func (j *Job) imported() {
+	j.mx.Lock()
+	defer j.mx.Unlock()
+	j.state.Rows++
+	j.state.Imported++
+}
+
+func (j *Job) rejected() {
+	j.mx.Lock()
+	defer j.mx.Unlock()
+	j.state.Rows++
+	j.state.Failed++
+}
+
+func (j *Job) progress(bytesRead int64) {
+	j.mx.Lock()
+	defer j.mx.Unlock()
+	j.state.BytesRead = bytesRead
+}
+
+// Finish records how the import ended, err nil means it read the whole file
+func (j *Job) Finish(err error) {
+	j.mx.Lock()
+	defer j.mx.Unlock()
+	now := time.Now().UTC()
+	j.state.FinishedAt = &now
+	j.state.Status = models.ImportSucceeded
+	if err != nil {
+		j.state.Status = models.ImportFailed
+		j.state.Error = err.Error()
+	}
+}
+
+func (j *Job) finished() bool {
+	j.mx.Lock()
+	defer j.mx.Unlock()
+	return j.state.Status != models.ImportRunning
+}
+
+// Manager runs uploaded imports in the background, the upload and the error report are kept as files in dir
+type Manager struct {
+	mx     sync.Mutex
+	target Target
+	dir    string
+	jobs   map[string]*Job
+	order  []string // job ids, oldest first
+}
+
+func NewManager(target Target, dir string) (*Manager, error) {
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{target: target, dir: dir, jobs: map[string]*Job{}}, nil
+}
+
+// Start copies the upload to disk, so the request can end, and imports it in the background.
+// size is the length of the upload for the progress, 0 if it is not known.
+func (m *Manager) Start(upload io.Reader, size int64, options Options) (*Job, error) {
+	job := NewJob(options.DryRun, size)
+	job.reportPath = filepath.Join(m.dir, job.state.Id+"-errors.csv")
+	uploadPath := filepath.Join(m.dir, job.state.Id+".csv")
+	err := copyToFile(uploadPath, upload)
+	if err != nil {
+		_ = os.Remove(uploadPath)
+		return nil, err
+	}
+	m.add(job)
+	go func() {
+		defer os.Remove(uploadPath)
+		err := m.run(job, uploadPath, options)
+		job.Finish(err)
+		state := job.Snapshot()
+		logrus.WithFields(logrus.Fields{"job": state.Id, "status": state.Status, "imported": state.Imported, "failed": state.Failed}).Info("import finished")
+	}()
+	return job, nil
+}
+
+func (m *Manager) run(job *Job, uploadPath string, options Options) error {
+	input, err := os.Open(uploadPath)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	report, err := os.Create(job.reportPath)
+	if err != nil {
+		return err
+	}
+	defer report.Close()
+	// the request that started the job is gone by now
+	return Run(context.Background(), input, m.target, options, job, report)
+}
+
+func (m *Manager) Get(id string) (*Job, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// OpenReport opens the error report of the job, while the job runs it has the rows rejected so far
+func (m *Manager) OpenReport(id string) (io.ReadCloser, error) {
+	job, err := m.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(job.reportPath)
+}
+
+func (m *Manager) add(job *Job) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	m.jobs[job.state.Id] = job
+	m.order = append(m.order, job.state.Id)
+	for i := 0; len(m.jobs) > maxJobs && i < len(m.order); {
+		oldest := m.jobs[m.order[i]]
+		if !oldest.finished() {
+			i++
+			continue
+		}
+		_ = os.Remove(oldest.reportPath)
+		delete(m.jobs, m.order[i])
+		m.order = append(m.order[:i], m.order[i+1:]...)
+	}
+}
+
+func copyToFile(path string, input io.Reader) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, input)
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to store the upload: %w", err)
+	}
+	return file.Close()
+}
+
+func newJobId() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
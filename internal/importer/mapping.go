@@ -0,0 +1,80 @@
+package importer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// the fields of an account a column can be mapped to, named like the json fields of the api
+const (
+	FieldName    = "account_name"
+	FieldIban    = "iban"
+	FieldAddress = "address"
+	FieldAmount  = "amount"
+	FieldType    = "type"
+)
+
+var fields = []string{FieldName, FieldIban, FieldAddress, FieldAmount, FieldType}
+
+// Mapping maps an account field to the header of the column it is read from
+type Mapping map[string]string
+
+// DefaultMapping reads every field from the column with the same name
+func DefaultMapping() Mapping {
+	mapping := Mapping{}
+	for _, field := range fields {
+		mapping[field] = field
+	}
+	return mapping
+}
+
+// ParseMapping reads field=column pairs separated by commas, like "account_name=Name,iban=IBAN",
+// fields that are not listed keep their default column
+func ParseMapping(value string) (Mapping, error) {
+	mapping := DefaultMapping()
+	if strings.TrimSpace(value) == "" {
+		return mapping, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		field, column, ok := strings.Cut(pair, "=")
+		field, column = strings.TrimSpace(field), strings.TrimSpace(column)
+		if !ok || column == "" {
+			return nil, fmt.Errorf("invalid column mapping %q, use field=column", pair)
+		}
+		if _, known := mapping[field]; !known {
+			return nil, fmt.Errorf("unknown field %q in the column mapping, fields are %s", field, strings.Join(fields, ", "))
+		}
+		mapping[field] = column
+	}
+	return mapping, nil
+}
+
+// columns finds the index of every mapped field in the header, address, amount and type may be missing
+func (m Mapping) columns(header []string) (map[string]int, error) {
+	byName := make(map[string]int, len(header))
+	for i, column := range header {
+		name := strings.ToLower(strings.TrimSpace(column))
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff") // byte order mark of spreadsheet exports
+		}
+		byName[name] = i
+	}
+	result := map[string]int{}
+	var missing []string
+	for field, column := range m {
+		index, ok := byName[strings.ToLower(column)]
+		if ok {
+			result[field] = index
+			continue
+		}
+		if field == FieldName || field == FieldIban {
+			missing = append(missing, fmt.Sprintf("%s (for %s)", column, field))
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("the header has no column %s", strings.Join(missing, ", "))
+	}
+	return result, nil
+}
@@ -0,0 +1,216 @@
+// Package merkle implements the RFC 6962 merkle tree hashing used by ledger proofs.
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/bits"
+)
+
+var (
+	ErrInvalidProof = errors.New("invalid merkle proof")
+	ErrOutOfRange   = errors.New("index out of range")
+)
+
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// LeafHash hashes the raw entry data as a tree leaf
+func LeafHash(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// NodeHash hashes two child hashes into their parent
+func NodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// EmptyRoot is the root hash of a tree without leaves
+func EmptyRoot() []byte {
+	sum := sha256.Sum256(nil)
+	return sum[:]
+}
+
+// Tree keeps every leaf hash in memory, it is meant for ledgers small enough to rebuild on startup
+type Tree struct {
+	leaves [][]byte
+}
+
+func NewTree() *Tree {
+	return &Tree{}
+}
+
+// Append adds a leaf hash and returns its index
+func (t *Tree) Append(leafHash []byte) uint64 {
+	t.leaves = append(t.leaves, leafHash)
+	return uint64(len(t.leaves) - 1)
+}
+
+func (t *Tree) Size() uint64 {
+	return uint64(len(t.leaves))
+}
+
+func (t *Tree) Root() []byte {
+	return rootHash(t.leaves)
+}
+
+// RootAt returns the root the tree had when it contained size leaves
+func (t *Tree) RootAt(size uint64) ([]byte, error) {
+	if size > t.Size() {
+		return nil, ErrOutOfRange
+	}
+	return rootHash(t.leaves[:size]), nil
+}
+
+// InclusionProof returns the audit path of the leaf at index in the tree of the given size
+func (t *Tree) InclusionProof(index, size uint64) ([][]byte, error) {
+	if size > t.Size() || index >= size {
+		return nil, ErrOutOfRange
+	}
+	return inclusionPath(index, t.leaves[:size]), nil
+}
+
+// ConsistencyProof proves that the tree of size from is a prefix of the tree of size to
+func (t *Tree) ConsistencyProof(from, to uint64) ([][]byte, error) {
+	if to > t.Size() || from > to {
+		return nil, ErrOutOfRange
+	}
+	if from == 0 || from == to {
+		return [][]byte{}, nil
+	}
+	return subProof(from, t.leaves[:to], true), nil
+}
+
+// VerifyInclusion checks the audit path of a leaf against the root of a tree of the given size (RFC 9162 2.1.3.2)
+func VerifyInclusion(index, size uint64, leafHash []byte, proof [][]byte, root []byte) error {
+	if index >= size {
+		return ErrInvalidProof
+	}
+	fn, sn := index, size-1
+	r := leafHash
+	for _, p := range proof {
+		if sn == 0 {
+			return ErrInvalidProof
+		}
+		if fn&1 == 1 || fn == sn {
+			r = NodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = NodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 || !bytes.Equal(r, root) {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// VerifyConsistency checks that the tree with firstRoot is a prefix of the tree with secondRoot (RFC 9162 2.1.4.2)
+func VerifyConsistency(firstSize, secondSize uint64, firstRoot, secondRoot []byte, proof [][]byte) error {
+	switch {
+	case firstSize > secondSize:
+		return ErrInvalidProof
+	case firstSize == secondSize:
+		if len(proof) != 0 || !bytes.Equal(firstRoot, secondRoot) {
+			return ErrInvalidProof
+		}
+		return nil
+	case firstSize == 0:
+		// the empty tree is a prefix of every tree
+		return nil
+	case len(proof) == 0:
+		return ErrInvalidProof
+	}
+
+	if bits.OnesCount64(firstSize) == 1 {
+		proof = append([][]byte{firstRoot}, proof...)
+	}
+	fn, sn := firstSize-1, secondSize-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return ErrInvalidProof
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = NodeHash(c, fr)
+			sr = NodeHash(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = NodeHash(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 || !bytes.Equal(fr, firstRoot) || !bytes.Equal(sr, secondRoot) {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// splitPoint returns the largest power of two smaller than n
+func splitPoint(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+func rootHash(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		return EmptyRoot()
+	case 1:
+		return leaves[0]
+	}
+	k := splitPoint(len(leaves))
+	return NodeHash(rootHash(leaves[:k]), rootHash(leaves[k:]))
+}
+
+func inclusionPath(index uint64, leaves [][]byte) [][]byte {
+	if len(leaves) <= 1 {
+		return [][]byte{}
+	}
+	k := splitPoint(len(leaves))
+	if index < uint64(k) {
+		return append(inclusionPath(index, leaves[:k]), rootHash(leaves[k:]))
+	}
+	return append(inclusionPath(index-uint64(k), leaves[k:]), rootHash(leaves[:k]))
+}
+
+func subProof(m uint64, leaves [][]byte, complete bool) [][]byte {
+	n := uint64(len(leaves))
+	if m == n {
+		if complete {
+			return [][]byte{}
+		}
+		return [][]byte{rootHash(leaves)}
+	}
+	k := uint64(splitPoint(len(leaves)))
+	if m <= k {
+		return append(subProof(m, leaves[:k], complete), rootHash(leaves[k:]))
+	}
+	return append(subProof(m-k, leaves[k:], false), rootHash(leaves[:k]))
+}
@@ -0,0 +1,87 @@
+package merkle
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func buildTree(size int) *Tree {
+	tree := NewTree()
+	for i := 0; i < size; i++ {
+		tree.Append(LeafHash([]byte(fmt.Sprintf("leaf-%d", i))))
+	}
+	return tree
+}
+
+func TestTree_Root(t *testing.T) {
+	a, b, c := LeafHash([]byte("a")), LeafHash([]byte("b")), LeafHash([]byte("c"))
+	tests := []struct {
+		name     string
+		leaves   [][]byte
+		expected []byte
+	}{
+		{name: "Test_Empty", expected: EmptyRoot()},
+		{name: "Test_Single_Leaf", leaves: [][]byte{a}, expected: a},
+		{name: "Test_Two_Leaves", leaves: [][]byte{a, b}, expected: NodeHash(a, b)},
+		{name: "Test_Unbalanced", leaves: [][]byte{a, b, c}, expected: NodeHash(NodeHash(a, b), c)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			tree := NewTree()
+			for _, leaf := range tt.leaves {
+				tree.Append(leaf)
+			}
+
+			// assert
+			assert.Equal(t, tt.expected, tree.Root())
+		})
+	}
+}
+
+func TestTree_InclusionProof(t *testing.T) {
+	tree := buildTree(33)
+	for size := uint64(1); size <= tree.Size(); size++ {
+		root, err := tree.RootAt(size)
+		assert.NoError(t, err)
+		for index := uint64(0); index < size; index++ {
+			proof, err := tree.InclusionProof(index, size)
+			assert.NoError(t, err)
+			leaf := tree.leaves[index]
+
+			assert.NoError(t, VerifyInclusion(index, size, leaf, proof, root), "size %d index %d", size, index)
+			assert.ErrorIs(t, VerifyInclusion(index, size, LeafHash([]byte("tampered")), proof, root), ErrInvalidProof)
+			if len(proof) > 0 {
+				assert.ErrorIs(t, VerifyInclusion(index, size, leaf, proof[1:], root), ErrInvalidProof)
+			}
+		}
+	}
+}
+
+func TestTree_ConsistencyProof(t *testing.T) {
+	tree := buildTree(33)
+	for second := uint64(1); second <= tree.Size(); second++ {
+		secondRoot, _ := tree.RootAt(second)
+		for first := uint64(0); first <= second; first++ {
+			firstRoot, _ := tree.RootAt(first)
+			proof, err := tree.ConsistencyProof(first, second)
+			assert.NoError(t, err)
+
+			assert.NoError(t, VerifyConsistency(first, second, firstRoot, secondRoot, proof), "first %d second %d", first, second)
+			if first > 0 && first < second {
+				assert.ErrorIs(t, VerifyConsistency(first, second, LeafHash([]byte("forked")), secondRoot, proof), ErrInvalidProof)
+				assert.ErrorIs(t, VerifyConsistency(first, second, firstRoot, LeafHash([]byte("forked")), proof), ErrInvalidProof)
+			}
+		}
+	}
+}
+
+func TestVerifyConsistency_Backwards(t *testing.T) {
+	tree := buildTree(4)
+	first, _ := tree.RootAt(4)
+	second, _ := tree.RootAt(2)
+
+	assert.ErrorIs(t, VerifyConsistency(4, 2, first, second, nil), ErrInvalidProof)
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type AccountType int
 
 const (
@@ -8,10 +10,20 @@ const (
 )
 
 type AccountInfo struct {
-	Id      uint   `json:"id"` // this name is used so we can have a index on this
-	Name    string `json:"name"`
-	Iban    string
-	Address *string // this is used for simplicity normally it needs to be a little bit more complex
-	Amount  float64 // Maybe It would be better if int64
-	Type    *AccountType
+	Id         uint   `json:"id"`            // this name is used so we can have a index on this
+	DocumentId string `json:"_id,omitempty"` // assigned by the vault, empty until the account is stored
+	Name       string `json:"name"`
+	Iban       string
+	Address    *string // this is used for simplicity normally it needs to be a little bit more complex
+	Amount     float64 // Maybe It would be better if int64
+	Type       *AccountType
+	Meta       *DocumentMeta `json:"-"` // never stored, filled from the vault when the account is loaded
+}
+
+// DocumentMeta is the ledger metadata of the revision an account was loaded from or written as
+type DocumentMeta struct {
+	Revision      uint64
+	TransactionId uint64
+	Timestamp     time.Time
+	Creator       string
 }
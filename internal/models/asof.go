@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// AsOf selects a point in the past, either by time or by ledger transaction
+type AsOf struct {
+	Timestamp     *time.Time
+	TransactionId uint64
+}
+
+// Includes reports whether the revision was already committed at this point
+func (a AsOf) Includes(meta *DocumentMeta) bool {
+	if meta == nil {
+		return false
+	}
+	if a.Timestamp != nil {
+		return !meta.Timestamp.IsZero() && !meta.Timestamp.After(*a.Timestamp)
+	}
+	return meta.TransactionId != 0 && meta.TransactionId <= a.TransactionId
+}
+
+// Select returns the revision that was current at this point, revisions must be ordered oldest first.
+// Nil means the account did not exist yet.
+func (a AsOf) Select(revisions []*AccountInfo) *AccountInfo {
+	var current *AccountInfo
+	for _, revision := range revisions {
+		if !a.Includes(revision.Meta) {
+			break
+		}
+		current = revision
+	}
+	return current
+}
@@ -0,0 +1,7 @@
+package models
+
+// BatchItem is the outcome of one account of a batch create, in the order of the request
+type BatchItem struct {
+	Account *AccountInfo // the created account with its receipt, nil if it was not created
+	Err     error
+}
@@ -0,0 +1,18 @@
+package models
+
+// FieldChange is a single field that differs between two revisions of an account
+type FieldChange struct {
+	Field         string
+	OldValue      interface{}
+	NewValue      interface{}
+	ChangedBy     string // creator of the revision that last changed the field
+	TransactionId uint64
+}
+
+type AccountDiff struct {
+	Id           uint
+	DocumentId   string
+	FromRevision uint64
+	ToRevision   uint64
+	Changes      []FieldChange
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+type EventType string
+
+// there is no account.deleted, the ledger never deletes an account
+const (
+	AccountCreated EventType = "account.created"
+	AccountUpdated EventType = "account.updated"
+)
+
+// EventTypes are every event type a listener can subscribe to
+var EventTypes = []EventType{AccountCreated, AccountUpdated}
+
+// AccountEvent is a committed change of an account
+type AccountEvent struct {
+	Id         string // the same revision always gets the same id, so receivers can drop duplicates
+	Type       EventType
+	OccurredAt time.Time
+	Account    AccountInfo
+}
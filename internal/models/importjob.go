@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+type ImportStatus string
+
+const (
+	ImportRunning   ImportStatus = "running"
+	ImportSucceeded ImportStatus = "succeeded"
+	ImportFailed    ImportStatus = "failed"
+)
+
+// ImportJob is the progress of a CSV import, on a dry run Imported counts the rows that would have been imported
+type ImportJob struct {
+	Id         string
+	Status     ImportStatus
+	DryRun     bool
+	Rows       int
+	Imported   int
+	Failed     int
+	BytesRead  int64
+	TotalBytes int64 // 0 when the size of the upload is not known
+	Error      string
+	StartedAt  time.Time
+	FinishedAt *time.Time
+}
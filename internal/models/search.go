@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// AccountSearch filters the accounts of the read model, unset filters match everything
+type AccountSearch struct {
+	Text      string // full text over name, iban and address
+	Type      *AccountType
+	MinAmount *float64
+	MaxAmount *float64
+	Page      int
+	PageSize  int
+}
+
+// AccountStats aggregates the accounts of one type
+type AccountStats struct {
+	Type          *AccountType
+	Accounts      int
+	Revisions     int
+	TotalAmount   float64
+	AverageAmount float64
+	LastChange    time.Time
+}
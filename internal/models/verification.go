@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Verification is the outcome of checking a document against the ledger proofs
+type Verification struct {
+	Verified            bool
+	DocumentId          string
+	TransactionId       uint64 // transaction that committed the verified revision
+	LedgerTransactionId uint64 // latest transaction of the ledger state the proof was checked against
+	RootHash            string
+	VerifiedAt          time.Time
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Webhook is a subscription of an url to account events
+type Webhook struct {
+	Id        string
+	Url       string
+	Events    []EventType
+	Secret    string // the key the payloads are signed with
+	Active    bool   // inactive webhooks keep their dead letters but get no new deliveries
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// WebhookDelivery is one event on its way to one webhook
+type WebhookDelivery struct {
+	Id             string // sent along so receivers can drop duplicates of a redelivery
+	WebhookId      string
+	Event          AccountEvent
+	Attempts       int
+	LastStatusCode int // 0 when the last attempt got no response
+	LastError      string
+	CreatedAt      time.Time
+	LastAttemptAt  *time.Time
+}
@@ -0,0 +1,299 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"immudb/internal/events"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	opAdd  = "add"
+	opAck  = "ack"
+	opSeen = "seen" // an id relayed before the last compaction, kept to drop duplicates
+	// compactAfter acknowledgements in the journal before it is rewritten with what is still needed
+	compactAfter = 1000
+	// rememberedIds relayed ids kept to drop an event that is added again
+	rememberedIds = 10000
+)
+
+// Record is an event waiting in the outbox, Id doubles as the deduplication id of the sinks
+type Record struct {
+	Id            string
+	Type          models.EventType
+	Key           string // the account number, sinks that partition keep the events of one account in order
+	TransactionId uint64
+	Payload       json.RawMessage // the event as webhooks and the event stream send it
+	AddedAt       time.Time
+}
+
+type entry struct {
+	Op     string  `json:"op"`
+	Record *Record `json:"record,omitempty"`
+	Id     string  `json:"id,omitempty"`
+}
+
+// Outbox is a journal of the events of committed writes. An event is appended and synced to disk after the ledger
+// committed the write and before it is answered, the two are not one transaction: the event of a write whose process
+// died in between is added by the change data capture, the outbox is never run without it. An event stays until
+// the relay acknowledges it, so a restart relays whatever was left.
+type Outbox struct {
+	mx         sync.Mutex
+	path       string
+	file       *os.File
+	pending    []*Record // oldest first
+	relayed    map[string]struct{}
+	relayOrder []string // oldest first
+	acks       int      // acknowledgements written since the last compaction
+	notify     chan struct{}
+}
+
+// Open reads the journal at path, creating it if needed
+func Open(path string) (*Outbox, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return nil, err
+	}
+	o := &Outbox{path: path, relayed: map[string]struct{}{}, notify: make(chan struct{}, 1)}
+	err = o.load()
+	if err != nil {
+		return nil, err
+	}
+	o.file, err = openJournal(path)
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func openJournal(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+}
+
+func (o *Outbox) load() error {
+	data, err := os.ReadFile(o.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// a crash while appending leaves the last line cut short, the write it belongs to was never answered
+	if complete := bytes.LastIndexByte(data, '\n') + 1; complete < len(data) {
+		logrus.WithField("bytes", len(data)-complete).Warn("dropped the torn end of the outbox journal")
+		err = os.Truncate(o.path, int64(complete))
+		if err != nil {
+			return err
+		}
+		data = data[:complete]
+	}
+	for i, line := range bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var e entry
+		err = json.Unmarshal(line, &e)
+		if err != nil {
+			return fmt.Errorf("corrupted outbox journal %s at line %d: %w", o.path, i+1, err)
+		}
+		switch e.Op {
+		case opAdd:
+			if e.Record != nil && !o.known(e.Record.Id) {
+				o.pending = append(o.pending, e.Record)
+			}
+		case opAck:
+			o.remove(e.Id)
+			o.acks++
+		case opSeen:
+			o.remember(e.Id)
+		}
+	}
+	return nil
+}
+
+// Close stops the journal, records still pending are relayed after the next Open
+func (o *Outbox) Close() error {
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	if o.file == nil {
+		return nil
+	}
+	err := o.file.Close()
+	o.file = nil
+	return err
+}
+
+// Publish adds the event of a committed write. It can't fail the write, which already happened,
+// an event that couldn't be added is added by the reconciliation from the change data capture.
+func (o *Outbox) Publish(event models.AccountEvent) {
+	err := o.Add(event)
+	if err != nil {
+		logrus.WithError(err).WithField("event", event.Id).Error("failed to add the event to the outbox")
+	}
+}
+
+// Handle reconciles the outbox with an event of the change data capture, adding it unless it is already known
+func (o *Outbox) Handle(ctx context.Context, event models.AccountEvent) error {
+	return o.Add(event)
+}
+
+// Add appends the event to the journal and syncs it, an event that is pending or was relayed recently is skipped
+func (o *Outbox) Add(event models.AccountEvent) error {
+	payload, err := json.Marshal(events.NewEnvelope(event))
+	if err != nil {
+		return err
+	}
+	record := &Record{
+		Id:            event.Id,
+		Type:          event.Type,
+		Key:           fmt.Sprint(event.Account.Id),
+		TransactionId: events.TransactionId(event),
+		Payload:       payload,
+		AddedAt:       time.Now().UTC(),
+	}
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	if o.known(record.Id) {
+		return nil
+	}
+	err = o.append(entry{Op: opAdd, Record: record})
+	if err != nil {
+		return err
+	}
+	o.pending = append(o.pending, record)
+	select {
+	case o.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Next is the oldest pending record, nil when the outbox is empty
+func (o *Outbox) Next() *Record {
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	if len(o.pending) == 0 {
+		return nil
+	}
+	return o.pending[0]
+}
+
+// Pending counts the records waiting to be relayed
+func (o *Outbox) Pending() int {
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	return len(o.pending)
+}
+
+// Ack removes a relayed record, once enough acknowledgements piled up the journal is compacted
+func (o *Outbox) Ack(id string) error {
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	err := o.append(entry{Op: opAck, Id: id})
+	if err != nil {
+		return err
+	}
+	o.remove(id)
+	o.acks++
+	if o.acks >= compactAfter && o.acks > len(o.pending) {
+		err = o.compact()
+		if err != nil {
+			// the journal is only longer than needed, compaction is tried again on the next acknowledgement
+			logrus.WithError(err).Warn("failed to compact the outbox journal")
+		}
+	}
+	return nil
+}
+
+// Notify is signalled when a record is added
+func (o *Outbox) Notify() <-chan struct{} {
+	return o.notify
+}
+
+// append must be called with the lock held
+func (o *Outbox) append(e entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if o.file == nil {
+		o.file, err = openJournal(o.path)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = o.file.Write(append(data, '\n'))
+	if err != nil {
+		return err
+	}
+	return o.file.Sync()
+}
+
+// compact rewrites the journal with the pending records and the remembered ids, must be called with the lock held
+func (o *Outbox) compact() error {
+	var data []byte
+	for _, id := range o.relayOrder {
+		line, err := json.Marshal(entry{Op: opSeen, Id: id})
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	for _, record := range o.pending {
+		line, err := json.Marshal(entry{Op: opAdd, Record: record})
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	err := persistance.WriteFileAtomic(o.path, data)
+	if err != nil {
+		return err
+	}
+	// the old file is gone, the next append opens the new one if this fails
+	_ = o.file.Close()
+	o.acks = 0
+	o.file, err = openJournal(o.path)
+	return err
+}
+
+// remove must be called with the lock held
+func (o *Outbox) remove(id string) {
+	o.pending = slices.DeleteFunc(o.pending, func(record *Record) bool {
+		return record.Id == id
+	})
+	o.remember(id)
+}
+
+// remember must be called with the lock held
+func (o *Outbox) remember(id string) {
+	if _, ok := o.relayed[id]; ok {
+		return
+	}
+	o.relayed[id] = struct{}{}
+	o.relayOrder = append(o.relayOrder, id)
+	if len(o.relayOrder) > rememberedIds {
+		delete(o.relayed, o.relayOrder[0])
+		o.relayOrder = o.relayOrder[1:]
+	}
+}
+
+// known must be called with the lock held
+func (o *Outbox) known(id string) bool {
+	if _, ok := o.relayed[id]; ok {
+		return true
+	}
+	return slices.ContainsFunc(o.pending, func(record *Record) bool {
+		return record.Id == id
+	})
+}
@@ -0,0 +1,124 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"immudb/internal/events"
+	"immudb/internal/models"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newEvent(id uint, revision uint64) models.AccountEvent {
+	return events.NewAccountEvent(models.AccountCreated, models.AccountInfo{Id: id, Name: "John", Meta: &models.DocumentMeta{Revision: revision, TransactionId: uint64(id)}})
+}
+
+func pendingIds(t *testing.T, box *Outbox) []string {
+	var ids []string
+	for _, record := range box.pending {
+		ids = append(ids, record.Id)
+	}
+	return ids
+}
+
+func TestOutbox_Restart(t *testing.T) {
+	// setup
+	path := filepath.Join(t.TempDir(), "outbox.log")
+	box, err := Open(path)
+	assert.NoError(t, err)
+	for _, event := range []models.AccountEvent{newEvent(1, 1), newEvent(2, 1), newEvent(1, 1), newEvent(3, 1)} {
+		assert.NoError(t, box.Add(event))
+	}
+	assert.NoError(t, box.Ack("1-1"))
+	assert.NoError(t, box.Close())
+	// a crash while appending leaves half a line behind
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	assert.NoError(t, err)
+	_, err = file.WriteString(`{"op":"add","record":{"Id":"4-`)
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	// action
+	reopened, err := Open(path)
+	assert.NoError(t, err)
+	assert.NoError(t, reopened.Add(newEvent(1, 1)))
+	assert.NoError(t, reopened.Add(newEvent(1, 2)))
+
+	// assertions
+	assert.Equal(t, []string{"2-1", "3-1", "1-2"}, pendingIds(t, reopened))
+	again, err := Open(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2-1", "3-1", "1-2"}, pendingIds(t, again), "appends after the torn line are kept")
+	assert.Equal(t, "2-1", reopened.Next().Id)
+	assert.Equal(t, "2", reopened.Next().Key)
+	assert.Contains(t, string(reopened.Next().Payload), `"transaction_id":2`)
+}
+
+func TestOutbox_Compaction(t *testing.T) {
+	// setup
+	path := filepath.Join(t.TempDir(), "outbox.log")
+	box, err := Open(path)
+	assert.NoError(t, err)
+	for i := 1; i <= compactAfter+1; i++ {
+		assert.NoError(t, box.Add(newEvent(uint(i), 1)))
+	}
+
+	// action
+	for i := 1; i <= compactAfter; i++ {
+		assert.NoError(t, box.Ack(fmt.Sprintf("%d-1", i)))
+	}
+
+	// assertions
+	assert.Equal(t, 0, box.acks)
+	reopened, err := Open(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{fmt.Sprintf("%d-1", compactAfter+1)}, pendingIds(t, reopened))
+	assert.NoError(t, reopened.Add(newEvent(1, 1)))
+	assert.Equal(t, 1, reopened.Pending(), "relayed ids are remembered across the compaction")
+}
+
+// flakySink fails once on the record with the id in failOn
+type flakySink struct {
+	sent   []string
+	failOn string
+}
+
+func (s *flakySink) Send(ctx context.Context, record *Record) error {
+	if record.Id == s.failOn {
+		s.failOn = ""
+		return errors.New("sink unavailable")
+	}
+	s.sent = append(s.sent, record.Id)
+	return nil
+}
+
+func TestRelay_RunOnce(t *testing.T) {
+	// setup
+	box, err := Open(filepath.Join(t.TempDir(), "outbox.log"))
+	assert.NoError(t, err)
+	for i := 1; i <= 3; i++ {
+		assert.NoError(t, box.Add(newEvent(uint(i), 1)))
+	}
+	sink := &flakySink{failOn: "2-1"}
+	relay := NewRelay(box, sink)
+
+	// action
+	relayed, err := relay.RunOnce(context.Background())
+
+	// assertions
+	assert.EqualError(t, err, "sink unavailable")
+	assert.Equal(t, 1, relayed)
+	assert.Equal(t, []string{"2-1", "3-1"}, pendingIds(t, box))
+
+	// action
+	relayed, err = relay.RunOnce(context.Background())
+
+	// assertions
+	assert.NoError(t, err)
+	assert.Equal(t, 2, relayed)
+	assert.Equal(t, []string{"1-1", "2-1", "3-1"}, sink.sent)
+	assert.Nil(t, box.Next())
+}
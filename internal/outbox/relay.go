@@ -0,0 +1,79 @@
+package outbox
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+const (
+	defaultBackoff = time.Second
+	maxBackoff     = 5 * time.Minute
+	// pollInterval the relay looks at the outbox this often even without being notified
+	pollInterval = 10 * time.Second
+)
+
+// Relay publishes the outbox to a sink in the order the records were added, a record is only
+// acknowledged once the sink took it, so every record is published at least once
+type Relay struct {
+	outbox  *Outbox
+	sink    Sink
+	Backoff time.Duration // wait after a failed send, doubled after every further one
+}
+
+func NewRelay(outbox *Outbox, sink Sink) *Relay {
+	return &Relay{outbox: outbox, sink: sink, Backoff: defaultBackoff}
+}
+
+// Start relays until the context is cancelled, a failing sink is retried with backoff
+func (r *Relay) Start(ctx context.Context) {
+	go func() {
+		wait := time.Duration(0)
+		for {
+			relayed, err := r.RunOnce(ctx)
+			if relayed > 0 {
+				logrus.WithField("events", relayed).Debug("outbox relayed")
+			}
+			if err != nil {
+				wait = min(max(wait*2, r.Backoff), maxBackoff)
+				logrus.WithError(err).WithField("retryIn", wait).Warn("outbox relay failed")
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(wait):
+				}
+				continue
+			}
+			wait = 0
+			select {
+			case <-ctx.Done():
+				return
+			case <-r.outbox.Notify():
+			case <-time.After(pollInterval):
+			}
+		}
+	}()
+}
+
+// RunOnce sends the pending records until the outbox is empty or a send fails, and returns how many were relayed
+func (r *Relay) RunOnce(ctx context.Context) (int, error) {
+	relayed := 0
+	for record := r.outbox.Next(); record != nil; record = r.outbox.Next() {
+		select {
+		case <-ctx.Done():
+			return relayed, ctx.Err()
+		default:
+		}
+		err := r.sink.Send(ctx, record)
+		if err != nil {
+			return relayed, err
+		}
+		err = r.outbox.Ack(record.Id)
+		if err != nil {
+			// sent again after a restart, the sink drops it by its id
+			return relayed, err
+		}
+		relayed++
+	}
+	return relayed, nil
+}
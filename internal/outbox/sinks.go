@@ -0,0 +1,158 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"immudb/internal/webhooks"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	SinkLog     = "log"
+	SinkFile    = "file"
+	SinkWebhook = "webhook"
+	// HeaderIdempotencyKey carries the deduplication id on webhook sinks
+	HeaderIdempotencyKey = "Idempotency-Key"
+	sinkTimeout          = 10 * time.Second
+)
+
+// Sink is where the relay publishes the records to. Send must only succeed once the record is safely delivered,
+// a record that fails is sent again, with the same id, until it succeeds.
+type Sink interface {
+	Send(ctx context.Context, record *Record) error
+}
+
+// Broker is the client of a message broker, a Kafka or NATS producer for example.
+// Brokers that deduplicate on their own should use dedupId, like the Nats-Msg-Id header of JetStream.
+type Broker interface {
+	Publish(ctx context.Context, topic, key, dedupId string, value []byte) error
+}
+
+// LogSink logs every record, for development
+type LogSink struct{}
+
+func (LogSink) Send(ctx context.Context, record *Record) error {
+	logrus.WithFields(logrus.Fields{"id": record.Id, "type": record.Type, "transactionId": record.TransactionId}).Info("outbox event")
+	return nil
+}
+
+// FileSink appends every record as a line of JSON, readers drop duplicates by id
+type FileSink struct {
+	mx   sync.Mutex
+	file *os.File
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{file: file}, nil
+}
+
+func (s *FileSink) Send(ctx context.Context, record *Record) error {
+	line, err := json.Marshal(struct {
+		Id      string          `json:"id"`
+		Type    string          `json:"type"`
+		Key     string          `json:"key"`
+		Payload json.RawMessage `json:"payload"`
+	}{record.Id, string(record.Type), record.Key, record.Payload})
+	if err != nil {
+		return err
+	}
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	_, err = s.file.Write(append(line, '\n'))
+	if err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// WebhookSink posts every record to one url, with the id as Idempotency-Key.
+// With a secret the body is signed like the webhooks sign theirs.
+type WebhookSink struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func NewWebhookSink(url, secret string) *WebhookSink {
+	return &WebhookSink{url: url, secret: secret, client: &http.Client{Timeout: sinkTimeout}}
+}
+
+func (s *WebhookSink) Send(ctx context.Context, record *Record) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(record.Payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderIdempotencyKey, record.Id)
+	request.Header.Set(webhooks.HeaderEvent, string(record.Type))
+	if s.secret != "" {
+		timestamp := time.Now().Unix()
+		request.Header.Set(webhooks.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+		request.Header.Set(webhooks.HeaderSignature, webhooks.Sign(s.secret, timestamp, record.Payload))
+	}
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("the outbox webhook answered %d", response.StatusCode)
+	}
+	return nil
+}
+
+// BrokerSink publishes every record to a topic, keyed by account so the events of one account stay in order
+type BrokerSink struct {
+	broker Broker
+	topic  string
+}
+
+func NewBrokerSink(broker Broker, topic string) *BrokerSink {
+	return &BrokerSink{broker: broker, topic: topic}
+}
+
+func (s *BrokerSink) Send(ctx context.Context, record *Record) error {
+	return s.broker.Publish(ctx, s.topic, record.Key, record.Id, record.Payload)
+}
+
+// NewSink opens the sink of the given kind, target is the path of the file sink and the url of the webhook sink
+func NewSink(kind, target, secret string) (Sink, error) {
+	switch kind {
+	case "", SinkLog:
+		return LogSink{}, nil
+	case SinkFile:
+		if target == "" {
+			return nil, fmt.Errorf("the %s outbox sink needs a target path", kind)
+		}
+		return NewFileSink(target)
+	case SinkWebhook:
+		if target == "" {
+			return nil, fmt.Errorf("the %s outbox sink needs a target url", kind)
+		}
+		return NewWebhookSink(target, secret), nil
+	default:
+		return nil, fmt.Errorf("unknown outbox sink %s, use %s, %s or %s", kind, SinkLog, SinkFile, SinkWebhook)
+	}
+}
@@ -0,0 +1,117 @@
+package outbox
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"immudb/internal/webhooks"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+var record = &Record{Id: "7-1", Type: "account.created", Key: "7", Payload: []byte(`{"id":"7-1"}`)}
+
+func TestFileSink(t *testing.T) {
+	// setup
+	path := filepath.Join(t.TempDir(), "events", "outbox.jsonl")
+	sink, err := NewFileSink(path)
+	assert.NoError(t, err)
+
+	// action
+	assert.NoError(t, sink.Send(context.Background(), record))
+	assert.NoError(t, sink.Close())
+
+	// assertions
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":"7-1","type":"account.created","key":"7","payload":{"id":"7-1"}}`+"\n", string(data))
+}
+
+func TestWebhookSink(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		expectedError string
+	}{
+		{name: "Test_Accepted", status: http.StatusAccepted},
+		{name: "Test_Refused", status: http.StatusConflict, expectedError: "the outbox webhook answered 409"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			var received *http.Request
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received = r
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+			sink := NewWebhookSink(server.URL, "0123456789abcdef")
+
+			// action
+			err := sink.Send(context.Background(), record)
+
+			// assertions
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, "7-1", received.Header.Get(HeaderIdempotencyKey))
+			timestamp, err := strconv.ParseInt(received.Header.Get(webhooks.HeaderTimestamp), 10, 64)
+			assert.NoError(t, err)
+			assert.Equal(t, webhooks.Sign("0123456789abcdef", timestamp, body), received.Header.Get(webhooks.HeaderSignature))
+		})
+	}
+}
+
+type fakeBroker struct {
+	topic, key, dedupId string
+}
+
+func (b *fakeBroker) Publish(ctx context.Context, topic, key, dedupId string, value []byte) error {
+	b.topic, b.key, b.dedupId = topic, key, dedupId
+	return nil
+}
+
+func TestBrokerSink(t *testing.T) {
+	// setup
+	broker := &fakeBroker{}
+
+	// action
+	err := NewBrokerSink(broker, "accounts").Send(context.Background(), record)
+
+	// assertions
+	assert.NoError(t, err)
+	assert.Equal(t, fakeBroker{topic: "accounts", key: "7", dedupId: "7-1"}, *broker)
+}
+
+func TestNewSink(t *testing.T) {
+	tests := []struct {
+		name          string
+		kind          string
+		target        string
+		expectedError string
+	}{
+		{name: "Test_Default", kind: ""},
+		{name: "Test_Webhook", kind: SinkWebhook, target: "http://localhost/events"},
+		{name: "Test_File_Without_Target", kind: SinkFile, expectedError: "the file outbox sink needs a target path"},
+		{name: "Test_Unknown", kind: "kafka", expectedError: "unknown outbox sink kafka, use log, file or webhook"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink, err := NewSink(tt.kind, tt.target, "")
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, sink)
+		})
+	}
+}
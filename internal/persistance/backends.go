@@ -0,0 +1,79 @@
+package persistance
+
+import "errors"
+
+const (
+	BackendImmudbVault = "immudb-vault"
+	BackendMemory      = "memory"
+	BackendFileLedger  = "file-ledger"
+)
+
+func init() {
+	Register(BackendImmudbVault, Backend{
+		NewSettings: func() BackendSettings { return &ImmudbVaultSettings{} },
+		Open: func(settings BackendSettings, _ BackendBlocks) (AccountDB, error) {
+			vault := settings.(*ImmudbVaultSettings)
+			return NewImmmuDB(vault.Url, vault.ApiKey, vault.SearchUrl), nil
+		},
+	})
+	Register(BackendMemory, Backend{
+		NewSettings: func() BackendSettings { return &MemorySettings{} },
+		Open: func(BackendSettings, BackendBlocks) (AccountDB, error) {
+			return NewMemoryDB(), nil
+		},
+	})
+	Register(BackendFileLedger, Backend{
+		NewSettings: func() BackendSettings { return &FileLedgerSettings{} },
+		Open: func(settings BackendSettings, _ BackendBlocks) (AccountDB, error) {
+			ledger := settings.(*FileLedgerSettings)
+			db, err := OpenFileLedgerDB(ledger.Path)
+			if err != nil {
+				return nil, err
+			}
+			db.Verifier = newTrustedVerifier(ledger.TrustedStatePath)
+			return db, nil
+		},
+	})
+}
+
+type ImmudbVaultSettings struct {
+	Url       string
+	SearchUrl string
+	ApiKey    string // taken from the IMMUDBAPIKEY environment variable when set
+}
+
+func (s *ImmudbVaultSettings) Validate() error {
+	if s.Url == "" || s.SearchUrl == "" {
+		return errors.New("Url and SearchUrl are required")
+	}
+	if s.ApiKey == "" {
+		return errors.New("ApiKey is required, set it with the IMMUDBAPIKEY environment variable or use Backend: memory")
+	}
+	return nil
+}
+
+// MemorySettings is empty, the memory backend has nothing to configure
+type MemorySettings struct{}
+
+func (s *MemorySettings) Validate() error {
+	return nil
+}
+
+type FileLedgerSettings struct {
+	Path             string // the append only log
+	TrustedStatePath string // where the last verified ledger state is kept, empty keeps it in memory
+}
+
+func (s *FileLedgerSettings) Validate() error {
+	if s.Path == "" {
+		return errors.New("Path is required")
+	}
+	return nil
+}
+
+func newTrustedVerifier(trustedStatePath string) *Verifier {
+	if trustedStatePath == "" {
+		return NewVerifier(NewMemoryStateStore())
+	}
+	return NewVerifier(NewFileStateStore(trustedStatePath))
+}
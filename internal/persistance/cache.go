@@ -0,0 +1,149 @@
+package persistance
+
+import (
+	"container/list"
+	"context"
+	"expvar"
+	cerror "immudb/internal/errors"
+	"immudb/internal/models"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	id      uint
+	account *models.AccountInfo
+	expires time.Time
+}
+
+// CachedDB keeps the latest revision of the most recently read accounts. A revision never changes once written,
+// so an entry only goes stale when the account is updated: through this instance the entry is replaced right away,
+// through another instance it is served until the TTL runs out.
+type CachedDB struct {
+	next       AccountDB
+	maxEntries int
+	ttl        time.Duration
+	mx         sync.Mutex
+	entries    map[uint]*list.Element
+	lru        *list.List // most recently used first
+	now        func() time.Time
+	// Metrics counts hits, misses, invalidations, expirations, evictions and the size of this cache only,
+	// it is not published, the caller decides where it is served
+	Metrics *expvar.Map
+}
+
+func NewCachedDB(next AccountDB, maxEntries int, ttl time.Duration) *CachedDB {
+	return &CachedDB{
+		next:       next,
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		entries:    map[uint]*list.Element{},
+		lru:        list.New(),
+		now:        time.Now,
+		Metrics:    new(expvar.Map),
+	}
+}
+
+func (db *CachedDB) Unwrap() AccountDB {
+	return db.next
+}
+
+func (db *CachedDB) CreateAccountInfo(ctx context.Context, data models.AccountInfo) (*models.AccountInfo, error) {
+	created, err := db.next.CreateAccountInfo(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	db.put(created)
+	return created, nil
+}
+
+func (db *CachedDB) GetAllAccountInfos(ctx context.Context, pageNr, pageSize int) ([]*models.AccountInfo, error) {
+	return db.next.GetAllAccountInfos(ctx, pageNr, pageSize)
+}
+
+func (db *CachedDB) GetAccountInfoById(ctx context.Context, Id uint) (*models.AccountInfo, error) {
+	if account := db.get(Id); account != nil {
+		db.Metrics.Add("hits", 1)
+		return account, nil
+	}
+	db.Metrics.Add("misses", 1)
+	account, err := db.next.GetAccountInfoById(ctx, Id)
+	if err != nil {
+		return nil, err
+	}
+	db.put(account)
+	return account, nil
+}
+
+// UpdateAccountInfo has to go through the cache so the replaced revision is not served anymore
+func (db *CachedDB) UpdateAccountInfo(ctx context.Context, Id uint, expectedRevision uint64, data models.AccountInfo) (*models.AccountInfo, error) {
+	updatable, ok := As[UpdatableDB](db.next)
+	if !ok {
+		return nil, cerror.NewServiceError("updates are not supported by the configured backend", http.StatusNotImplemented)
+	}
+	updated, err := updatable.UpdateAccountInfo(ctx, Id, expectedRevision, data)
+	if err != nil {
+		// a stale revision means our entry might be stale as well
+		db.Invalidate(Id)
+		return nil, err
+	}
+	db.put(updated)
+	return updated, nil
+}
+
+// Invalidate drops the account, the next read goes to the backend
+func (db *CachedDB) Invalidate(Id uint) {
+	db.mx.Lock()
+	defer db.mx.Unlock()
+	if element, ok := db.entries[Id]; ok {
+		db.remove(element)
+		db.Metrics.Add("invalidations", 1)
+	}
+}
+
+func (db *CachedDB) get(Id uint) *models.AccountInfo {
+	db.mx.Lock()
+	defer db.mx.Unlock()
+	element, ok := db.entries[Id]
+	if !ok {
+		return nil
+	}
+	entry := element.Value.(*cacheEntry)
+	if !db.now().Before(entry.expires) {
+		db.remove(element)
+		db.Metrics.Add("expirations", 1)
+		return nil
+	}
+	db.lru.MoveToFront(element)
+	return copyAccountInfo(entry.account)
+}
+
+func (db *CachedDB) put(account *models.AccountInfo) {
+	db.mx.Lock()
+	defer db.mx.Unlock()
+	entry := &cacheEntry{id: account.Id, account: copyAccountInfo(account), expires: db.now().Add(db.ttl)}
+	if element, ok := db.entries[account.Id]; ok {
+		current := element.Value.(*cacheEntry).account
+		// a slow read must not put back a revision an update already replaced
+		if current.Meta != nil && account.Meta != nil && current.Meta.Revision > account.Meta.Revision {
+			return
+		}
+		element.Value = entry
+		db.lru.MoveToFront(element)
+		return
+	}
+	db.entries[account.Id] = db.lru.PushFront(entry)
+	db.Metrics.Add("size", 1)
+	for db.lru.Len() > db.maxEntries {
+		db.remove(db.lru.Back())
+		db.Metrics.Add("evictions", 1)
+	}
+}
+
+// remove must be called with the lock held
+func (db *CachedDB) remove(element *list.Element) {
+	db.lru.Remove(element)
+	delete(db.entries, element.Value.(*cacheEntry).id)
+	db.Metrics.Add("size", -1)
+}
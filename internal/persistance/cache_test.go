@@ -0,0 +1,101 @@
+package persistance
+
+import (
+	"context"
+	"expvar"
+	"github.com/stretchr/testify/assert"
+	"immudb/internal/models"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingDB counts the lookups that reach the backend
+type countingDB struct {
+	*MemoryDB
+	lookups atomic.Int64
+}
+
+func (db *countingDB) GetAccountInfoById(ctx context.Context, Id uint) (*models.AccountInfo, error) {
+	db.lookups.Add(1)
+	return db.MemoryDB.GetAccountInfoById(ctx, Id)
+}
+
+func cacheMetric(db *CachedDB, name string) int64 {
+	value, ok := db.Metrics.Get(name).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+	return value.Value()
+}
+
+func TestCachedDB_GetAccountInfoById(t *testing.T) {
+	// setup
+	ctx := context.Background()
+	backend := &countingDB{MemoryDB: NewMemoryDB()}
+	first, err := backend.CreateAccountInfo(ctx, models.AccountInfo{Name: "first"})
+	assert.NoError(t, err)
+	second, err := backend.CreateAccountInfo(ctx, models.AccountInfo{Name: "second"})
+	assert.NoError(t, err)
+	third, err := backend.CreateAccountInfo(ctx, models.AccountInfo{Name: "third"})
+	assert.NoError(t, err)
+	now := time.Now()
+	db := NewCachedDB(backend, 2, time.Minute)
+	db.now = func() time.Time { return now }
+
+	// action
+	for i := 0; i < 3; i++ {
+		account, err := db.GetAccountInfoById(ctx, first.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, first, account)
+	}
+	account, _ := db.GetAccountInfoById(ctx, first.Id)
+	account.Name = "changed by the caller"
+
+	// assertions
+	assert.Equal(t, int64(1), backend.lookups.Load(), "repeated lookups must be served from the cache")
+	assert.Equal(t, int64(3), cacheMetric(db, "hits"))
+	assert.Equal(t, int64(1), cacheMetric(db, "misses"))
+	assert.Equal(t, int64(0), cacheMetric(NewCachedDB(backend, 2, time.Minute), "hits"), "every cache counts on its own")
+	cached, _ := db.GetAccountInfoById(ctx, first.Id)
+	assert.Equal(t, first, cached, "callers must not change what is cached")
+
+	// the least recently used account is evicted
+	_, _ = db.GetAccountInfoById(ctx, second.Id)
+	_, _ = db.GetAccountInfoById(ctx, first.Id)
+	_, _ = db.GetAccountInfoById(ctx, third.Id)
+	assert.Equal(t, int64(3), backend.lookups.Load())
+	_, _ = db.GetAccountInfoById(ctx, first.Id)
+	assert.Equal(t, int64(3), backend.lookups.Load())
+	_, _ = db.GetAccountInfoById(ctx, second.Id)
+	assert.Equal(t, int64(4), backend.lookups.Load(), "second was the least recently used")
+
+	// entries expire
+	now = now.Add(time.Minute)
+	_, _ = db.GetAccountInfoById(ctx, second.Id)
+	assert.Equal(t, int64(5), backend.lookups.Load())
+}
+
+func TestCachedDB_UpdateAccountInfo(t *testing.T) {
+	// setup
+	ctx := context.Background()
+	db := NewCachedDB(NewMemoryDB(), 10, time.Minute)
+	created, err := db.CreateAccountInfo(ctx, models.AccountInfo{Name: "first"})
+	assert.NoError(t, err)
+
+	// action
+	updated, err := db.UpdateAccountInfo(ctx, created.Id, 1, models.AccountInfo{Name: "updated"})
+	assert.NoError(t, err)
+	db.put(created)
+	result, err := db.GetAccountInfoById(ctx, created.Id)
+
+	// assertions
+	assert.NoError(t, err)
+	assert.Equal(t, updated, result, "an update must replace the cached revision and an older one must not come back")
+	_, ok := As[UpdatableDB](db)
+	assert.True(t, ok)
+	_, ok = As[ChangeFeed](db)
+	assert.True(t, ok, "the optional interfaces of the backend must stay reachable")
+	_, ok = As[VerifiableDB](db)
+	assert.False(t, ok)
+}
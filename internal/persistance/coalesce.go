@@ -0,0 +1,87 @@
+package persistance
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"golang.org/x/sync/singleflight"
+	"immudb/internal/models"
+)
+
+// CoalescedDB lets concurrent identical reads share one call to the backend. The shared call runs without
+// the deadline of whoever started it, every caller still stops waiting when its own context is done.
+type CoalescedDB struct {
+	next  AccountDB
+	group singleflight.Group
+	// Metrics counts the callers of this instance, shared the ones whose result was shared with at least one other caller,
+	// it is not published, the caller decides where it is served
+	Metrics *expvar.Map
+}
+
+func NewCoalescedDB(next AccountDB) *CoalescedDB {
+	return &CoalescedDB{next: next, Metrics: new(expvar.Map)}
+}
+
+func (db *CoalescedDB) Unwrap() AccountDB {
+	return db.next
+}
+
+func (db *CoalescedDB) CreateAccountInfo(ctx context.Context, data models.AccountInfo) (*models.AccountInfo, error) {
+	return db.next.CreateAccountInfo(ctx, data)
+}
+
+func (db *CoalescedDB) GetAllAccountInfos(ctx context.Context, pageNr, pageSize int) ([]*models.AccountInfo, error) {
+	result, err := db.do(ctx, fmt.Sprintf("page:%d:%d", pageNr, pageSize), func(ctx context.Context) (interface{}, error) {
+		return db.next.GetAllAccountInfos(ctx, pageNr, pageSize)
+	})
+	if err != nil {
+		return nil, err
+	}
+	shared := result.([]*models.AccountInfo)
+	if shared == nil {
+		return nil, nil
+	}
+	output := make([]*models.AccountInfo, 0, len(shared))
+	for _, account := range shared {
+		output = append(output, copyAccountInfo(account))
+	}
+	return output, nil
+}
+
+func (db *CoalescedDB) GetAccountInfoById(ctx context.Context, Id uint) (*models.AccountInfo, error) {
+	result, err := db.do(ctx, fmt.Sprintf("id:%d", Id), func(ctx context.Context) (interface{}, error) {
+		return db.next.GetAccountInfoById(ctx, Id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	account := result.(*models.AccountInfo)
+	if account == nil {
+		return nil, nil
+	}
+	return copyAccountInfo(account), nil
+}
+
+// do returns the result of the call in flight for key or starts it, callers get their own copy of the result
+// since they share the pointers the backend returned
+func (db *CoalescedDB) do(ctx context.Context, key string, call func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	upstream := context.WithoutCancel(ctx)
+	results := db.group.DoChan(key, func() (interface{}, error) {
+		return call(upstream)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-results:
+		if result.Shared {
+			db.Metrics.Add("shared", 1)
+		}
+		db.Metrics.Add("callers", 1)
+		return result.Val, result.Err
+	}
+}
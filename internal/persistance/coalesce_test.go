@@ -0,0 +1,73 @@
+package persistance
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"immudb/internal/models"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingDB holds every lookup until release is closed
+type blockingDB struct {
+	*MemoryDB
+	lookups atomic.Int64
+	started chan struct{}
+	release chan struct{}
+	ctxErr  atomic.Value
+}
+
+func (db *blockingDB) GetAccountInfoById(ctx context.Context, Id uint) (*models.AccountInfo, error) {
+	if db.lookups.Add(1) == 1 {
+		close(db.started)
+	}
+	<-db.release
+	db.ctxErr.Store(ctx.Err() == nil)
+	return db.MemoryDB.GetAccountInfoById(ctx, Id)
+}
+
+func TestCoalescedDB_GetAccountInfoById(t *testing.T) {
+	// setup
+	backend := &blockingDB{MemoryDB: NewMemoryDB(), started: make(chan struct{}), release: make(chan struct{})}
+	created, err := backend.CreateAccountInfo(context.Background(), models.AccountInfo{Name: "first"})
+	assert.NoError(t, err)
+	db := NewCoalescedDB(backend)
+	callers := 10
+	results := make([]*models.AccountInfo, callers)
+	wg := sync.WaitGroup{}
+	first, cancelFirst := context.WithCancel(context.Background())
+
+	// action
+	wg.Add(1)
+	var firstErr error
+	go func() {
+		defer wg.Done()
+		_, firstErr = db.GetAccountInfoById(first, created.Id)
+	}()
+	<-backend.started
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = db.GetAccountInfoById(context.Background(), created.Id)
+		}(i)
+	}
+	// give the callers time to join the call in flight
+	time.Sleep(100 * time.Millisecond)
+	cancelFirst()
+	time.Sleep(10 * time.Millisecond)
+	close(backend.release)
+	wg.Wait()
+
+	// assertions
+	assert.Equal(t, int64(1), backend.lookups.Load(), "concurrent lookups must share one backend call")
+	assert.ErrorIs(t, firstErr, context.Canceled, "a cancelled caller must stop waiting")
+	assert.Equal(t, true, backend.ctxErr.Load(), "cancelling the caller that started the call must not cancel it for the others")
+	for _, result := range results {
+		assert.Equal(t, created, result)
+	}
+	results[0].Name = "changed by the caller"
+	assert.Equal(t, created.Name, results[1].Name, "every caller must get its own copy")
+}
@@ -3,6 +3,7 @@ package persistance
 import (
 	"context"
 	"immudb/internal/models"
+	"sort"
 )
 
 type AccountDB interface {
@@ -10,3 +11,93 @@ type AccountDB interface {
 	GetAllAccountInfos(ctx context.Context, pageNr, pageSize int) ([]*models.AccountInfo, error)
 	GetAccountInfoById(ctx context.Context, Id uint) (*models.AccountInfo, error)
 }
+
+// DocumentDB is implemented by backends that can load an account by the id the store assigned to its document
+type DocumentDB interface {
+	GetAccountInfoByDocumentId(ctx context.Context, documentId string) (*models.AccountInfo, error)
+}
+
+// VerifiableDB is implemented by backends that can prove their documents are part of a tamper evident ledger
+type VerifiableDB interface {
+	GetVerifiedAccountInfoById(ctx context.Context, Id uint) (*models.AccountInfo, *models.Verification, error)
+}
+
+// AuditableDB is implemented by backends whose ledger can be audited in the background
+type AuditableDB interface {
+	// RecentRevisions returns the latest committed document revisions, newest first
+	RecentRevisions(ctx context.Context, limit int) ([]RevisionRef, error)
+	GetDocumentProof(ctx context.Context, documentId string, transactionId uint64) (*DocumentProof, error)
+}
+
+// BatchDB is implemented by backends that can create many accounts with fewer writes than one per account
+type BatchDB interface {
+	// CreateAccountInfos returns the created accounts in input order, on error only the ones written before it
+	CreateAccountInfos(ctx context.Context, data []models.AccountInfo) ([]*models.AccountInfo, error)
+}
+
+// RevisionDB is implemented by backends that keep every revision of an account
+type RevisionDB interface {
+	// GetAccountInfoRevisions returns every revision of the document, oldest first
+	GetAccountInfoRevisions(ctx context.Context, documentId string) ([]*models.AccountInfo, error)
+}
+
+// UpdatableDB is implemented by backends that can store a new revision of an account
+type UpdatableDB interface {
+	// UpdateAccountInfo fails with 412 if the current revision is not the expected one
+	UpdateAccountInfo(ctx context.Context, Id uint, expectedRevision uint64, data models.AccountInfo) (*models.AccountInfo, error)
+}
+
+// ChangeFeed is implemented by backends that can list the revisions committed after a cursor
+type ChangeFeed interface {
+	// ChangesSince returns at most limit revisions committed after the cursor in feed order, the cursor of the last one
+	// is where the next call continues. The feed is at least once, a revision may come again after a later one.
+	ChangesSince(ctx context.Context, cursor ChangeCursor, limit int) ([]Change, error)
+	// Head points at the last committed revision, the zero cursor when nothing was committed yet
+	Head(ctx context.Context) (ChangeCursor, error)
+}
+
+// Change is a revision of the change feed and its position in it
+type Change struct {
+	Cursor   ChangeCursor
+	Revision *models.AccountInfo
+}
+
+// CursorOf points at the revision, which must carry its ledger metadata
+func CursorOf(revision *models.AccountInfo) ChangeCursor {
+	return ChangeCursor{TransactionId: revision.Meta.TransactionId, DocumentId: revision.DocumentId, Timestamp: revision.Meta.Timestamp, Revision: revision.Meta.Revision}
+}
+
+// changesOf are the revisions of a feed ordered by transaction, every one at its own position
+func changesOf(revisions []*models.AccountInfo) []Change {
+	output := make([]Change, 0, len(revisions))
+	for _, revision := range revisions {
+		output = append(output, Change{Cursor: CursorOf(revision), Revision: revision})
+	}
+	return output
+}
+
+// Before tells if the revision the cursor points at comes before the given one in the change feed
+func (c ChangeCursor) Before(other ChangeCursor) bool {
+	if c.TransactionId != other.TransactionId {
+		return c.TransactionId < other.TransactionId
+	}
+	if !c.Timestamp.Equal(other.Timestamp) {
+		return c.Timestamp.Before(other.Timestamp)
+	}
+	if c.DocumentId != other.DocumentId {
+		return c.DocumentId < other.DocumentId
+	}
+	return c.Revision < other.Revision
+}
+
+// sortChanges puts revisions in change feed order
+func sortChanges(revisions []*models.AccountInfo) {
+	sort.Slice(revisions, func(i, j int) bool {
+		return CursorOf(revisions[i]).Before(CursorOf(revisions[j]))
+	})
+}
+
+// TrustedLedger is implemented by backends that check their ledger against the last state they trusted
+type TrustedLedger interface {
+	LedgerVerifier() *Verifier
+}
@@ -0,0 +1,23 @@
+package persistance
+
+// Wrapper is implemented by decorators around an AccountDB, the optional interfaces of the wrapped backend stay reachable through As
+type Wrapper interface {
+	Unwrap() AccountDB
+}
+
+// As finds the first backend in the chain of decorators that implements T, starting with db itself.
+// Decorators that have to see a call, like the cache for updates, implement the interface themselves.
+func As[T any](db AccountDB) (T, bool) {
+	for db != nil {
+		if result, ok := db.(T); ok {
+			return result, true
+		}
+		wrapper, ok := db.(Wrapper)
+		if !ok {
+			break
+		}
+		db = wrapper.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
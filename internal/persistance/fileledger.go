@@ -0,0 +1,507 @@
+package persistance
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"hash/crc32"
+	cerror "immudb/internal/errors"
+	"immudb/internal/merkle"
+	"immudb/internal/models"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	ledgerHeaderSize   = 8 // payload length and crc32 of the payload, both big endian uint32
+	maxLedgerEntrySize = 16 << 20
+	fileLedgerCreator  = "file-ledger"
+)
+
+var ledgerChecksum = crc32.MakeTable(crc32.Castagnoli)
+
+// ledgerEntry is one committed document revision, every entry is its own transaction
+type ledgerEntry struct {
+	DocumentID string    `json:"documentId"`
+	Revision   uint64    `json:"revision"`
+	Timestamp  time.Time `json:"timestamp"`
+	Document   []byte    `json:"document"` // the document exactly as it was hashed into the tree
+}
+
+// FileLedgerDB keeps every revision in an append only log on the local disk and a merkle tree over the entries,
+// its proofs back the verified reads, the tamper alarm and the background audit
+type FileLedgerDB struct {
+	mx        sync.RWMutex
+	file      *os.File
+	size      int64 // offset of the end of the last complete entry
+	tree      *merkle.Tree
+	entries   []*ledgerEntry
+	documents map[string][]uint64 // transaction ids of every document, oldest first
+	byId      map[uint]string     // account number to document id
+	order     []string            // document ids in creation order
+	Id        uint
+	Verifier  *Verifier
+}
+
+// OpenFileLedgerDB replays the log at path, a torn entry at the end left by a crash is cut off
+func OpenFileLedgerDB(path string) (*FileLedgerDB, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	db := &FileLedgerDB{
+		file:      file,
+		tree:      merkle.NewTree(),
+		documents: map[string][]uint64{},
+		byId:      map[uint]string{},
+		Id:        uint(time.Now().UnixNano()),
+		Verifier:  NewVerifier(NewMemoryStateStore()),
+	}
+	err = db.recover()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	// persist the directory entry of a newly created log
+	dir, err := os.Open(filepath.Dir(path))
+	if err == nil {
+		err = dir.Sync()
+		_ = dir.Close()
+	}
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func (db *FileLedgerDB) LedgerVerifier() *Verifier {
+	return db.Verifier
+}
+
+func (db *FileLedgerDB) Close() error {
+	db.mx.Lock()
+	defer db.mx.Unlock()
+	return db.file.Close()
+}
+
+// recover reads every complete entry, a damaged entry is only tolerated at the very end of the log
+func (db *FileLedgerDB) recover() error {
+	info, err := db.file.Stat()
+	if err != nil {
+		return err
+	}
+	reader := io.NewSectionReader(db.file, 0, info.Size())
+	var offset int64
+	for offset < info.Size() {
+		entry, length, err := readLedgerEntry(reader, offset, info.Size())
+		if errors.Is(err, errTornEntry) {
+			logrus.WithFields(logrus.Fields{
+				"path":   db.file.Name(),
+				"offset": offset,
+				"bytes":  info.Size() - offset,
+			}).Warn("cutting off a torn ledger entry left by a crash")
+			err = db.file.Truncate(offset)
+			if err == nil {
+				err = db.file.Sync()
+			}
+			if err != nil {
+				return err
+			}
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("ledger %s is corrupted at offset %d: %w", db.file.Name(), offset, err)
+		}
+		db.apply(entry)
+		offset += length
+	}
+	db.size = offset
+	return nil
+}
+
+var errTornEntry = errors.New("torn entry")
+
+// readLedgerEntry returns errTornEntry when the entry runs past the end of the log or is the last one and fails its checksum
+func readLedgerEntry(reader io.ReaderAt, offset, end int64) (*ledgerEntry, int64, error) {
+	if end-offset < ledgerHeaderSize {
+		return nil, 0, errTornEntry
+	}
+	header := make([]byte, ledgerHeaderSize)
+	_, err := reader.ReadAt(header, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	length := int64(binary.BigEndian.Uint32(header[0:4]))
+	if length > maxLedgerEntrySize {
+		return nil, 0, fmt.Errorf("entry of %d bytes is too big", length)
+	}
+	total := ledgerHeaderSize + length
+	if offset+total > end {
+		return nil, 0, errTornEntry
+	}
+	payload := make([]byte, length)
+	_, err = reader.ReadAt(payload, offset+ledgerHeaderSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	if crc32.Checksum(payload, ledgerChecksum) != binary.BigEndian.Uint32(header[4:8]) {
+		if offset+total == end {
+			return nil, 0, errTornEntry
+		}
+		return nil, 0, errors.New("checksum mismatch")
+	}
+	var entry ledgerEntry
+	err = json.Unmarshal(payload, &entry)
+	if err != nil {
+		return nil, 0, err
+	}
+	if entry.DocumentID == "" || !json.Valid(entry.Document) {
+		return nil, 0, errors.New("invalid document")
+	}
+	return &entry, total, nil
+}
+
+// append writes and fsyncs the entry before it becomes visible, a failed write is rolled back, must be called with the lock held
+func (db *FileLedgerDB) append(entry *ledgerEntry) (uint64, error) {
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		return 0, err
+	}
+	record := make([]byte, ledgerHeaderSize, ledgerHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.Checksum(payload, ledgerChecksum))
+	record = append(record, payload...)
+
+	_, err = db.file.WriteAt(record, db.size)
+	if err == nil {
+		err = db.file.Sync()
+	}
+	if err != nil {
+		// don't leave half an entry behind for the next append to build on
+		if truncateErr := db.file.Truncate(db.size); truncateErr != nil {
+			logrus.WithError(truncateErr).Error("failed to roll back a ledger write")
+		}
+		return 0, err
+	}
+	db.size += int64(len(record))
+	return db.apply(entry), nil
+}
+
+// apply adds a committed entry to the in memory indexes and returns its transaction id
+func (db *FileLedgerDB) apply(entry *ledgerEntry) uint64 {
+	transactionId := db.tree.Append(merkle.LeafHash(entry.Document)) + 1
+	db.entries = append(db.entries, entry)
+	if _, ok := db.documents[entry.DocumentID]; !ok {
+		db.order = append(db.order, entry.DocumentID)
+	}
+	db.documents[entry.DocumentID] = append(db.documents[entry.DocumentID], transactionId)
+	var account models.AccountInfo
+	if err := json.Unmarshal(entry.Document, &account); err == nil {
+		db.byId[account.Id] = entry.DocumentID
+		// ids are taken from the clock, a log written with a fast clock must not make us reuse them
+		db.Id = max(db.Id, account.Id)
+	}
+	return transactionId
+}
+
+func (db *FileLedgerDB) CreateAccountInfo(ctx context.Context, data models.AccountInfo) (*models.AccountInfo, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	db.mx.Lock()
+	defer db.mx.Unlock()
+	db.Id++
+	data.Id = db.Id
+	data.DocumentId = newLedgerDocumentId(db.tree.Size() + 1)
+	return db.commit(data, 1)
+}
+
+// UpdateAccountInfo the revision check and the write happen under the same lock so they can't interleave
+func (db *FileLedgerDB) UpdateAccountInfo(ctx context.Context, Id uint, expectedRevision uint64, data models.AccountInfo) (*models.AccountInfo, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	db.mx.Lock()
+	defer db.mx.Unlock()
+	documentId, ok := db.byId[Id]
+	if !ok {
+		return nil, cerror.NewServiceError("account info not found", http.StatusNotFound)
+	}
+	current, err := db.latest(documentId)
+	if err != nil {
+		return nil, err
+	}
+	if current.Meta.Revision != expectedRevision {
+		return nil, cerror.NewServiceError("account info was modified, reload it and apply your changes again", http.StatusPreconditionFailed)
+	}
+	data.Id = Id
+	data.DocumentId = documentId
+	return db.commit(data, current.Meta.Revision+1)
+}
+
+// commit must be called with the lock held
+func (db *FileLedgerDB) commit(data models.AccountInfo, revision uint64) (*models.AccountInfo, error) {
+	data.Meta = nil
+	document, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	entry := &ledgerEntry{DocumentID: data.DocumentId, Revision: revision, Timestamp: time.Now().UTC(), Document: document}
+	transactionId, err := db.append(entry)
+	if err != nil {
+		return nil, err
+	}
+	return db.toAccountInfo(transactionId)
+}
+
+func (db *FileLedgerDB) GetAllAccountInfos(ctx context.Context, pageNr, pageSize int) ([]*models.AccountInfo, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	if pageNr < 1 {
+		pageNr = 1
+	}
+	db.mx.RLock()
+	defer db.mx.RUnlock()
+	start := (pageNr - 1) * pageSize
+	if pageSize <= 0 || start >= len(db.order) {
+		return nil, nil
+	}
+	end := min(start+pageSize, len(db.order))
+	output := make([]*models.AccountInfo, 0, end-start)
+	for _, documentId := range db.order[start:end] {
+		account, err := db.latest(documentId)
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, account)
+	}
+	return output, nil
+}
+
+func (db *FileLedgerDB) GetAccountInfoById(ctx context.Context, Id uint) (*models.AccountInfo, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	db.mx.RLock()
+	defer db.mx.RUnlock()
+	documentId, ok := db.byId[Id]
+	if !ok {
+		return nil, cerror.NewServiceError("account info not found", http.StatusNotFound)
+	}
+	return db.latest(documentId)
+}
+
+func (db *FileLedgerDB) GetAccountInfoByDocumentId(ctx context.Context, documentId string) (*models.AccountInfo, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	db.mx.RLock()
+	defer db.mx.RUnlock()
+	if _, ok := db.documents[documentId]; !ok {
+		return nil, cerror.NewServiceError("account info not found", http.StatusNotFound)
+	}
+	return db.latest(documentId)
+}
+
+func (db *FileLedgerDB) GetAccountInfoRevisions(ctx context.Context, documentId string) ([]*models.AccountInfo, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	db.mx.RLock()
+	defer db.mx.RUnlock()
+	transactions, ok := db.documents[documentId]
+	if !ok {
+		return nil, cerror.NewServiceError("account info not found", http.StatusNotFound)
+	}
+	output := make([]*models.AccountInfo, 0, len(transactions))
+	for _, transactionId := range transactions {
+		account, err := db.toAccountInfo(transactionId)
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, account)
+	}
+	return output, nil
+}
+
+// GetVerifiedAccountInfoById checks the local proofs against the trusted state, a log truncated or rewritten
+// behind our back shows up as an inconsistency with the trusted state
+func (db *FileLedgerDB) GetVerifiedAccountInfoById(ctx context.Context, Id uint) (*models.AccountInfo, *models.Verification, error) {
+	account, err := db.GetAccountInfoById(ctx, Id)
+	if err != nil {
+		return nil, nil, err
+	}
+	proof, err := db.GetDocumentProof(ctx, account.DocumentId, account.Meta.TransactionId)
+	if err != nil {
+		return nil, nil, err
+	}
+	verification, err := db.Verifier.VerifyDocument(proof, account)
+	if err != nil {
+		return nil, nil, err
+	}
+	return account, verification, nil
+}
+
+// GetDocumentProof proves the revision against the current tree and the tree against the trusted state
+func (db *FileLedgerDB) GetDocumentProof(ctx context.Context, documentId string, transactionId uint64) (*DocumentProof, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	trustedId, err := db.Verifier.TrustedTransactionId()
+	if err != nil {
+		return nil, err
+	}
+	db.mx.RLock()
+	defer db.mx.RUnlock()
+	transactions, ok := db.documents[documentId]
+	if !ok {
+		return nil, cerror.NewServiceError("account info not found", http.StatusNotFound)
+	}
+	if transactionId == 0 {
+		transactionId = transactions[len(transactions)-1]
+	}
+	if transactionId > uint64(len(db.entries)) || db.entries[transactionId-1].DocumentID != documentId {
+		return nil, cerror.NewServiceError("the document has no revision in this transaction", http.StatusNotFound)
+	}
+	entry := db.entries[transactionId-1]
+	size := db.tree.Size()
+	inclusion, err := db.tree.InclusionProof(transactionId-1, size)
+	if err != nil {
+		return nil, err
+	}
+	// a trusted state past our end gets no consistency proof, the verifier reports the ledger moved backwards
+	var consistency [][]byte
+	if trustedId > 0 && trustedId <= size {
+		consistency, err = db.tree.ConsistencyProof(trustedId, size)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &DocumentProof{
+		DocumentID:       documentId,
+		Revision:         strconv.FormatUint(entry.Revision, 10),
+		TransactionID:    strconv.FormatUint(transactionId, 10),
+		EncodedDocument:  base64.StdEncoding.EncodeToString(entry.Document),
+		State:            LedgerState{TransactionID: size, RootHash: hex.EncodeToString(db.tree.Root())},
+		InclusionProof:   encodeHashes(inclusion),
+		ConsistencyProof: encodeHashes(consistency),
+	}, nil
+}
+
+// RecentRevisions returns the last committed entries, newest first
+func (db *FileLedgerDB) RecentRevisions(ctx context.Context, limit int) ([]RevisionRef, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	db.mx.RLock()
+	defer db.mx.RUnlock()
+	output := make([]RevisionRef, 0, min(limit, len(db.entries)))
+	for i := len(db.entries) - 1; i >= 0 && len(output) < limit; i-- {
+		output = append(output, RevisionRef{DocumentID: db.entries[i].DocumentID, TransactionID: uint64(i + 1)})
+	}
+	return output, nil
+}
+
+func (db *FileLedgerDB) ChangesSince(ctx context.Context, cursor ChangeCursor, limit int) ([]Change, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	db.mx.RLock()
+	defer db.mx.RUnlock()
+	var output []*models.AccountInfo
+	// every transaction holds one document, the one of the cursor itself is only left out if the cursor points at it
+	for transactionId := max(cursor.TransactionId, 1); transactionId <= uint64(len(db.entries)) && len(output) < limit; transactionId++ {
+		account, err := db.toAccountInfo(transactionId)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.Before(CursorOf(account)) {
+			output = append(output, account)
+		}
+	}
+	return changesOf(output), nil
+}
+
+func (db *FileLedgerDB) Head(ctx context.Context) (ChangeCursor, error) {
+	db.mx.RLock()
+	defer db.mx.RUnlock()
+	if len(db.entries) == 0 {
+		return ChangeCursor{}, nil
+	}
+	last, err := db.toAccountInfo(uint64(len(db.entries)))
+	if err != nil {
+		return ChangeCursor{}, err
+	}
+	return CursorOf(last), nil
+}
+
+// latest must be called with the lock held
+func (db *FileLedgerDB) latest(documentId string) (*models.AccountInfo, error) {
+	transactions := db.documents[documentId]
+	return db.toAccountInfo(transactions[len(transactions)-1])
+}
+
+// toAccountInfo decodes a fresh copy of the entry, must be called with the lock held.
+// An entry that passed its checksum but isn't an account is reported, never served as an empty one.
+func (db *FileLedgerDB) toAccountInfo(transactionId uint64) (*models.AccountInfo, error) {
+	entry := db.entries[transactionId-1]
+	var result models.AccountInfo
+	if err := json.Unmarshal(entry.Document, &result); err != nil {
+		logrus.WithError(err).WithField("transactionId", transactionId).Error("failed to decode a ledger entry")
+		return nil, fmt.Errorf("ledger entry of transaction %d is not an account: %w", transactionId, err)
+	}
+	result.Meta = &models.DocumentMeta{
+		Revision:      entry.Revision,
+		TransactionId: transactionId,
+		Timestamp:     entry.Timestamp,
+		Creator:       fileLedgerCreator,
+	}
+	return &result, nil
+}
+
+// newLedgerDocumentId has the same shape as the vault ids, seconds and transaction in hex
+func newLedgerDocumentId(transactionId uint64) string {
+	return fmt.Sprintf("%08x%016x%08x", time.Now().Unix(), transactionId, 0)
+}
+
+func encodeHashes(hashes [][]byte) []string {
+	result := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		result = append(result, hex.EncodeToString(hash))
+	}
+	return result
+}
@@ -0,0 +1,224 @@
+package persistance
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	cerror "immudb/internal/errors"
+	"immudb/internal/models"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func openTestLedger(t *testing.T, path string) *FileLedgerDB {
+	db, err := OpenFileLedgerDB(path)
+	assert.NoError(t, err, "failed to open the ledger")
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func TestFileLedgerDB_ReadWrite(t *testing.T) {
+	// setup
+	path := filepath.Join(t.TempDir(), "ledger.log")
+	db := openTestLedger(t, path)
+	ctx := context.Background()
+
+	// action
+	first, err := db.CreateAccountInfo(ctx, models.AccountInfo{Name: "first", Iban: "aa", Type: AddPointer(models.Sending)})
+	assert.NoError(t, err)
+	second, err := db.CreateAccountInfo(ctx, models.AccountInfo{Name: "second", Iban: "bb", Address: AddPointer("street")})
+	assert.NoError(t, err)
+	updated, err := db.UpdateAccountInfo(ctx, first.Id, 1, models.AccountInfo{Name: "first updated", Iban: "aa"})
+	assert.NoError(t, err)
+	_, staleErr := db.UpdateAccountInfo(ctx, first.Id, 1, models.AccountInfo{Name: "stale"})
+
+	// assertions
+	assert.Equal(t, uint64(1), first.Meta.TransactionId)
+	assert.Equal(t, uint64(2), second.Meta.TransactionId)
+	assert.Equal(t, &models.DocumentMeta{Revision: 2, TransactionId: 3, Timestamp: updated.Meta.Timestamp, Creator: fileLedgerCreator}, updated.Meta)
+	assert.Equal(t, first.DocumentId, updated.DocumentId)
+	assert.Equal(t, cerror.NewServiceError("account info was modified, reload it and apply your changes again", http.StatusPreconditionFailed), staleErr)
+
+	all, err := db.GetAllAccountInfos(ctx, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.AccountInfo{updated, second}, all)
+	byDocument, err := db.GetAccountInfoByDocumentId(ctx, second.DocumentId)
+	assert.NoError(t, err)
+	assert.Equal(t, second, byDocument)
+	revisions, err := db.GetAccountInfoRevisions(ctx, first.DocumentId)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.AccountInfo{first, updated}, revisions)
+	_, notFoundErr := db.GetAccountInfoById(ctx, second.Id+1)
+	assert.Equal(t, cerror.NewServiceError("account info not found", http.StatusNotFound), notFoundErr)
+
+	// everything must come back the same after a restart
+	assert.NoError(t, db.Close())
+	reopened := openTestLedger(t, path)
+	reopenedAll, err := reopened.GetAllAccountInfos(ctx, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, all, reopenedAll)
+	assert.Equal(t, db.tree.Root(), reopened.tree.Root())
+	created, err := reopened.CreateAccountInfo(ctx, models.AccountInfo{Name: "third"})
+	assert.NoError(t, err)
+	assert.Greater(t, created.Id, second.Id, "ids must not be reused after a restart")
+	assert.Equal(t, uint64(4), created.Meta.TransactionId)
+}
+
+func TestFileLedgerDB_Recovery(t *testing.T) {
+	tests := []struct {
+		name            string
+		damage          func(t *testing.T, path string, sizes []int64)
+		isErrorExpected bool
+		expectedEntries int
+	}{
+		{
+			name:            "Test_Clean_Shutdown",
+			damage:          func(t *testing.T, path string, sizes []int64) {},
+			expectedEntries: 3,
+		},
+		{
+			name: "Test_Torn_Header",
+			damage: func(t *testing.T, path string, sizes []int64) {
+				appendBytes(t, path, []byte{0, 0, 0})
+			},
+			expectedEntries: 3,
+		},
+		{
+			name: "Test_Torn_Payload",
+			damage: func(t *testing.T, path string, sizes []int64) {
+				assert.NoError(t, os.Truncate(path, sizes[2]-5))
+			},
+			expectedEntries: 2,
+		},
+		{
+			name: "Test_Last_Entry_Checksum_Mismatch",
+			damage: func(t *testing.T, path string, sizes []int64) {
+				flipByte(t, path, sizes[2]-2)
+			},
+			expectedEntries: 2,
+		},
+		{
+			name: "Test_Corrupted_Entry_In_The_Middle",
+			damage: func(t *testing.T, path string, sizes []int64) {
+				flipByte(t, path, sizes[1]-2)
+			},
+			isErrorExpected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			path := filepath.Join(t.TempDir(), "ledger.log")
+			db, err := OpenFileLedgerDB(path)
+			assert.NoError(t, err)
+			var sizes []int64
+			for _, name := range []string{"first", "second", "third"} {
+				_, err = db.CreateAccountInfo(context.Background(), models.AccountInfo{Name: name})
+				assert.NoError(t, err)
+				sizes = append(sizes, db.size)
+			}
+			assert.NoError(t, db.Close())
+			tt.damage(t, path, sizes)
+
+			// action
+			recovered, err := OpenFileLedgerDB(path)
+
+			// assertions
+			if tt.isErrorExpected {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			defer recovered.Close()
+			assert.Len(t, recovered.entries, tt.expectedEntries)
+			info, err := os.Stat(path)
+			assert.NoError(t, err)
+			assert.Equal(t, sizes[tt.expectedEntries-1], info.Size(), "the torn tail must be cut off")
+
+			// new entries must land right after the last good one
+			_, err = recovered.CreateAccountInfo(context.Background(), models.AccountInfo{Name: "after recovery"})
+			assert.NoError(t, err)
+			assert.NoError(t, recovered.Close())
+			reopened := openTestLedger(t, path)
+			assert.Len(t, reopened.entries, tt.expectedEntries+1)
+		})
+	}
+}
+
+func TestFileLedgerDB_UndecodableEntry(t *testing.T) {
+	// setup
+	db := openTestLedger(t, filepath.Join(t.TempDir(), "ledger.log"))
+	ctx := context.Background()
+	// valid json that passes the checksum but isn't an account, the id must be a number
+	db.mx.Lock()
+	_, err := db.append(&ledgerEntry{DocumentID: "broken", Revision: 1, Document: []byte(`{"id":"not a number"}`)})
+	db.mx.Unlock()
+	assert.NoError(t, err)
+
+	// action
+	_, byDocumentErr := db.GetAccountInfoByDocumentId(ctx, "broken")
+	_, allErr := db.GetAllAccountInfos(ctx, 1, 10)
+	_, revisionsErr := db.GetAccountInfoRevisions(ctx, "broken")
+	_, changesErr := db.ChangesSince(ctx, ChangeCursor{}, 10)
+
+	// assertions
+	assert.Error(t, byDocumentErr)
+	assert.Error(t, allErr)
+	assert.Error(t, revisionsErr)
+	assert.Error(t, changesErr)
+}
+
+func TestFileLedgerDB_Verification(t *testing.T) {
+	// setup
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ledger.log")
+	store := NewFileStateStore(filepath.Join(dir, "trusted-state.json"))
+	db := openTestLedger(t, path)
+	db.Verifier = NewVerifier(store)
+	ctx := context.Background()
+	first, err := db.CreateAccountInfo(ctx, models.AccountInfo{Name: "first"})
+	assert.NoError(t, err)
+	sizeAfterFirst := db.size
+	_, err = db.CreateAccountInfo(ctx, models.AccountInfo{Name: "second"})
+	assert.NoError(t, err)
+
+	// action
+	account, verification, err := db.GetVerifiedAccountInfoById(ctx, first.Id)
+
+	// assertions
+	assert.NoError(t, err)
+	assert.Equal(t, first, account)
+	assert.Equal(t, uint64(1), verification.TransactionId)
+	assert.Equal(t, uint64(2), verification.LedgerTransactionId)
+	refs, err := db.RecentRevisions(ctx, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []RevisionRef{{DocumentID: account.DocumentId, TransactionID: 1}}, refs[1:])
+
+	// cutting entries off the log behind our back must raise the alarm
+	assert.NoError(t, db.Close())
+	assert.NoError(t, os.Truncate(path, sizeAfterFirst))
+	truncated := openTestLedger(t, path)
+	truncated.Verifier = NewVerifier(store)
+	_, _, err = truncated.GetVerifiedAccountInfoById(ctx, first.Id)
+	var serviceError *cerror.ServiceError
+	assert.ErrorAs(t, err, &serviceError)
+	assert.Equal(t, cerror.TypeProofVerification, serviceError.Type)
+	assert.Error(t, truncated.Verifier.Healthy(), "the tamper alarm must be raised")
+}
+
+func appendBytes(t *testing.T, path string, data []byte) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	assert.NoError(t, err)
+	_, err = file.Write(data)
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+}
+
+func flipByte(t *testing.T, path string, offset int64) {
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	data[offset] ^= 0xff
+	assert.NoError(t, os.WriteFile(path, data, 0o644))
+}
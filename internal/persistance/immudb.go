@@ -11,11 +11,35 @@ import (
 	"immudb/internal/models"
 	"io"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
+const (
+	// revisionField a copy of the revision kept inside the stored document, so the replace query can match on it
+	revisionField       = "revision"
+	maxDocumentsPerCall = 100
+	revisionPageSize    = 100
+	maxRevisionPages    = 100 // an account with more revisions than this is most likely a bug on our side
+	// changeSettleTime the change feed leaves out the documents changed in the last seconds, a write of the second
+	// it read last could still get a position before the one it stopped at
+	changeSettleTime = 2 * time.Second
+)
+
+var errModified = cerror.NewServiceError("account info was modified, reload it and apply your changes again", http.StatusPreconditionFailed)
+
+// vaultDocument is the account as it is stored in the vault
+type vaultDocument struct {
+	models.AccountInfo
+	Revision uint64 `json:"revision"`
+}
+
+// ImmmuDB stores the accounts in the immudb vault. It does not check the vault proofs, which are immudb dual proofs
+// over the SQL encoded document, so it offers no verified reads, tamper alarm or audit, those need the file ledger backend.
 type ImmmuDB struct {
 	url            string
 	apiKey         string
@@ -23,6 +47,7 @@ type ImmmuDB struct {
 	DefaultHeaders map[string]string
 	mx             sync.Mutex
 	Id             uint
+	now            func() time.Time // the clock the change feed settles by
 }
 
 func NewImmmuDB(url, apiKey, searchUrl string) *ImmmuDB {
@@ -35,64 +60,45 @@ func NewImmmuDB(url, apiKey, searchUrl string) *ImmmuDB {
 			"Content-Type": "application/json",
 			"X-API-Key":    apiKey,
 		},
-		mx: sync.Mutex{},
-		Id: uint(time.Now().UnixNano()),
+		mx:  sync.Mutex{},
+		Id:  uint(time.Now().UnixNano()),
+		now: time.Now,
 	}
 }
 
 func (db *ImmmuDB) doCreateHttpCall(ctx context.Context, input interface{}) (*CreateResponse, error) {
-	// usually this needs to be taken from the configurations but for this sample application i am leaving it here
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	jsonData, err := json.Marshal(input)
-	if err != nil {
-		logrus.WithError(err).Error("json marshal failed")
-		return nil, err
-	}
+	return doHttpCall[CreateResponse](ctx, db, "PUT", db.url, input)
+}
 
-	req, err := http.NewRequestWithContext(ctx, "PUT", db.url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		logrus.WithError(err).Error("http request failed")
-		return nil, err
-	}
-	for key, value := range db.DefaultHeaders {
-		req.Header.Set(key, value)
-	}
+func (db *ImmmuDB) doCreateDocumentsHttpCall(ctx context.Context, input CreateDocumentsRequest) (*CreateDocumentsResponse, error) {
+	return doHttpCall[CreateDocumentsResponse](ctx, db, "PUT", db.documentsUrl(), input)
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		logrus.WithError(err).Error("http request failed")
-		return nil, err
-	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			logrus.WithError(err).Error("failed to close response body")
-		}
-	}(resp.Body)
+func (db *ImmmuDB) doGetAllHttpCall(ctx context.Context, input interface{}) (*GetAllResponse, error) {
+	return doHttpCall[GetAllResponse](ctx, db, "POST", db.searchUrl, input)
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		logrus.WithError(err).Error("http response body read failed")
-		return nil, err
-	}
-	if resp.StatusCode == http.StatusOK {
-		var result CreateResponse
-		err = json.Unmarshal(body, &result)
-		if err != nil {
-			logrus.WithError(err).Error("json unmarshal failed")
-			return nil, err
-		}
-		return &result, nil
-	}
-	logrus.WithField("response", string(body)).Error("http response body read failed")
-	return nil, fmt.Errorf("http call failed with status code: %d", resp.StatusCode)
+func (db *ImmmuDB) doReplaceHttpCall(ctx context.Context, input interface{}) (*ReplaceResponse, error) {
+	return doHttpCall[ReplaceResponse](ctx, db, "POST", db.url, input)
 }
 
-func (db *ImmmuDB) doGetAllHttpCall(ctx context.Context, input interface{}) (*GetAllResponse, error) {
+func (db *ImmmuDB) doAuditHttpCall(ctx context.Context, documentId string, input interface{}) (*GetAllResponse, error) {
+	return doHttpCall[GetAllResponse](ctx, db, "POST", db.documentUrl(documentId, "audit"), input)
+}
+
+// documentsUrl is the bulk document endpoint, the search url is the same path with /search at the end
+func (db *ImmmuDB) documentsUrl() string {
+	return strings.TrimSuffix(strings.TrimSuffix(db.searchUrl, "/"), "/search")
+}
+
+// documentUrl builds the url of a single document endpoint from the configured document url
+func (db *ImmmuDB) documentUrl(documentId string, action string) string {
+	return fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(db.url, "/"), url.PathEscape(documentId), action)
+}
+
+func doHttpCall[T any](ctx context.Context, db *ImmmuDB, method, endpoint string, input interface{}) (*T, error) {
 	// usually this needs to be taken from the configurations but for this sample application i am leaving it here
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 	jsonData, err := json.Marshal(input)
 	if err != nil {
@@ -100,7 +106,7 @@ func (db *ImmmuDB) doGetAllHttpCall(ctx context.Context, input interface{}) (*Ge
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", db.searchUrl, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
 		logrus.WithError(err).Error("http request failed")
 		return nil, err
@@ -108,6 +114,7 @@ func (db *ImmmuDB) doGetAllHttpCall(ctx context.Context, input interface{}) (*Ge
 	for key, value := range db.DefaultHeaders {
 		req.Header.Set(key, value)
 	}
+
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
@@ -127,8 +134,11 @@ func (db *ImmmuDB) doGetAllHttpCall(ctx context.Context, input interface{}) (*Ge
 		return nil, err
 	}
 	if resp.StatusCode == http.StatusOK {
-		var result GetAllResponse
-		err = json.Unmarshal(body, &result)
+		var result T
+		// numbers are kept as json.Number, account ids don't fit in a float64
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		err = decoder.Decode(&result)
 		if err != nil {
 			logrus.WithError(err).Error("json unmarshal failed")
 			return nil, err
@@ -136,14 +146,23 @@ func (db *ImmmuDB) doGetAllHttpCall(ctx context.Context, input interface{}) (*Ge
 		return &result, nil
 	}
 	logrus.WithField("response", string(body)).Error("http response body read failed")
-	return nil, fmt.Errorf("http call failed with status code: %d", resp.StatusCode)
+	return nil, &vaultStatusError{StatusCode: resp.StatusCode}
+}
+
+// vaultStatusError is returned when the vault answers with anything but 200
+type vaultStatusError struct {
+	StatusCode int
+}
+
+func (e *vaultStatusError) Error() string {
+	return fmt.Sprintf("http call failed with status code: %d", e.StatusCode)
 }
 
 func (db *ImmmuDB) CreateAccountInfo(ctx context.Context, data models.AccountInfo) (*models.AccountInfo, error) {
 	data.Id = db.GetId()
 	db.mx.Lock()
 	defer db.mx.Unlock()
-	result, err := db.doCreateHttpCall(ctx, data)
+	result, err := db.doCreateHttpCall(ctx, vaultDocument{AccountInfo: data, Revision: 1})
 	if err != nil {
 		return nil, err
 	}
@@ -154,9 +173,49 @@ func (db *ImmmuDB) CreateAccountInfo(ctx context.Context, data models.AccountInf
 	if id == 0 {
 		return nil, errors.New("invalid transaction-id")
 	}
+	// a new document always starts at its first revision
+	data.DocumentId = result.DocumentID
+	data.Meta = &models.DocumentMeta{Revision: 1, TransactionId: id, Timestamp: time.Now().UTC()}
 	return &data, nil
 }
 
+// CreateAccountInfos writes up to maxDocumentsPerCall accounts per vault call, every call is one transaction.
+// A failed call stops the batch, the accounts of the calls before it are returned with the error.
+func (db *ImmmuDB) CreateAccountInfos(ctx context.Context, data []models.AccountInfo) ([]*models.AccountInfo, error) {
+	db.mx.Lock()
+	defer db.mx.Unlock()
+	output := make([]*models.AccountInfo, 0, len(data))
+	for start := 0; start < len(data); start += maxDocumentsPerCall {
+		chunk := make([]models.AccountInfo, min(maxDocumentsPerCall, len(data)-start))
+		copy(chunk, data[start:])
+		request := CreateDocumentsRequest{Documents: make([]interface{}, 0, len(chunk))}
+		for i := range chunk {
+			db.Id++
+			chunk[i].Id = db.Id
+			request.Documents = append(request.Documents, vaultDocument{AccountInfo: chunk[i], Revision: 1})
+		}
+		result, err := db.doCreateDocumentsHttpCall(ctx, request)
+		if err != nil {
+			return output, err
+		}
+		id, err := strconv.ParseUint(result.TransactionID, 10, 64)
+		if err != nil {
+			return output, err
+		}
+		if id == 0 || len(result.DocumentIDs) != len(chunk) {
+			return output, fmt.Errorf("invalid bulk create response, %d document ids for %d documents in transaction %d", len(result.DocumentIDs), len(chunk), id)
+		}
+		timestamp := time.Now().UTC()
+		for i := range chunk {
+			created := chunk[i]
+			created.DocumentId = result.DocumentIDs[i]
+			created.Meta = &models.DocumentMeta{Revision: 1, TransactionId: id, Timestamp: timestamp}
+			output = append(output, &created)
+		}
+	}
+	return output, nil
+}
+
 func (db *ImmmuDB) GetAllAccountInfos(ctx context.Context, pageNr, pageSize int) ([]*models.AccountInfo, error) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -175,7 +234,7 @@ func (db *ImmmuDB) GetAllAccountInfos(ctx context.Context, pageNr, pageSize int)
 	var output []*models.AccountInfo
 	if result != nil && result.Revisions != nil {
 		for _, value := range result.Revisions {
-			tmp := db.convertModelToAccountInfo(value.Document)
+			tmp := db.convertRevisionToAccountInfo(value)
 			if tmp != nil {
 				output = append(output, tmp)
 			}
@@ -186,35 +245,338 @@ func (db *ImmmuDB) GetAllAccountInfos(ctx context.Context, pageNr, pageSize int)
 }
 
 func (db *ImmmuDB) GetAccountInfoById(ctx context.Context, Id uint) (*models.AccountInfo, error) {
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-	// check if we have any cancellation before continuing
+	revision, err := db.searchById(ctx, Id)
+	if err != nil {
+		return nil, err
+	}
+	return db.withRevision(ctx, *revision)
+}
+
+// withRevision converts a search result, the search doesn't report the revision and documents written before
+// they carried their own revision only have it in the audit log
+func (db *ImmmuDB) withRevision(ctx context.Context, revision Revisions) (*models.AccountInfo, error) {
+	account := db.convertRevisionToAccountInfo(revision)
+	if account == nil || account.Meta.Revision != 0 || account.DocumentId == "" {
+		return account, nil
+	}
+	latest, err := db.GetAccountInfoByDocumentId(ctx, account.DocumentId)
+	if err != nil {
+		return nil, err
+	}
+	account.Meta = latest.Meta
+	return account, nil
+}
+
+// GetAccountInfoByDocumentId loads the latest revision straight from the document, without a search
+func (db *ImmmuDB) GetAccountInfoByDocumentId(ctx context.Context, documentId string) (*models.AccountInfo, error) {
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	default:
 	}
-	requestData := GetAllRequest{
-		Query: Query{
-			Expressions: []Expression{
-				{
-					FieldComparisons: []FieldComparison{
-						{
-							Field:    "id",
-							Operator: "EQ",
-							Value:    Id,
-						},
+	result, err := db.doAuditHttpCall(ctx, documentId, AuditRequest{Desc: true, Page: 1, PerPage: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Revisions) == 0 {
+		return nil, cerror.NewServiceError("account info not found", http.StatusNotFound)
+	}
+	return db.convertRevisionToAccountInfo(result.Revisions[0]), nil
+}
+
+func (db *ImmmuDB) GetAccountInfoRevisions(ctx context.Context, documentId string) ([]*models.AccountInfo, error) {
+	var output []*models.AccountInfo
+	for page := 1; page <= maxRevisionPages; page++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		result, err := db.doAuditHttpCall(ctx, documentId, AuditRequest{Desc: false, Page: page, PerPage: revisionPageSize})
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range result.Revisions {
+			tmp := db.convertRevisionToAccountInfo(value)
+			if tmp != nil {
+				output = append(output, tmp)
+			}
+		}
+		if len(result.Revisions) < revisionPageSize {
+			break
+		}
+	}
+	if len(output) == 0 {
+		return nil, cerror.NewServiceError("account info not found", http.StatusNotFound)
+	}
+	return output, nil
+}
+
+// ChangesSince the vault has no change feed and no transaction ids in its search. The documents are searched in the
+// order of their last change, ledger time then id, and that position is the cursor, so a call stops as soon as it has
+// limit revisions. The search brings the latest revision of every document, only a document with more than one
+// revision since the cursor needs the audit log, read newest first until the cursor. A document changed again while
+// a consumer pages moves behind the cursor and its revisions since the cursor come again, the feed is at least once.
+func (db *ImmmuDB) ChangesSince(ctx context.Context, cursor ChangeCursor, limit int) ([]Change, error) {
+	settled := db.settled()
+	since := settled
+	var output []Change
+	var after *Revisions
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		result, err := db.doGetAllHttpCall(ctx, GetAllRequest{Query: changedQuery(cursor, after), Page: 1, PerPage: revisionPageSize})
+		if err != nil {
+			return nil, err
+		}
+		for _, latest := range result.Revisions {
+			// a document written before it carried its revision gets it from the audit log
+			current, err := db.withRevision(ctx, latest)
+			if err != nil {
+				return nil, err
+			}
+			if current == nil || current.DocumentId == "" {
+				continue
+			}
+			if current.Meta.Timestamp.After(settled) {
+				// a write of the same second could still get a position before this one, the document is read once
+				// it settled and the feed can't be settled past the revisions it has not handed out yet
+				if current.Meta.Revision <= 1 {
+					continue
+				}
+				unread, err := db.auditSince(ctx, current.DocumentId, 0, cursor.Since)
+				if err != nil {
+					return nil, err
+				}
+				if len(unread) > 0 && !unread[0].Meta.Timestamp.After(since) {
+					since = unread[0].Meta.Timestamp.Add(-time.Second)
+				}
+				continue
+			}
+			changes, err := db.revisionsSince(ctx, cursor, current, limit-len(output))
+			if err != nil {
+				return nil, err
+			}
+			output = append(output, changes...)
+			if len(output) == limit {
+				return output, nil
+			}
+		}
+		if len(result.Revisions) < revisionPageSize {
+			break
+		}
+		after = &result.Revisions[len(result.Revisions)-1]
+	}
+	// every settled document was read, the next call only needs the revisions after since
+	if len(output) > 0 && cursor.Since.Before(since) {
+		output[len(output)-1].Cursor.Since = since
+	}
+	return output, nil
+}
+
+// Head is the position of the document changed last, documents changed in the last seconds are not settled yet
+func (db *ImmmuDB) Head(ctx context.Context) (ChangeCursor, error) {
+	settled := db.settled()
+	query := Query{
+		Expressions: []Expression{{FieldComparisons: []FieldComparison{{Field: "_vault_md.ts", Operator: "LE", Value: settled.Unix()}}}},
+		OrderBy:     []OrderBy{{Field: "_vault_md.ts", Desc: true}, {Field: "_id", Desc: true}},
+	}
+	result, err := db.doGetAllHttpCall(ctx, GetAllRequest{Query: query, Page: 1, PerPage: 1})
+	if err != nil || len(result.Revisions) == 0 {
+		return ChangeCursor{Since: settled}, err
+	}
+	last := db.convertRevisionToAccountInfo(result.Revisions[0])
+	if last == nil {
+		return ChangeCursor{}, fmt.Errorf("the last changed document is not an account")
+	}
+	return ChangeCursor{DocumentId: last.DocumentId, Timestamp: last.Meta.Timestamp, Revision: last.Meta.Revision, Since: settled}, nil
+}
+
+// settled is the last second no write can land in anymore, the vault time has a resolution of seconds
+func (db *ImmmuDB) settled() time.Time {
+	return time.Unix(db.now().Add(-changeSettleTime).Unix(), 0).UTC()
+}
+
+// changedQuery searches the documents from the position of the cursor on, after the given one when paging.
+// Documents of the same second are told apart by their id.
+func changedQuery(cursor ChangeCursor, after *Revisions) Query {
+	query := Query{OrderBy: []OrderBy{{Field: "_vault_md.ts"}, {Field: "_id"}}}
+	if after != nil {
+		var ts int
+		if md := vaultMdOf(after.Document); md != nil {
+			ts = md.Ts
+		}
+		query.Expressions = positionExpressions(int64(ts), documentIdOf(after.Document), "GT")
+	} else if cursor.DocumentId != "" {
+		// the document of the cursor is searched again, it can have revisions after the cursor
+		query.Expressions = positionExpressions(cursor.Timestamp.Unix(), cursor.DocumentId, "GE")
+	} else if !cursor.Timestamp.IsZero() {
+		query.Expressions = []Expression{
+			{FieldComparisons: []FieldComparison{{Field: "_vault_md.ts", Operator: "GE", Value: cursor.Timestamp.Unix()}}},
+		}
+	}
+	return query
+}
+
+// positionExpressions matches the documents after the position, with the given operator on the id in the same second
+func positionExpressions(ts int64, documentId string, idOperator string) []Expression {
+	return []Expression{
+		{FieldComparisons: []FieldComparison{{Field: "_vault_md.ts", Operator: "GT", Value: ts}}},
+		{FieldComparisons: []FieldComparison{
+			{Field: "_vault_md.ts", Operator: "EQ", Value: ts},
+			{Field: "_id", Operator: idOperator, Value: documentId},
+		}},
+	}
+}
+
+// revisionsSince returns at most limit revisions of the document after the cursor, oldest first, current is its latest revision.
+// The document of the cursor continues after the revision of the cursor, every other one after the time the cursor settled at.
+func (db *ImmmuDB) revisionsSince(ctx context.Context, cursor ChangeCursor, current *models.AccountInfo, limit int) ([]Change, error) {
+	position := ChangeCursor{DocumentId: current.DocumentId, Timestamp: current.Meta.Timestamp, Since: cursor.Since}
+	var seen uint64
+	if position.DocumentId == cursor.DocumentId && position.Timestamp.Equal(cursor.Timestamp) {
+		seen = cursor.Revision
+	}
+	if current.Meta.Revision <= seen || !current.Meta.Timestamp.After(cursor.Since) {
+		return nil, nil
+	}
+	revisions := []*models.AccountInfo{current}
+	if current.Meta.Revision > seen+1 {
+		var err error
+		revisions, err = db.auditSince(ctx, current.DocumentId, seen, cursor.Since)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(revisions) > limit {
+		revisions = revisions[:limit]
+	}
+	output := make([]Change, 0, len(revisions))
+	for _, revision := range revisions {
+		position.Revision = revision.Meta.Revision
+		output = append(output, Change{Cursor: position, Revision: revision})
+	}
+	return output, nil
+}
+
+// auditSince reads the revisions of the document after the given one and after since, oldest first
+func (db *ImmmuDB) auditSince(ctx context.Context, documentId string, seen uint64, since time.Time) ([]*models.AccountInfo, error) {
+	var output []*models.AccountInfo
+	for page := 1; ; page++ {
+		if page > maxRevisionPages {
+			return nil, fmt.Errorf("document %s has more than %d revisions since the cursor", documentId, maxRevisionPages*revisionPageSize)
+		}
+		result, err := db.doAuditHttpCall(ctx, documentId, AuditRequest{Desc: true, Page: page, PerPage: revisionPageSize})
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range result.Revisions {
+			revision := db.convertRevisionToAccountInfo(value)
+			if revision == nil {
+				continue
+			}
+			if revision.Meta.Revision <= seen || !revision.Meta.Timestamp.After(since) {
+				slices.Reverse(output)
+				return output, nil
+			}
+			output = append(output, revision)
+		}
+		if len(result.Revisions) < revisionPageSize {
+			slices.Reverse(output)
+			return output, nil
+		}
+	}
+}
+
+// UpdateAccountInfo replaces the account with a new revision if nobody changed it since expectedRevision.
+// Every stored document carries its revision, the replace only matches the expected one, so a concurrent
+// update from any instance makes it fail with 412 instead of being overwritten.
+func (db *ImmmuDB) UpdateAccountInfo(ctx context.Context, Id uint, expectedRevision uint64, data models.AccountInfo) (*models.AccountInfo, error) {
+	revision, err := db.searchById(ctx, Id)
+	if err != nil {
+		return nil, err
+	}
+	current, err := db.withRevision(ctx, *revision)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil || current.Meta.Revision != expectedRevision {
+		return nil, errModified
+	}
+
+	query := idQuery(Id)
+	if _, ok := storedRevisionOf(revision.Document); ok {
+		query.Expressions[0].FieldComparisons = append(query.Expressions[0].FieldComparisons, FieldComparison{
+			Field:    revisionField,
+			Operator: "EQ",
+			Value:    expectedRevision,
+		})
+	} else {
+		// written before documents carried their revision, this one update relies on the check above
+		logrus.WithField("id", Id).Warn("updating a document without a stored revision, the update is not conditional")
+	}
+	data.Id = Id
+	data.DocumentId = ""
+	result, err := db.doReplaceHttpCall(ctx, ReplaceRequest{Query: query, Document: vaultDocument{AccountInfo: data, Revision: expectedRevision + 1}})
+	var statusErr *vaultStatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		// the revision changed between the search and the replace
+		return nil, errModified
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Revisions) == 0 {
+		return nil, errModified
+	}
+	data.DocumentId = result.Revisions[0].DocumentID
+	data.Meta = &models.DocumentMeta{Timestamp: time.Now().UTC()}
+	data.Meta.Revision, _ = strconv.ParseUint(result.Revisions[0].Revision, 10, 64)
+	if data.Meta.Revision == 0 {
+		// the replace matched the expected revision, so the new one is the next
+		data.Meta.Revision = expectedRevision + 1
+	}
+	data.Meta.TransactionId, _ = strconv.ParseUint(result.Revisions[0].TransactionID, 10, 64)
+	return &data, nil
+}
+
+func idQuery(Id uint) Query {
+	return Query{
+		Expressions: []Expression{
+			{
+				FieldComparisons: []FieldComparison{
+					{
+						Field:    "id",
+						Operator: "EQ",
+						Value:    Id,
 					},
 				},
 			},
-			Limit: 0,
-			OrderBy: []OrderBy{
-				{
-					Desc:  true,
-					Field: "id",
-				},
+		},
+		Limit: 0,
+		OrderBy: []OrderBy{
+			{
+				Desc:  true,
+				Field: "id",
 			},
 		},
+	}
+}
+
+func (db *ImmmuDB) searchById(ctx context.Context, Id uint) (*Revisions, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// check if we have any cancellation before continuing
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	requestData := GetAllRequest{
+		Query:   idQuery(Id),
 		Page:    1,
 		PerPage: 1,
 	}
@@ -224,7 +586,7 @@ func (db *ImmmuDB) GetAccountInfoById(ctx context.Context, Id uint) (*models.Acc
 	}
 
 	if result != nil && len(result.Revisions) > 0 {
-		return db.convertModelToAccountInfo(result.Revisions[0].Document), nil
+		return &result.Revisions[0], nil
 	}
 	// normally this doesn't need to have acesss to this but i am leaving it for simplity
 	return nil, cerror.NewServiceError("account info not found", http.StatusNotFound)
@@ -251,3 +613,66 @@ func (db *ImmmuDB) convertModelToAccountInfo(sc interface{}) *models.AccountInfo
 	}
 	return &result
 }
+
+// convertRevisionToAccountInfo converts the document and keeps the ledger metadata of the revision
+func (db *ImmmuDB) convertRevisionToAccountInfo(revision Revisions) *models.AccountInfo {
+	result := db.convertModelToAccountInfo(revision.Document)
+	if result == nil {
+		return nil
+	}
+	// the search leaves revision and transaction empty, only the audit log reports them,
+	// the revision is also kept in the document so it is known either way
+	meta := &models.DocumentMeta{}
+	meta.Revision, _ = strconv.ParseUint(revision.Revision, 10, 64)
+	if meta.Revision == 0 {
+		meta.Revision, _ = storedRevisionOf(revision.Document)
+	}
+	meta.TransactionId, _ = strconv.ParseUint(revision.TransactionID, 10, 64)
+	if md := vaultMdOf(revision.Document); md != nil {
+		meta.Timestamp = time.Unix(int64(md.Ts), 0).UTC()
+		meta.Creator = md.Creator
+	}
+	result.Meta = meta
+	return result
+}
+
+func vaultMdOf(document interface{}) *VaultMd {
+	fields, ok := document.(map[string]interface{})
+	if !ok || fields["_vault_md"] == nil {
+		return nil
+	}
+	jsonString, err := json.Marshal(fields["_vault_md"])
+	if err != nil {
+		return nil
+	}
+	var result VaultMd
+	err = json.Unmarshal(jsonString, &result)
+	if err != nil {
+		return nil
+	}
+	return &result
+}
+
+// storedRevisionOf reads the revision kept in the document, documents written before it was kept have none
+func storedRevisionOf(document interface{}) (uint64, bool) {
+	fields, ok := document.(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	value, ok := fields[revisionField].(json.Number)
+	if !ok {
+		return 0, false
+	}
+	revision, err := strconv.ParseUint(value.String(), 10, 64)
+	return revision, err == nil
+}
+
+// documentIdOf reads the id the vault assigned to a document
+func documentIdOf(document interface{}) string {
+	fields, ok := document.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	id, _ := fields["_id"].(string)
+	return id
+}
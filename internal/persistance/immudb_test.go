@@ -2,14 +2,61 @@ package persistance
 
 import (
 	"context"
+	"fmt"
 	"github.com/stretchr/testify/assert"
+	cerror "immudb/internal/errors"
 	"immudb/internal/models"
+	"immudb/internal/vaultsim"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
+const testApiKey = "test-key"
+
+// newSimulatedImmuDB points the client at an in process vault, so every test goes through the real http path
+func newSimulatedImmuDB(t *testing.T) (*ImmmuDB, *vaultsim.Server) {
+	sim := vaultsim.NewServer(testApiKey)
+	ts := httptest.NewServer(sim)
+	t.Cleanup(ts.Close)
+	db := NewImmmuDB(vaultsim.DocumentUrl(ts.URL), testApiKey, vaultsim.SearchUrl(ts.URL))
+	db.now = settledNow
+	return db, sim
+}
+
+func createAccounts(t *testing.T, db *ImmmuDB, names ...string) []*models.AccountInfo {
+	var output []*models.AccountInfo
+	for _, name := range names {
+		created, err := db.CreateAccountInfo(context.Background(), models.AccountInfo{
+			Name: name,
+			Iban: "iban " + name,
+			Type: AddPointer(models.Sending),
+		})
+		assert.NoError(t, err, "failed to create account")
+		output = append(output, created)
+	}
+	return output
+}
+
+// withoutVaultMeta drops what the vault reports depending on the call: the receipt timestamp is local and the stored one
+// comes from the vault, the transaction is only known to a write and the audit log, the search leaves it empty
+func withoutVaultMeta(infos ...*models.AccountInfo) []*models.AccountInfo {
+	output := make([]*models.AccountInfo, 0, len(infos))
+	for _, info := range infos {
+		result := *info
+		meta := *info.Meta
+		meta.Timestamp = time.Time{}
+		meta.Creator = ""
+		meta.TransactionId = 0
+		result.Meta = &meta
+		output = append(output, &result)
+	}
+	return output
+}
 func TestImmmuDB_GetId(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -71,18 +118,13 @@ func TestImmmuDB_GetId(t *testing.T) {
 func TestImmmuDB_CreateAccountInfo(t *testing.T) {
 	tests := []struct {
 		name               string
-		ImmuReturnResponse string
-		ImmuReturnStatus   int
+		failWith           int
 		request            models.AccountInfo
 		expectedResult     models.AccountInfo
+		expectedErrorCount int
 	}{
 		{
-			name:             "Test_Validity_Sending_Type",
-			ImmuReturnStatus: http.StatusOK,
-			ImmuReturnResponse: `{
-									"documentId": "66af5b390000000000000007f0afc792",
-									"transactionId": "8"
-			}`,
+			name: "Test_Validity_Sending_Type",
 			request: models.AccountInfo{
 				Name:    "test",
 				Iban:    "testIban",
@@ -99,12 +141,7 @@ func TestImmmuDB_CreateAccountInfo(t *testing.T) {
 			},
 		},
 		{
-			name:             "Test_Validity_Receiving_Type",
-			ImmuReturnStatus: http.StatusOK,
-			ImmuReturnResponse: `{
-									"documentId": "66af5b390000000000000007f0afc792",
-									"transactionId": "8"
-			}`,
+			name: "Test_Validity_Receiving_Type",
 			request: models.AccountInfo{
 				Name:    "test",
 				Iban:    "testIban",
@@ -118,205 +155,650 @@ func TestImmmuDB_CreateAccountInfo(t *testing.T) {
 				Type:    AddPointer(models.Receiving),
 			},
 		},
+		{
+			name:     "Test_Vault_Unavailable",
+			failWith: http.StatusServiceUnavailable,
+			request:  models.AccountInfo{Name: "test"},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// setup
-
-			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				w.WriteHeader(tt.ImmuReturnStatus)
-				_, err := w.Write([]byte(tt.ImmuReturnResponse))
-				if err != nil {
-					t.Error(err, "failed to write response")
-				}
-			}))
-			defer ts.Close()
-			db := NewImmmuDB(ts.URL, "", "")
+			db, sim := newSimulatedImmuDB(t)
+			if tt.failWith != 0 {
+				sim.FailNext(tt.failWith)
+			}
 
 			// action
 			result, err := db.CreateAccountInfo(context.Background(), tt.request)
-			if err != nil {
-				t.Error(err)
+
+			// assertions
+			if tt.failWith != 0 {
+				assert.EqualError(t, err, "http call failed with status code: 503")
+				return
 			}
+			assert.NoError(t, err)
 			// bc this is set on db level
 			tt.expectedResult.Id = result.Id
-
-			// assertions
+			assert.NotEmpty(t, result.DocumentId, "document id not returned in the receipt")
+			assert.Equal(t, uint64(1), result.Meta.Revision, "a new document starts at revision 1")
+			assert.Equal(t, uint64(1), result.Meta.TransactionId, "transaction id not returned in the receipt")
+			assert.False(t, result.Meta.Timestamp.IsZero(), "timestamp not returned in the receipt")
+			tt.expectedResult.DocumentId = result.DocumentId
+			tt.expectedResult.Meta = result.Meta
 			assert.Equal(t, &tt.expectedResult, result, "invalid result returned from CreateAccountInfo")
 		})
 	}
 }
 
 func TestImmmuDB_GetById(t *testing.T) {
+	// setup
+	db, _ := newSimulatedImmuDB(t)
+	created := createAccounts(t, db, "first", "second", "third")
+
 	tests := []struct {
-		name               string
-		ImmuReturnResponse string
-		ImmuReturnStatus   int
-		requestId          uint
-		expectedResult     models.AccountInfo
+		name           string
+		requestId      uint
+		expectedError  error
+		expectedResult *models.AccountInfo
 	}{
 		{
-			name:             "Test_Validity_GetById",
-			ImmuReturnStatus: http.StatusOK,
-			ImmuReturnResponse: `{
-									"page": 1,
-									"perPage": 100,
-									"revisions": [
-										{
-											"document": {
-												"Address": "1234 Elm Street, Springfield, USA",
-												"Amount": 1500.75,
-												"Iban": "GB82WEST12345698765432",
-												"Type": 1,
-												"_id": "66ae4d420000000000000004f0afc78f",
-												"_vault_md": {
-													"creator": "a:14ffea9d-4313-465e-9f61-ed4f4097ca87",
-													"ts": 1722699074
-												},
-												"id": 1722699072077830000,
-												"name": "John Doe 22"
-											},
-											"revision": "",
-											"transactionId": ""
-										}
-									],
-									"searchId": ""
-			}`,
-			requestId: 1722699072077830000,
-			expectedResult: models.AccountInfo{
-				Id:      1722699072077830000,
-				Name:    "John Doe 22",
-				Iban:    "GB82WEST12345698765432",
-				Address: AddPointer("1234 Elm Street, Springfield, USA"),
-				Amount:  1500.75,
-				Type:    AddPointer(models.Sending),
-			},
+			name:           "Test_Validity_GetById",
+			requestId:      created[1].Id,
+			expectedResult: created[1],
+		},
+		{
+			// ids are generated from the clock, neighbours differ below float64 precision
+			name:           "Test_Neighbour_Ids_Are_Not_Mixed_Up",
+			requestId:      created[2].Id,
+			expectedResult: created[2],
+		},
+		{
+			name:          "Test_Not_Found",
+			requestId:     created[2].Id + 1,
+			expectedError: cerror.NewServiceError("account info not found", http.StatusNotFound),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// setup
+			// action
+			result, err := db.GetAccountInfoById(context.Background(), tt.requestId)
 
+			// assertions
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "a:vaultsim", result.Meta.Creator)
+			assert.Equal(t, withoutVaultMeta(tt.expectedResult), withoutVaultMeta(result), "invalid result returned from getById")
+		})
+	}
+}
+
+func TestImmmuDB_GetById_VaultSearchResponse(t *testing.T) {
+	// the real vault search leaves revision and transactionId empty, the revision is either stored in the
+	// document or, for documents written before it was stored, only in the audit log
+	tests := []struct {
+		name             string
+		document         string
+		expectedRevision uint64
+		expectedAudits   int
+	}{
+		{
+			name:             "Test_Stored_Revision",
+			document:         `{"_id": "66af5b390000000000000007f0afc792", "_vault_md": {"creator": "a:vault", "ts": 1722768185}, "id": 7, "account_name": "test", "revision": 3}`,
+			expectedRevision: 3,
+		},
+		{
+			name:             "Test_Legacy_Document_Without_Revision",
+			document:         `{"_id": "66af5b390000000000000007f0afc792", "_vault_md": {"creator": "a:vault", "ts": 1722768185}, "id": 7, "account_name": "test"}`,
+			expectedRevision: 2,
+			expectedAudits:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			audits := 0
 			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				// here basically we can also check the request sent to the server but i want to keep this a little simple
-				w.WriteHeader(tt.ImmuReturnStatus)
-				_, err := w.Write([]byte(tt.ImmuReturnResponse))
-				if err != nil {
-					t.Error(err, "failed to write response")
+				if strings.HasSuffix(r.URL.Path, "/audit") {
+					audits++
+					_, _ = w.Write([]byte(`{"revisions": [{"document": ` + tt.document + `, "revision": "2", "transactionId": "9"}], "page": 1, "perPage": 1}`))
+					return
 				}
+				_, _ = w.Write([]byte(`{"revisions": [{"document": ` + tt.document + `, "revision": "", "transactionId": ""}], "searchId": "", "page": 1, "perPage": 100}`))
 			}))
-			defer ts.Close()
-			db := NewImmmuDB("", "", ts.URL)
+			t.Cleanup(ts.Close)
+			db := NewImmmuDB(ts.URL+"/document", testApiKey, ts.URL+"/documents/search")
 
 			// action
-			result, err := db.GetAccountInfoById(context.Background(), tt.requestId)
-			if err != nil {
-				t.Error(err)
-			}
+			result, err := db.GetAccountInfoById(context.Background(), 7)
 
 			// assertions
-			assert.Equal(t, &tt.expectedResult, result, "invalid result returned from getById")
+			assert.NoError(t, err)
+			assert.Equal(t, "66af5b390000000000000007f0afc792", result.DocumentId)
+			assert.Equal(t, tt.expectedRevision, result.Meta.Revision)
+			assert.Equal(t, tt.expectedAudits, audits)
 		})
 	}
 }
 
+func TestImmmuDB_GetAll_VaultSearchResponse(t *testing.T) {
+	// setup
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{
+						"page": 1,
+						"perPage": 10,
+						"revisions": [
+							{
+								"document": {
+									"Address": "1234 Elm Street, Springfield, USA",
+									"Amount": 1500.75,
+									"Iban": "GB82WEST12345698765432",
+									"Type": 1,
+									"_id": "66ae5d610000000000000005f0afc790",
+									"_vault_md": {
+										"creator": "a:14ffea9d-4313-465e-9f61-ed4f4097ca87",
+										"ts": 1722703201
+									},
+									"id": 1722703201251299000,
+									"name": "John Doe 22",
+									"revision": 3
+								},
+								"revision": "",
+								"transactionId": ""
+							},
+							{
+								"document": {
+									"Address": null,
+									"Amount": 1,
+									"Iban": "aa",
+									"Type": 1,
+									"_id": "66ae9a240000000000000006f0afc791",
+									"_vault_md": {
+										"creator": "a:14ffea9d-4313-465e-9f61-ed4f4097ca87",
+										"ts": 1722718756
+									},
+									"id": 1722718756365078000,
+									"name": "test"
+								},
+								"revision": "",
+								"transactionId": ""
+							}
+						],
+						"searchId": ""
+		}`))
+	}))
+	t.Cleanup(ts.Close)
+	db := NewImmmuDB("", "", ts.URL)
+
+	// action
+	result, err := db.GetAllAccountInfos(context.Background(), 1, 10)
+
+	// assertions
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.AccountInfo{
+		{
+			Id:         1722703201251299000,
+			DocumentId: "66ae5d610000000000000005f0afc790",
+			Name:       "John Doe 22",
+			Iban:       "GB82WEST12345698765432",
+			Address:    AddPointer("1234 Elm Street, Springfield, USA"),
+			Amount:     1500.75,
+			Type:       AddPointer(models.Sending),
+			Meta:       &models.DocumentMeta{Revision: 3, Timestamp: time.Unix(1722703201, 0).UTC(), Creator: "a:14ffea9d-4313-465e-9f61-ed4f4097ca87"},
+		},
+		{
+			Id:         1722718756365078000,
+			DocumentId: "66ae9a240000000000000006f0afc791",
+			Name:       "test",
+			Iban:       "aa",
+			Amount:     1,
+			Type:       AddPointer(models.Sending),
+			Meta:       &models.DocumentMeta{Timestamp: time.Unix(1722718756, 0).UTC(), Creator: "a:14ffea9d-4313-465e-9f61-ed4f4097ca87"},
+		},
+	}, result, "the revision comes from the document, the search reports no transaction")
+}
+
 func TestImmmuDB_GetAll(t *testing.T) {
+	// setup
+	db, _ := newSimulatedImmuDB(t)
+	created := createAccounts(t, db, "first", "second", "third")
+
 	tests := []struct {
-		name               string
-		ImmuReturnResponse string
-		ImmuReturnStatus   int
-		expectedResult     []*models.AccountInfo
+		name            string
+		page            int
+		pageSize        int
+		isErrorExpected bool
+		expectedResult  []*models.AccountInfo
 	}{
 		{
-			name:             "Test_Validity_GetAll",
-			ImmuReturnStatus: http.StatusOK,
-			ImmuReturnResponse: `{
-							"page": 1,
-							"perPage": 10,
-							"revisions": [
-								{
-									"document": {
-										"Address": "1234 Elm Street, Springfield, USA",
-										"Amount": 1500.75,
-										"Iban": "GB82WEST12345698765432",
-										"Type": 1,
-										"_id": "66ae5d610000000000000005f0afc790",
-										"_vault_md": {
-											"creator": "a:14ffea9d-4313-465e-9f61-ed4f4097ca87",
-											"ts": 1722703201
-										},
-										"id": 1722703201251299000,
-										"name": "John Doe 22"
-									},
-									"revision": "",
-									"transactionId": ""
-								},
-								{
-									"document": {
-										"Address": null,
-										"Amount": 1,
-										"Iban": "aa",
-										"Type": 1,
-										"_id": "66ae9a240000000000000006f0afc791",
-										"_vault_md": {
-											"creator": "a:14ffea9d-4313-465e-9f61-ed4f4097ca87",
-											"ts": 1722718756
-										},
-										"id": 1722718756365078000,
-										"name": "test"
-									},
-									"revision": "",
-									"transactionId": ""
-								}
-							],
-							"searchId": ""
-			}`,
-			expectedResult: []*models.AccountInfo{
-				&models.AccountInfo{
-					Id:      1722703201251299000,
-					Name:    "John Doe 22",
-					Iban:    "GB82WEST12345698765432",
-					Address: AddPointer("1234 Elm Street, Springfield, USA"),
-					Amount:  1500.75,
-					Type:    AddPointer(models.Sending),
-				},
-				&models.AccountInfo{
-					Id:     1722718756365078000,
-					Name:   "test",
-					Iban:   "aa",
-					Amount: 1,
-					Type:   AddPointer(models.Sending),
-				},
-			},
+			name:           "Test_Validity_GetAll",
+			page:           1,
+			pageSize:       10,
+			expectedResult: created,
+		},
+		{
+			name:           "Test_Second_Page",
+			page:           2,
+			pageSize:       2,
+			expectedResult: created[2:],
+		},
+		{
+			name:           "Test_Past_The_End",
+			page:           3,
+			pageSize:       2,
+			expectedResult: nil,
+		},
+		{
+			name:            "Test_Page_Size_Rejected_By_The_Vault",
+			page:            1,
+			pageSize:        1000,
+			isErrorExpected: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// setup
+			// action
+			result, err := db.GetAllAccountInfos(context.Background(), tt.page, tt.pageSize)
 
-			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				// here basically we can also check the request sent to the server but i want to keep this a little simple
-				w.WriteHeader(tt.ImmuReturnStatus)
-				_, err := w.Write([]byte(tt.ImmuReturnResponse))
-				if err != nil {
-					t.Error(err, "failed to write response")
-				}
-			}))
-			defer ts.Close()
-			db := NewImmmuDB("", "", ts.URL)
+			// assertions
+			if tt.isErrorExpected {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, len(tt.expectedResult), len(result), "invalid length returned by getAllAccountInfos")
+			if len(tt.expectedResult) > 0 {
+				assert.Equal(t, withoutVaultMeta(tt.expectedResult...), withoutVaultMeta(result...), "invalid result returned from getAll")
+			}
+		})
+	}
+}
+
+func TestImmmuDB_GetByDocumentId(t *testing.T) {
+	// setup
+	db, _ := newSimulatedImmuDB(t)
+	created := createAccounts(t, db, "first", "second")
+	updated, err := db.UpdateAccountInfo(context.Background(), created[0].Id, 1, models.AccountInfo{Name: "updated", Iban: "aa"})
+	assert.NoError(t, err)
+
+	// action
+	result, err := db.GetAccountInfoByDocumentId(context.Background(), created[0].DocumentId)
+	_, notFoundErr := db.GetAccountInfoByDocumentId(context.Background(), "unknown")
+
+	// assertions
+	assert.NoError(t, err)
+	assert.Equal(t, withoutVaultMeta(updated), withoutVaultMeta(result), "the latest revision must be returned")
+	assert.Error(t, notFoundErr)
+}
+
+func TestImmmuDB_GetAccountInfoRevisions(t *testing.T) {
+	// setup
+	db, _ := newSimulatedImmuDB(t)
+	created := createAccounts(t, db, "first")
+	second, err := db.UpdateAccountInfo(context.Background(), created[0].Id, 1, models.AccountInfo{Name: "second", Iban: "aa"})
+	assert.NoError(t, err)
+	third, err := db.UpdateAccountInfo(context.Background(), created[0].Id, 2, models.AccountInfo{Name: "third", Iban: "aa"})
+	assert.NoError(t, err)
+
+	// action
+	result, err := db.GetAccountInfoRevisions(context.Background(), created[0].DocumentId)
+
+	// assertions
+	assert.NoError(t, err)
+	assert.Equal(t, withoutVaultMeta(created[0], second, third), withoutVaultMeta(result...), "revisions must be returned oldest first")
+}
+
+func TestImmmuDB_UpdateAccountInfo(t *testing.T) {
+	tests := []struct {
+		name             string
+		expectedRevision uint64
+		expectedError    error
+	}{
+		{
+			name:             "Test_Validity",
+			expectedRevision: 1,
+		},
+		{
+			name:             "Test_Stale_Revision",
+			expectedRevision: 2,
+			expectedError:    cerror.NewServiceError("account info was modified, reload it and apply your changes again", http.StatusPreconditionFailed),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			db, _ := newSimulatedImmuDB(t)
+			created := createAccounts(t, db, "first", "second")
 
 			// action
-			result, err := db.GetAllAccountInfos(context.Background(), 1, 10)
-			if err != nil {
-				t.Error(err)
+			result, err := db.UpdateAccountInfo(context.Background(), created[0].Id, tt.expectedRevision, models.AccountInfo{
+				Name:   "updated",
+				Iban:   "aa",
+				Amount: 2,
+				Type:   AddPointer(models.Receiving),
+			})
+			stored, getErr := db.GetAccountInfoById(context.Background(), created[0].Id)
+			assert.NoError(t, getErr)
+
+			// assertions
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, err)
+				assert.Equal(t, withoutVaultMeta(created[0]), withoutVaultMeta(stored), "a stale update must not reach the vault")
+				return
 			}
+			assert.NoError(t, err)
+			assert.Equal(t, withoutVaultMeta(&models.AccountInfo{
+				Id:         created[0].Id,
+				DocumentId: created[0].DocumentId,
+				Name:       "updated",
+				Iban:       "aa",
+				Amount:     2,
+				Type:       AddPointer(models.Receiving),
+				Meta:       &models.DocumentMeta{Revision: 2},
+			}), withoutVaultMeta(result))
+			assert.Equal(t, uint64(3), result.Meta.TransactionId, "transaction id not returned by the replace")
+			assert.Equal(t, withoutVaultMeta(result), withoutVaultMeta(stored))
+		})
+	}
+}
+
+func TestImmmuDB_UpdateAccountInfo_AnotherInstance(t *testing.T) {
+	// setup
+	sim := vaultsim.NewServer(testApiKey)
+	direct := httptest.NewServer(sim)
+	t.Cleanup(direct.Close)
+	other := NewImmmuDB(vaultsim.DocumentUrl(direct.URL), testApiKey, vaultsim.SearchUrl(direct.URL))
+	var once sync.Once
+	var otherErr error
+	// the other instance updates the account between our revision check and our replace
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == vaultsim.DocumentPath {
+			once.Do(func() {
+				_, otherErr = other.UpdateAccountInfo(context.Background(), 1, 1, models.AccountInfo{Name: "other"})
+			})
+		}
+		sim.ServeHTTP(w, r)
+	}))
+	t.Cleanup(proxy.Close)
+	db := NewImmmuDB(vaultsim.DocumentUrl(proxy.URL), testApiKey, vaultsim.SearchUrl(proxy.URL))
+	db.Id = 0
+	created := createAccounts(t, db, "first")
+
+	// action
+	_, err := db.UpdateAccountInfo(context.Background(), created[0].Id, 1, models.AccountInfo{Name: "ours"})
+
+	// assertions
+	assert.NoError(t, otherErr)
+	assert.Equal(t, cerror.NewServiceError("account info was modified, reload it and apply your changes again", http.StatusPreconditionFailed), err)
+	stored, err := db.GetAccountInfoById(context.Background(), created[0].Id)
+	assert.NoError(t, err)
+	assert.Equal(t, "other", stored.Name, "the update of the other instance must not be overwritten")
+	assert.Equal(t, uint64(2), stored.Meta.Revision)
+}
+
+func TestImmmuDB_ChangesSince(t *testing.T) {
+	// setup
+	db, _ := newSimulatedImmuDB(t)
+	created := createAccounts(t, db, "first", "second", "third")
+	updated, err := db.UpdateAccountInfo(context.Background(), created[0].Id, 1, models.AccountInfo{Name: "first updated", Iban: "aa"})
+	assert.NoError(t, err)
+
+	// action
+	all, err := db.ChangesSince(context.Background(), ChangeCursor{}, 10)
+	assert.NoError(t, err)
+	limited, err := db.ChangesSince(context.Background(), all[0].Cursor, 2)
+	assert.NoError(t, err)
+	after, err := db.ChangesSince(context.Background(), all[len(all)-1].Cursor, 10)
+	assert.NoError(t, err)
+
+	// assertions
+	assert.ElementsMatch(t, withoutVaultMeta(created[0], created[1], created[2], updated), withoutVaultMeta(revisionsOf(all)...), "every revision must be returned")
+	assertFeedOrder(t, all)
+	assert.Equal(t, withoutVaultMeta(revisionsOf(all[1:3])...), withoutVaultMeta(revisionsOf(limited)...))
+	assert.Equal(t, all[len(all)-1].Cursor.Timestamp, all[len(all)-1].Cursor.Since, "the end of the feed must settle the cursor")
+	assert.Empty(t, after)
+}
+
+func TestImmmuDB_ChangesSince_LegacyDocument(t *testing.T) {
+	// setup
+	sim := vaultsim.NewServer(testApiKey)
+	ts := httptest.NewServer(sim)
+	t.Cleanup(ts.Close)
+	db := NewImmmuDB(vaultsim.DocumentUrl(ts.URL), testApiKey, vaultsim.SearchUrl(ts.URL))
+	db.now = settledNow
+	// written before documents carried their revision
+	request, err := http.NewRequest(http.MethodPut, vaultsim.DocumentUrl(ts.URL), strings.NewReader(`{"id": 5, "name": "legacy"}`))
+	assert.NoError(t, err)
+	request.Header.Set("X-API-Key", testApiKey)
+	response, err := ts.Client().Do(request)
+	assert.NoError(t, err)
+	_ = response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	// action
+	changes, err := db.ChangesSince(context.Background(), ChangeCursor{}, 10)
+
+	// assertions
+	assert.NoError(t, err)
+	assert.Len(t, changes, 1, "a document without a stored revision must not be left out of the feed")
+	assert.Equal(t, "legacy", changes[0].Revision.Name)
+	assert.Equal(t, uint64(1), changes[0].Revision.Meta.Revision, "the revision comes from the audit log")
+}
+
+func TestImmmuDB_ChangesSince_Paged(t *testing.T) {
+	// setup
+	sim := vaultsim.NewServer(testApiKey)
+	audits := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/audit") {
+			audits++
+		}
+		sim.ServeHTTP(w, r)
+	}))
+	t.Cleanup(ts.Close)
+	db := NewImmmuDB(vaultsim.DocumentUrl(ts.URL), testApiKey, vaultsim.SearchUrl(ts.URL))
+	db.now = settledNow
+	data := make([]models.AccountInfo, revisionPageSize+revisionPageSize/2)
+	for i := range data {
+		data[i] = models.AccountInfo{Name: fmt.Sprint("account ", i)}
+	}
+	created, err := db.CreateAccountInfos(context.Background(), data)
+	assert.NoError(t, err)
+	updated, err := db.UpdateAccountInfo(context.Background(), created[0].Id, 1, models.AccountInfo{Name: "updated"})
+	assert.NoError(t, err)
+	again, err := db.UpdateAccountInfo(context.Background(), created[0].Id, 2, models.AccountInfo{Name: "updated again"})
+	assert.NoError(t, err)
+	audits = 0
+
+	// action
+	var paged []Change
+	cursor := ChangeCursor{}
+	for calls := 0; calls < len(created); calls++ {
+		page, err := db.ChangesSince(context.Background(), cursor, 7)
+		assert.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+		paged = append(paged, page...)
+		cursor = page[len(page)-1].Cursor
+	}
+
+	// assertions
+	assert.ElementsMatch(t, withoutVaultMeta(append(created, updated, again)...), withoutVaultMeta(revisionsOf(paged)...), "every revision must be read once, a page can end inside a transaction or a document")
+	assertFeedOrder(t, paged)
+	assert.LessOrEqual(t, audits, 2, "only the document with more than one revision since the cursor needs the audit log")
+}
+
+func TestImmmuDB_ChangesSince_UpdatedWhilePaging(t *testing.T) {
+	// setup
+	db, _ := newSimulatedImmuDB(t)
+	created := createAccounts(t, db, "first", "second", "third")
+	first, err := db.ChangesSince(context.Background(), ChangeCursor{}, 1)
+	assert.NoError(t, err)
+	updated, err := db.UpdateAccountInfo(context.Background(), first[0].Revision.Id, 1, models.AccountInfo{Name: "updated"})
+	assert.NoError(t, err)
+
+	// action
+	rest, err := db.ChangesSince(context.Background(), first[0].Cursor, 10)
+	assert.NoError(t, err)
+
+	// assertions
+	assert.Len(t, created, 3)
+	assert.Contains(t, withoutVaultMeta(revisionsOf(rest)...), withoutVaultMeta(updated)[0], "a document updated behind the cursor must come again")
+	assertFeedOrder(t, rest)
+}
+
+func TestImmmuDB_ChangesSince_Settle(t *testing.T) {
+	// setup
+	sim := vaultsim.NewServer(testApiKey)
+	ts := httptest.NewServer(sim)
+	t.Cleanup(ts.Close)
+	var clock atomic.Int64
+	clock.Store(1722768000)
+	now := func() time.Time { return time.Unix(clock.Load(), 0) }
+	sim.SetClock(now)
+	db := NewImmmuDB(vaultsim.DocumentUrl(ts.URL), testApiKey, vaultsim.SearchUrl(ts.URL))
+	db.now = now
+	created := createAccounts(t, db, "first", "second")
+
+	// action
+	unsettled, err := db.ChangesSince(context.Background(), ChangeCursor{}, 10)
+	assert.NoError(t, err)
+	clock.Add(int64(changeSettleTime / time.Second))
+	head, headErr := db.Head(context.Background())
+	// every document of the head second changes again, one of them has an id before the head
+	updated := make([]*models.AccountInfo, 0, len(created))
+	for _, account := range created {
+		result, err := db.UpdateAccountInfo(context.Background(), account.Id, 1, models.AccountInfo{Name: "updated"})
+		assert.NoError(t, err)
+		updated = append(updated, result)
+	}
+	beforeSettled, err := db.ChangesSince(context.Background(), head, 10)
+	assert.NoError(t, err)
+	clock.Add(int64(changeSettleTime / time.Second))
+	sinceHead, err := db.ChangesSince(context.Background(), head, 10)
+	assert.NoError(t, err)
+
+	// assertions
+	assert.Empty(t, unsettled, "the documents of the last seconds are not settled yet")
+	assert.NoError(t, headErr)
+	assert.Equal(t, time.Unix(1722768000, 0).UTC(), head.Timestamp)
+	assert.Equal(t, uint64(1), head.Revision)
+	assert.Empty(t, beforeSettled)
+	assert.ElementsMatch(t, withoutVaultMeta(updated...), withoutVaultMeta(revisionsOf(sinceHead)...), "only what changed after the head must be returned")
+}
+
+func TestImmmuDB_ChangesSince_UpdatedBeforeSettled(t *testing.T) {
+	// setup
+	sim := vaultsim.NewServer(testApiKey)
+	ts := httptest.NewServer(sim)
+	t.Cleanup(ts.Close)
+	var clock atomic.Int64
+	clock.Store(1722768000)
+	now := func() time.Time { return time.Unix(clock.Load(), 0) }
+	sim.SetClock(now)
+	db := NewImmmuDB(vaultsim.DocumentUrl(ts.URL), testApiKey, vaultsim.SearchUrl(ts.URL))
+	db.now = now
+	first := createAccounts(t, db, "first")
+	clock.Add(1)
+	second := createAccounts(t, db, "second")
+	clock.Add(1)
+	escaping := createAccounts(t, db, "escaping")
+	clock.Add(2)
+	page, err := db.ChangesSince(context.Background(), ChangeCursor{}, 1)
+	assert.NoError(t, err)
+	// updated before the feed got to it, its first revision is older than the second that settled
+	updated, err := db.UpdateAccountInfo(context.Background(), escaping[0].Id, 1, models.AccountInfo{Name: "escaping updated"})
+	assert.NoError(t, err)
+
+	// action
+	next, err := db.ChangesSince(context.Background(), page[0].Cursor, 10)
+	assert.NoError(t, err)
+	clock.Add(2)
+	rest, err := db.ChangesSince(context.Background(), next[len(next)-1].Cursor, 10)
+	assert.NoError(t, err)
+
+	// assertions
+	assert.Equal(t, withoutVaultMeta(first...), withoutVaultMeta(revisionsOf(page)...))
+	assert.Equal(t, withoutVaultMeta(second...), withoutVaultMeta(revisionsOf(next)...), "the document changed in the last seconds must wait")
+	assert.Equal(t, withoutVaultMeta(escaping[0], updated), withoutVaultMeta(revisionsOf(rest)...), "no revision of a document that was not settled may be lost")
+}
+
+// settledNow lets the change feed read the documents that were just written
+func settledNow() time.Time {
+	return time.Now().Add(changeSettleTime)
+}
+
+func revisionsOf(changes []Change) []*models.AccountInfo {
+	output := make([]*models.AccountInfo, 0, len(changes))
+	for _, change := range changes {
+		output = append(output, change.Revision)
+	}
+	return output
+}
+
+// assertFeedOrder every change must come after the one before it, the revisions of a document oldest first
+func assertFeedOrder(t *testing.T, changes []Change) {
+	for i := 1; i < len(changes); i++ {
+		assert.True(t, changes[i-1].Cursor.Before(changes[i].Cursor), "change %d is out of order", i)
+	}
+}
+
+func TestImmmuDB_CreateAccountInfos(t *testing.T) {
+	tests := []struct {
+		name            string
+		failures        []int
+		isErrorExpected bool
+		expectedCreated int
+	}{
+		{
+			name:            "Test_Validity",
+			expectedCreated: 150,
+		},
+		{
+			name:            "Test_Second_Call_Fails",
+			failures:        []int{0, http.StatusServiceUnavailable},
+			isErrorExpected: true,
+			expectedCreated: maxDocumentsPerCall,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			db, sim := newSimulatedImmuDB(t)
+			sim.FailNext(tt.failures...)
+			accounts := make([]models.AccountInfo, 150)
+			for i := range accounts {
+				accounts[i] = models.AccountInfo{Name: fmt.Sprintf("account %d", i), Iban: "aa", Type: AddPointer(models.Sending)}
+			}
+
+			// action
+			created, err := db.CreateAccountInfos(context.Background(), accounts)
 
 			// assertions
-			assert.Equal(t, len(tt.expectedResult), len(result), "invalid length returned by getAllAccountInfos")
-			assert.Equal(t, tt.expectedResult, result, "invalid result returned from getAll")
+			if tt.isErrorExpected {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Len(t, created, tt.expectedCreated)
+			assert.Zero(t, accounts[0].Id, "the input must not be changed")
+			for i, account := range created {
+				assert.Equal(t, accounts[i].Name, account.Name, "accounts must be returned in input order")
+				stored, err := db.GetAccountInfoById(context.Background(), account.Id)
+				assert.NoError(t, err)
+				assert.Equal(t, account.DocumentId, stored.DocumentId)
+			}
+			assert.Equal(t, created[0].Meta.TransactionId, created[maxDocumentsPerCall-1].Meta.TransactionId, "one call is one transaction")
 		})
 	}
 }
@@ -0,0 +1,208 @@
+package persistance
+
+import (
+	"context"
+	"fmt"
+	cerror "immudb/internal/errors"
+	"immudb/internal/models"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const memoryCreator = "memory"
+
+// MemoryDB keeps every revision of every account in process memory, it is meant for local development
+// and tests so nothing survives a restart
+type MemoryDB struct {
+	mx            sync.RWMutex
+	Id            uint
+	transactionId uint64
+	documents     map[string][]*models.AccountInfo // revisions of every document, oldest first
+	byId          map[uint]string                  // account number to document id
+	order         []string                         // document ids in creation order
+}
+
+func NewMemoryDB() *MemoryDB {
+	return &MemoryDB{
+		Id:        uint(time.Now().UnixNano()),
+		documents: map[string][]*models.AccountInfo{},
+		byId:      map[uint]string{},
+	}
+}
+
+func (db *MemoryDB) CreateAccountInfo(ctx context.Context, data models.AccountInfo) (*models.AccountInfo, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	db.mx.Lock()
+	defer db.mx.Unlock()
+	db.Id++
+	db.transactionId++
+	data.Id = db.Id
+	data.DocumentId = fmt.Sprintf("%032x", db.transactionId)
+	data.Meta = &models.DocumentMeta{Revision: 1, TransactionId: db.transactionId, Timestamp: time.Now().UTC(), Creator: memoryCreator}
+
+	db.documents[data.DocumentId] = []*models.AccountInfo{copyAccountInfo(&data)}
+	db.byId[data.Id] = data.DocumentId
+	db.order = append(db.order, data.DocumentId)
+	return copyAccountInfo(&data), nil
+}
+
+// GetAllAccountInfos returns the latest revision of the accounts in the order they were created, pages start at 1
+func (db *MemoryDB) GetAllAccountInfos(ctx context.Context, pageNr, pageSize int) ([]*models.AccountInfo, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	if pageNr < 1 {
+		pageNr = 1
+	}
+	db.mx.RLock()
+	defer db.mx.RUnlock()
+	start := (pageNr - 1) * pageSize
+	if pageSize <= 0 || start >= len(db.order) {
+		return nil, nil
+	}
+	end := min(start+pageSize, len(db.order))
+	output := make([]*models.AccountInfo, 0, end-start)
+	for _, documentId := range db.order[start:end] {
+		output = append(output, db.latest(documentId))
+	}
+	return output, nil
+}
+
+func (db *MemoryDB) GetAccountInfoById(ctx context.Context, Id uint) (*models.AccountInfo, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	db.mx.RLock()
+	defer db.mx.RUnlock()
+	documentId, ok := db.byId[Id]
+	if !ok {
+		return nil, cerror.NewServiceError("account info not found", http.StatusNotFound)
+	}
+	return db.latest(documentId), nil
+}
+
+func (db *MemoryDB) GetAccountInfoByDocumentId(ctx context.Context, documentId string) (*models.AccountInfo, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	db.mx.RLock()
+	defer db.mx.RUnlock()
+	if _, ok := db.documents[documentId]; !ok {
+		return nil, cerror.NewServiceError("account info not found", http.StatusNotFound)
+	}
+	return db.latest(documentId), nil
+}
+
+func (db *MemoryDB) GetAccountInfoRevisions(ctx context.Context, documentId string) ([]*models.AccountInfo, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	db.mx.RLock()
+	defer db.mx.RUnlock()
+	revisions, ok := db.documents[documentId]
+	if !ok {
+		return nil, cerror.NewServiceError("account info not found", http.StatusNotFound)
+	}
+	output := make([]*models.AccountInfo, 0, len(revisions))
+	for _, revision := range revisions {
+		output = append(output, copyAccountInfo(revision))
+	}
+	return output, nil
+}
+
+// UpdateAccountInfo appends a new revision, unlike the vault the revision check here is atomic
+func (db *MemoryDB) UpdateAccountInfo(ctx context.Context, Id uint, expectedRevision uint64, data models.AccountInfo) (*models.AccountInfo, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	db.mx.Lock()
+	defer db.mx.Unlock()
+	documentId, ok := db.byId[Id]
+	if !ok {
+		return nil, cerror.NewServiceError("account info not found", http.StatusNotFound)
+	}
+	revisions := db.documents[documentId]
+	current := revisions[len(revisions)-1]
+	if current.Meta.Revision != expectedRevision {
+		return nil, cerror.NewServiceError("account info was modified, reload it and apply your changes again", http.StatusPreconditionFailed)
+	}
+
+	db.transactionId++
+	data.Id = Id
+	data.DocumentId = documentId
+	data.Meta = &models.DocumentMeta{Revision: current.Meta.Revision + 1, TransactionId: db.transactionId, Timestamp: time.Now().UTC(), Creator: memoryCreator}
+	db.documents[documentId] = append(revisions, copyAccountInfo(&data))
+	return copyAccountInfo(&data), nil
+}
+
+func (db *MemoryDB) ChangesSince(ctx context.Context, cursor ChangeCursor, limit int) ([]Change, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	db.mx.RLock()
+	defer db.mx.RUnlock()
+	var output []*models.AccountInfo
+	for _, revisions := range db.documents {
+		for _, revision := range revisions {
+			if cursor.Before(CursorOf(revision)) {
+				output = append(output, copyAccountInfo(revision))
+			}
+		}
+	}
+	sortChanges(output)
+	if len(output) > limit {
+		output = output[:limit]
+	}
+	return changesOf(output), nil
+}
+
+func (db *MemoryDB) Head(ctx context.Context) (ChangeCursor, error) {
+	db.mx.RLock()
+	defer db.mx.RUnlock()
+	var head ChangeCursor
+	for _, revisions := range db.documents {
+		if last := CursorOf(revisions[len(revisions)-1]); head.Before(last) {
+			head = last
+		}
+	}
+	return head, nil
+}
+
+// latest must be called with the lock held
+func (db *MemoryDB) latest(documentId string) *models.AccountInfo {
+	revisions := db.documents[documentId]
+	return copyAccountInfo(revisions[len(revisions)-1])
+}
+
+// copyAccountInfo makes sure callers can't change what is stored through the pointers of the model
+func copyAccountInfo(info *models.AccountInfo) *models.AccountInfo {
+	result := *info
+	if info.Address != nil {
+		result.Address = AddPointer(*info.Address)
+	}
+	if info.Type != nil {
+		result.Type = AddPointer(*info.Type)
+	}
+	if info.Meta != nil {
+		meta := *info.Meta
+		result.Meta = &meta
+	}
+	return &result
+}
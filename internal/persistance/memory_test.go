@@ -0,0 +1,139 @@
+package persistance
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	cerror "immudb/internal/errors"
+	"immudb/internal/models"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestMemoryDB_CreateAndGet(t *testing.T) {
+	// setup
+	db := NewMemoryDB()
+	ctx := context.Background()
+
+	// action
+	created, err := db.CreateAccountInfo(ctx, models.AccountInfo{Name: "test", Iban: "aa", Address: AddPointer("street"), Type: AddPointer(models.Sending)})
+	assert.NoError(t, err)
+	byId, err := db.GetAccountInfoById(ctx, created.Id)
+	assert.NoError(t, err)
+	byDocument, err := db.GetAccountInfoByDocumentId(ctx, created.DocumentId)
+	assert.NoError(t, err)
+
+	// assertions
+	assert.NotEmpty(t, created.DocumentId)
+	assert.Equal(t, uint64(1), created.Meta.Revision)
+	assert.Equal(t, uint64(1), created.Meta.TransactionId)
+	assert.Equal(t, created, byId)
+	assert.Equal(t, created, byDocument)
+
+	// changing what we got back must not change what is stored
+	*byId.Address = "changed"
+	byId.Meta.Revision = 5
+	stored, err := db.GetAccountInfoById(ctx, created.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, created, stored)
+}
+
+func TestMemoryDB_NotFound(t *testing.T) {
+	// setup
+	db := NewMemoryDB()
+	notFound := cerror.NewServiceError("account info not found", http.StatusNotFound)
+
+	// action
+	_, byIdErr := db.GetAccountInfoById(context.Background(), 1)
+	_, byDocumentErr := db.GetAccountInfoByDocumentId(context.Background(), "doc1")
+	_, revisionsErr := db.GetAccountInfoRevisions(context.Background(), "doc1")
+
+	// assertions
+	assert.Equal(t, notFound, byIdErr)
+	assert.Equal(t, notFound, byDocumentErr)
+	assert.Equal(t, notFound, revisionsErr)
+}
+
+func TestMemoryDB_GetAll(t *testing.T) {
+	// setup
+	db := NewMemoryDB()
+	var created []*models.AccountInfo
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		info, err := db.CreateAccountInfo(context.Background(), models.AccountInfo{Name: name})
+		assert.NoError(t, err)
+		created = append(created, info)
+	}
+	tests := []struct {
+		name           string
+		page           int
+		pageSize       int
+		expectedResult []*models.AccountInfo
+	}{
+		{name: "Test_First_Page", page: 1, pageSize: 2, expectedResult: created[0:2]},
+		{name: "Test_Last_Page", page: 3, pageSize: 2, expectedResult: created[4:5]},
+		{name: "Test_Past_The_End", page: 4, pageSize: 2, expectedResult: nil},
+		{name: "Test_Page_Zero", page: 0, pageSize: 10, expectedResult: created},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// action
+			result, err := db.GetAllAccountInfos(context.Background(), tt.page, tt.pageSize)
+
+			// assertions
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResult, result)
+		})
+	}
+}
+
+func TestMemoryDB_Update(t *testing.T) {
+	// setup
+	db := NewMemoryDB()
+	ctx := context.Background()
+	created, err := db.CreateAccountInfo(ctx, models.AccountInfo{Name: "before"})
+	assert.NoError(t, err)
+
+	// action
+	updated, err := db.UpdateAccountInfo(ctx, created.Id, 1, models.AccountInfo{Name: "after"})
+	assert.NoError(t, err)
+	_, staleErr := db.UpdateAccountInfo(ctx, created.Id, 1, models.AccountInfo{Name: "stale"})
+	revisions, err := db.GetAccountInfoRevisions(ctx, created.DocumentId)
+	assert.NoError(t, err)
+
+	// assertions
+	assert.Equal(t, created.Id, updated.Id)
+	assert.Equal(t, created.DocumentId, updated.DocumentId)
+	assert.Equal(t, uint64(2), updated.Meta.Revision)
+	assert.Equal(t, uint64(2), updated.Meta.TransactionId)
+	assert.Equal(t, cerror.NewServiceError("account info was modified, reload it and apply your changes again", http.StatusPreconditionFailed), staleErr)
+	assert.Equal(t, []*models.AccountInfo{created, updated}, revisions)
+}
+
+func TestMemoryDB_ParallelCreate(t *testing.T) {
+	// setup
+	db := NewMemoryDB()
+	wg := sync.WaitGroup{}
+	numberOfJobs := 100
+
+	// action
+	for i := 0; i < numberOfJobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := db.CreateAccountInfo(context.Background(), models.AccountInfo{Name: "test"})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	// assertions
+	result, err := db.GetAllAccountInfos(context.Background(), 1, numberOfJobs*2)
+	assert.NoError(t, err)
+	assert.Len(t, result, numberOfJobs)
+	ids := make(map[uint]bool)
+	for _, info := range result {
+		assert.False(t, ids[info.Id], "the same id was assigned more than once")
+		ids[info.Id] = true
+	}
+}
@@ -1,9 +1,38 @@
 package persistance
 
+import "time"
+
 type CreateResponse struct {
 	DocumentID    string `json:"documentId"`
 	TransactionID string `json:"transactionId"`
 }
+
+// CreateDocumentsRequest creates every document in one transaction
+type CreateDocumentsRequest struct {
+	Documents []interface{} `json:"documents"`
+}
+
+type CreateDocumentsResponse struct {
+	DocumentIDs   []string `json:"documentIds"`
+	TransactionID string   `json:"transactionId"`
+}
+
+// ReplaceRequest replaces the documents matching the query with a new revision
+type ReplaceRequest struct {
+	Query    Query       `json:"query"`
+	Document interface{} `json:"document"`
+}
+
+type ReplaceResponse struct {
+	Revisions []ReplacedRevision `json:"revisions"`
+}
+
+type ReplacedRevision struct {
+	DocumentID    string `json:"documentId"`
+	Revision      string `json:"revision"`
+	TransactionID string `json:"transactionId"`
+}
+
 type GetAllSimpleRequest struct {
 	Page    int `json:"page"`
 	PerPage int `json:"perPage"`
@@ -15,6 +44,12 @@ type GetAllRequest struct {
 	PerPage int   `json:"perPage"`
 }
 
+type AuditRequest struct {
+	Desc    bool `json:"desc"`
+	Page    int  `json:"page"`
+	PerPage int  `json:"perPage"`
+}
+
 type FieldComparison struct {
 	Field    string      `json:"field"`
 	Operator string      `json:"operator"`
@@ -55,3 +90,40 @@ type Revisions struct {
 	Revision      string      `json:"revision"`
 	TransactionID string      `json:"transactionId"`
 }
+
+// LedgerState is a point of the file ledger, every document revision is committed in its own transaction
+// so the transaction id is also the number of leaves in the merkle tree
+type LedgerState struct {
+	TransactionID uint64 `json:"transactionId,string"`
+	RootHash      string `json:"rootHash"`
+}
+
+// DocumentProof proves a document revision is part of the file ledger and that the ledger
+// only grew since the transaction the proof was requested from. Hashes are hex encoded.
+// It is the format of our own ledger, the vault proofs look different and are not checked.
+type DocumentProof struct {
+	DocumentID       string      `json:"documentId"`
+	Revision         string      `json:"revision"`
+	TransactionID    string      `json:"transactionId"`
+	EncodedDocument  string      `json:"encodedDocument"` // base64 of the document exactly as it was hashed
+	State            LedgerState `json:"state"`
+	InclusionProof   []string    `json:"inclusionProof"`
+	ConsistencyProof []string    `json:"consistencyProof"`
+}
+
+// RevisionRef points at a committed document revision, a zero transaction id means the latest revision
+type RevisionRef struct {
+	DocumentID    string
+	TransactionID uint64
+}
+
+// ChangeCursor points at the last revision a consumer of the change feed has seen, the zero cursor is the start of the ledger.
+// One transaction can commit many documents, the feed orders them by document id so a page can end between them.
+// The vault has no transaction ids, its feed is ordered by the ledger time and the document id of the last change.
+type ChangeCursor struct {
+	TransactionId uint64
+	DocumentId    string
+	Timestamp     time.Time // the ledger time of that revision, lets the vault narrow its search
+	Revision      uint64    // the revision of the document, the vault reads the revisions of one document in this order
+	Since         time.Time // vault only, the revisions before it were all read already
+}
@@ -0,0 +1,124 @@
+package persistance
+
+import (
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"sort"
+	"sync"
+)
+
+// BackendSettings is the typed settings block of a backend, it is validated before the backend is opened
+type BackendSettings interface {
+	Validate() error
+}
+
+// SourceSettings is implemented by the settings of backends that wrap another backend,
+// the settings of the source are validated together with the wrapping ones
+type SourceSettings interface {
+	SourceBackend() string
+}
+
+// Backend opens one AccountDB implementation, it is registered under the name the configuration selects it by
+type Backend struct {
+	// NewSettings returns a pointer to the settings with their defaults, the configuration block is decoded into it
+	NewSettings func() BackendSettings
+	// Open gets the validated settings, blocks is there for backends that wrap another one
+	Open func(settings BackendSettings, blocks BackendBlocks) (AccountDB, error)
+}
+
+// BackendBlocks are the settings blocks of the configuration by backend name
+type BackendBlocks map[string]map[string]interface{}
+
+var registry = struct {
+	mx       sync.RWMutex
+	backends map[string]Backend
+}{backends: map[string]Backend{}}
+
+// Register makes a backend selectable by name, it is meant to be called from init and panics on a duplicate name
+func Register(name string, backend Backend) {
+	registry.mx.Lock()
+	defer registry.mx.Unlock()
+	if name == "" || backend.NewSettings == nil || backend.Open == nil {
+		panic("persistance: backend " + name + " is incomplete")
+	}
+	if _, ok := registry.backends[name]; ok {
+		panic("persistance: backend " + name + " is registered twice")
+	}
+	registry.backends[name] = backend
+}
+
+// Backends returns the names of the registered backends, sorted
+func Backends() []string {
+	registry.mx.RLock()
+	defer registry.mx.RUnlock()
+	names := make([]string, 0, len(registry.backends))
+	for name := range registry.backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupBackend(name string) (Backend, error) {
+	registry.mx.RLock()
+	backend, ok := registry.backends[name]
+	registry.mx.RUnlock()
+	if !ok {
+		return Backend{}, fmt.Errorf("unknown backend %q, registered backends are %v", name, Backends())
+	}
+	return backend, nil
+}
+
+// Settings decodes and validates the settings block of the backend, keys the settings don't know are an error
+func (b BackendBlocks) Settings(name string) (BackendSettings, error) {
+	return b.settings(name, map[string]bool{})
+}
+
+func (b BackendBlocks) settings(name string, visited map[string]bool) (BackendSettings, error) {
+	if visited[name] {
+		return nil, fmt.Errorf("backend %q is its own source", name)
+	}
+	visited[name] = true
+	backend, err := lookupBackend(name)
+	if err != nil {
+		return nil, err
+	}
+	settings := backend.NewSettings()
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		ErrorUnused:      true,
+		WeaklyTypedInput: true, // values from environment variables are always strings
+		Result:           settings,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = decoder.Decode(b[name])
+	if err != nil {
+		return nil, fmt.Errorf("invalid settings for the %s backend: %w", name, err)
+	}
+	err = settings.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid settings for the %s backend: %w", name, err)
+	}
+	if wrapping, ok := settings.(SourceSettings); ok {
+		_, err = b.settings(wrapping.SourceBackend(), visited)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return settings, nil
+}
+
+// Open decodes the settings of the backend and opens it
+func (b BackendBlocks) Open(name string) (AccountDB, error) {
+	settings, err := b.Settings(name)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := lookupBackend(name)
+	if err != nil {
+		return nil, err
+	}
+	return backend.Open(settings, b)
+}
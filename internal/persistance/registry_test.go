@@ -0,0 +1,107 @@
+package persistance
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type wrappingSettings struct {
+	Source string
+}
+
+func (s *wrappingSettings) Validate() error {
+	return nil
+}
+
+func (s *wrappingSettings) SourceBackend() string {
+	return s.Source
+}
+
+func init() {
+	Register("test-wrapping", Backend{
+		NewSettings: func() BackendSettings { return &wrappingSettings{} },
+		Open: func(settings BackendSettings, blocks BackendBlocks) (AccountDB, error) {
+			return blocks.Open(settings.(*wrappingSettings).Source)
+		},
+	})
+}
+
+func TestBackendBlocks_Settings(t *testing.T) {
+	tests := []struct {
+		name             string
+		backend          string
+		blocks           BackendBlocks
+		expectedError    error
+		expectedSettings BackendSettings
+	}{
+		{
+			name:    "Test_Validity",
+			backend: BackendImmudbVault,
+			blocks: BackendBlocks{BackendImmudbVault: {
+				"url":       "http://vault/document",
+				"searchurl": "http://vault/search",
+				"apikey":    "key",
+			}},
+			expectedSettings: &ImmudbVaultSettings{Url: "http://vault/document", SearchUrl: "http://vault/search", ApiKey: "key"},
+		},
+		{
+			name:             "Test_Missing_Block",
+			backend:          BackendMemory,
+			expectedSettings: &MemorySettings{},
+		},
+		{
+			name:          "Test_Unknown_Backend",
+			backend:       "nope",
+			expectedError: errors.New(`unknown backend "nope", registered backends are [file-ledger immudb-vault memory test-wrapping]`),
+		},
+		{
+			name:          "Test_Invalid_Settings",
+			backend:       BackendFileLedger,
+			blocks:        BackendBlocks{BackendFileLedger: {"TrustedStatePath": "state.json"}},
+			expectedError: errors.New("invalid settings for the file-ledger backend: Path is required"),
+		},
+		{
+			name:          "Test_Unknown_Setting",
+			backend:       BackendFileLedger,
+			blocks:        BackendBlocks{BackendFileLedger: {"Path": "ledger.log", "Paht": "typo"}},
+			expectedError: errors.New("invalid settings for the file-ledger backend: 1 error(s) decoding:\n\n* '' has invalid keys: Paht"),
+		},
+		{
+			name:          "Test_Invalid_Source",
+			backend:       "test-wrapping",
+			blocks:        BackendBlocks{"test-wrapping": {"Source": BackendFileLedger}},
+			expectedError: errors.New("invalid settings for the file-ledger backend: Path is required"),
+		},
+		{
+			name:          "Test_Source_Cycle",
+			backend:       "test-wrapping",
+			blocks:        BackendBlocks{"test-wrapping": {"Source": "test-wrapping"}},
+			expectedError: errors.New(`backend "test-wrapping" is its own source`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// action
+			settings, err := tt.blocks.Settings(tt.backend)
+
+			// assertions
+			if tt.expectedError != nil {
+				assert.EqualError(t, err, tt.expectedError.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedSettings, settings)
+		})
+	}
+}
+
+func TestBackendBlocks_Open(t *testing.T) {
+	// action
+	db, err := BackendBlocks{"test-wrapping": {"Source": BackendMemory}}.Open("test-wrapping")
+
+	// assertions
+	assert.NoError(t, err)
+	assert.IsType(t, &MemoryDB{}, db)
+}
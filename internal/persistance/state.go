@@ -0,0 +1,118 @@
+package persistance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// StateStore keeps the last ledger state we verified, proofs are always checked relative to it
+type StateStore interface {
+	// Load returns nil if no state has been trusted yet
+	Load() (*LedgerState, error)
+	Save(state LedgerState) error
+}
+
+type MemoryStateStore struct {
+	mx    sync.Mutex
+	state *LedgerState
+}
+
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{}
+}
+
+func (s *MemoryStateStore) Load() (*LedgerState, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if s.state == nil {
+		return nil, nil
+	}
+	state := *s.state
+	return &state, nil
+}
+
+func (s *MemoryStateStore) Save(state LedgerState) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.state = &state
+	return nil
+}
+
+// FileStateStore keeps the trusted state on disk so a restart can't be used to make us trust a rewritten ledger
+type FileStateStore struct {
+	mx   sync.Mutex
+	path string
+}
+
+func NewFileStateStore(path string) *FileStateStore {
+	return &FileStateStore{path: path}
+}
+
+func (s *FileStateStore) Load() (*LedgerState, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state LedgerState
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		return nil, fmt.Errorf("corrupted trusted state %s: %w", s.path, err)
+	}
+	return &state, nil
+}
+
+func (s *FileStateStore) Save(state LedgerState) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(s.path, data)
+}
+
+// WriteFileAtomic writes to a temp file next to the target and renames it over, so readers either see the old or the new content
+func WriteFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	// no-op once the rename succeeded
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return err
+	}
+	// persist the rename itself
+	dirFile, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer dirFile.Close()
+	return dirFile.Sync()
+}
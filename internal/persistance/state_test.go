@@ -0,0 +1,54 @@
+package persistance
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStateStore(t *testing.T) {
+	tests := []struct {
+		name   string
+		states []LedgerState
+	}{
+		{
+			name: "Test_No_State",
+		},
+		{
+			name:   "Test_Save_Load",
+			states: []LedgerState{{TransactionID: 3, RootHash: "aa"}},
+		},
+		{
+			name:   "Test_Overwrite",
+			states: []LedgerState{{TransactionID: 3, RootHash: "aa"}, {TransactionID: 7, RootHash: "bb"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			dir := t.TempDir()
+			path := filepath.Join(dir, "state", "trusted.json")
+			store := NewFileStateStore(path)
+
+			// action
+			for _, state := range tt.states {
+				assert.NoError(t, store.Save(state))
+			}
+			// a new store simulates a restart
+			result, err := NewFileStateStore(path).Load()
+
+			// assertions
+			assert.NoError(t, err)
+			if len(tt.states) == 0 {
+				assert.Nil(t, result)
+				return
+			}
+			assert.Equal(t, &tt.states[len(tt.states)-1], result)
+			entries, err := os.ReadDir(filepath.Dir(path))
+			assert.NoError(t, err)
+			assert.Len(t, entries, 1, "temporary files left behind")
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package persistance
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/sirupsen/logrus"
+	cerror "immudb/internal/errors"
+	"immudb/internal/merkle"
+	"immudb/internal/models"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Verifier checks ledger proofs on the client, the storage is never trusted to say a document is intact
+type Verifier struct {
+	mx    sync.Mutex
+	Store StateStore
+	alarm error
+}
+
+func NewVerifier(store StateStore) *Verifier {
+	return &Verifier{Store: store}
+}
+
+// TrustedTransactionId proofs are requested relative to this transaction, 0 means nothing is trusted yet
+func (v *Verifier) TrustedTransactionId() (uint64, error) {
+	state, err := v.Store.Load()
+	if err != nil || state == nil {
+		return 0, err
+	}
+	return state.TransactionID, nil
+}
+
+// Healthy returns the tamper alarm if one was raised, once raised it stays until the process restarts
+func (v *Verifier) Healthy() error {
+	v.mx.Lock()
+	defer v.mx.Unlock()
+	return v.alarm
+}
+
+// CheckConsistency verifies that the ledger only grew since the trusted state and trusts the new state
+func (v *Verifier) CheckConsistency(state LedgerState, consistencyProof []string) error {
+	v.mx.Lock()
+	defer v.mx.Unlock()
+	return v.advance(state, consistencyProof)
+}
+
+// VerifyDocument checks that the proven document is part of the ledger, that the ledger only grew since the
+// trusted state and that the proven document is the one we were served. The trusted state advances on success.
+func (v *Verifier) VerifyDocument(proof *DocumentProof, served *models.AccountInfo) (*models.Verification, error) {
+	if proof == nil {
+		return nil, cerror.NewVerificationError("no proof received")
+	}
+	document, err := base64.StdEncoding.DecodeString(proof.EncodedDocument)
+	if err != nil {
+		return nil, cerror.NewVerificationError("invalid encoded document")
+	}
+	transactionId, err := strconv.ParseUint(proof.TransactionID, 10, 64)
+	if err != nil || transactionId == 0 {
+		return nil, cerror.NewVerificationError("invalid transaction id %q", proof.TransactionID)
+	}
+	root, err := hex.DecodeString(proof.State.RootHash)
+	if err != nil {
+		return nil, cerror.NewVerificationError("invalid root hash")
+	}
+	inclusion, err := decodeHashes(proof.InclusionProof)
+	if err != nil {
+		return nil, cerror.NewVerificationError("invalid inclusion proof")
+	}
+	err = merkle.VerifyInclusion(transactionId-1, proof.State.TransactionID, merkle.LeafHash(document), inclusion, root)
+	if err != nil {
+		return nil, cerror.NewVerificationError("document %s is not included in transaction %d", proof.DocumentID, transactionId)
+	}
+	if served != nil && !sameDocument(document, served) {
+		return nil, cerror.NewVerificationError("served document %s does not match the proven revision", proof.DocumentID)
+	}
+
+	v.mx.Lock()
+	defer v.mx.Unlock()
+	err = v.advance(proof.State, proof.ConsistencyProof)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Verification{
+		Verified:            true,
+		DocumentId:          proof.DocumentID,
+		TransactionId:       transactionId,
+		LedgerTransactionId: proof.State.TransactionID,
+		RootHash:            proof.State.RootHash,
+		VerifiedAt:          time.Now().UTC(),
+	}, nil
+}
+
+// advance moves the trusted state forward, it must be called while holding the lock
+func (v *Verifier) advance(state LedgerState, consistencyProof []string) error {
+	trusted, err := v.Store.Load()
+	if err != nil {
+		return err
+	}
+	if trusted == nil {
+		// trust on first use, there is nothing to be consistent with yet
+		return v.Store.Save(state)
+	}
+	if state.TransactionID < trusted.TransactionID {
+		return v.raiseAlarm(*trusted, state, cerror.NewVerificationError("ledger moved backwards from transaction %d to %d", trusted.TransactionID, state.TransactionID))
+	}
+	trustedRoot, err := hex.DecodeString(trusted.RootHash)
+	if err != nil {
+		return err
+	}
+	root, err := hex.DecodeString(state.RootHash)
+	if err != nil {
+		return cerror.NewVerificationError("invalid root hash")
+	}
+	proof, err := decodeHashes(consistencyProof)
+	if err != nil {
+		return cerror.NewVerificationError("invalid consistency proof")
+	}
+	err = merkle.VerifyConsistency(trusted.TransactionID, state.TransactionID, trustedRoot, root, proof)
+	if err != nil {
+		return v.raiseAlarm(*trusted, state, cerror.NewVerificationError("ledger at transaction %d is not consistent with trusted transaction %d", state.TransactionID, trusted.TransactionID))
+	}
+	if state.TransactionID == trusted.TransactionID {
+		return nil
+	}
+	return v.Store.Save(state)
+}
+
+// raiseAlarm a forked or rewound ledger means someone rewrote history, the trusted state is kept as is
+func (v *Verifier) raiseAlarm(trusted, received LedgerState, err error) error {
+	logrus.WithFields(logrus.Fields{
+		"trustedTransactionId":  trusted.TransactionID,
+		"trustedRootHash":       trusted.RootHash,
+		"receivedTransactionId": received.TransactionID,
+		"receivedRootHash":      received.RootHash,
+	}).WithError(err).Error("TAMPER ALARM: ledger state is not consistent with the trusted state")
+	v.alarm = err
+	return err
+}
+
+func decodeHashes(values []string) ([][]byte, error) {
+	result := make([][]byte, 0, len(values))
+	for _, value := range values {
+		hash, err := hex.DecodeString(value)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, hash)
+	}
+	return result, nil
+}
+
+// sameDocument compares through json so only the persisted fields take part
+func sameDocument(document []byte, served *models.AccountInfo) bool {
+	var proven models.AccountInfo
+	if err := json.Unmarshal(document, &proven); err != nil {
+		return false
+	}
+	left, err := json.Marshal(proven)
+	if err != nil {
+		return false
+	}
+	right, err := json.Marshal(served)
+	if err != nil {
+		return false
+	}
+	return string(left) == string(right)
+}
@@ -0,0 +1,94 @@
+package readmodel
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"immudb/internal/persistance"
+	"time"
+)
+
+const defaultBatchSize = 100
+
+// ProjectionStore is where the projector writes to, Store is the SQL one
+type ProjectionStore interface {
+	Checkpoint(ctx context.Context) (persistance.ChangeCursor, error)
+	Apply(ctx context.Context, changes []persistance.Change) error
+	Reset(ctx context.Context) error
+}
+
+// Projector follows the change feed of the ledger and applies every revision to the read model,
+// the checkpoint is stored with the data so a restart resumes where the last batch ended
+type Projector struct {
+	feed      persistance.ChangeFeed
+	store     ProjectionStore
+	interval  time.Duration
+	BatchSize int
+}
+
+func NewProjector(feed persistance.ChangeFeed, store ProjectionStore, interval time.Duration) *Projector {
+	return &Projector{
+		feed:      feed,
+		store:     store,
+		interval:  interval,
+		BatchSize: defaultBatchSize,
+	}
+}
+
+// Start polls the change feed until the context is cancelled, the first poll starts right away
+func (p *Projector) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			projected, err := p.RunOnce(ctx)
+			if err != nil {
+				logrus.WithError(err).Warn("read model projection failed, it is retried on the next poll")
+			} else if projected > 0 {
+				logrus.WithField("revisions", projected).Info("read model projected")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce projects batches until the read model caught up with the ledger and returns how many revisions were applied
+func (p *Projector) RunOnce(ctx context.Context) (int, error) {
+	cursor, err := p.store.Checkpoint(ctx)
+	if err != nil {
+		return 0, err
+	}
+	projected := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return projected, ctx.Err()
+		default:
+		}
+		changes, err := p.feed.ChangesSince(ctx, cursor, p.BatchSize)
+		if err != nil {
+			return projected, err
+		}
+		err = p.store.Apply(ctx, changes)
+		if err != nil {
+			return projected, err
+		}
+		projected += len(changes)
+		if len(changes) < p.BatchSize {
+			return projected, nil
+		}
+		cursor = changes[len(changes)-1].Cursor
+	}
+}
+
+// Backfill drops what was projected so far and projects the whole ledger again
+func (p *Projector) Backfill(ctx context.Context) (int, error) {
+	err := p.store.Reset(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return p.RunOnce(ctx)
+}
@@ -0,0 +1,69 @@
+package readmodel
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"testing"
+)
+
+type memoryProjection struct {
+	checkpoint persistance.ChangeCursor
+	applied    []*models.AccountInfo
+}
+
+func (m *memoryProjection) Checkpoint(ctx context.Context) (persistance.ChangeCursor, error) {
+	return m.checkpoint, nil
+}
+
+func (m *memoryProjection) Apply(ctx context.Context, changes []persistance.Change) error {
+	for _, change := range changes {
+		m.applied = append(m.applied, change.Revision)
+		m.checkpoint = change.Cursor
+	}
+	return nil
+}
+
+func (m *memoryProjection) Reset(ctx context.Context) error {
+	m.checkpoint = persistance.ChangeCursor{}
+	m.applied = nil
+	return nil
+}
+
+func TestProjector_RunOnce(t *testing.T) {
+	// setup
+	ctx := context.Background()
+	db := persistance.NewMemoryDB()
+	for _, name := range []string{"first", "second", "third"} {
+		_, err := db.CreateAccountInfo(ctx, models.AccountInfo{Name: name})
+		assert.NoError(t, err)
+	}
+	store := &memoryProjection{}
+	projector := NewProjector(db, store, 0)
+	projector.BatchSize = 2
+
+	// action
+	projected, err := projector.RunOnce(ctx)
+
+	// assertions
+	assert.NoError(t, err)
+	assert.Equal(t, 3, projected)
+	assert.Equal(t, uint64(3), store.checkpoint.TransactionId)
+
+	// only what was written after the checkpoint is projected on the next run
+	all, err := db.GetAllAccountInfos(ctx, 1, 10)
+	assert.NoError(t, err)
+	updated, err := db.UpdateAccountInfo(ctx, all[0].Id, 1, models.AccountInfo{Name: "first updated"})
+	assert.NoError(t, err)
+	projected, err = projector.RunOnce(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, projected)
+	assert.Equal(t, updated, store.applied[3])
+
+	// a backfill starts from scratch
+	projected, err = projector.Backfill(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, projected)
+	assert.Len(t, store.applied, 4)
+}
@@ -0,0 +1,182 @@
+package readmodel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	cerror "immudb/internal/errors"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"net/http"
+	"time"
+)
+
+const BackendSQLReadThrough = "sql-readthrough"
+
+func init() {
+	persistance.Register(BackendSQLReadThrough, persistance.Backend{
+		NewSettings: func() persistance.BackendSettings {
+			return &ReadThroughSettings{ProjectorInterval: 5 * time.Second}
+		},
+		Open: openReadThrough,
+	})
+}
+
+type ReadThroughSettings struct {
+	ConnectionString  string        // go-sql-driver/mysql connection string, parseTime=True is required
+	Source            string        // the backend every write goes to and every miss is read from
+	ProjectorInterval time.Duration // how often writes of other instances are picked up
+}
+
+func (s *ReadThroughSettings) Validate() error {
+	if s.ConnectionString == "" {
+		return errors.New("ConnectionString is required")
+	}
+	if s.Source == "" {
+		return errors.New("Source is required")
+	}
+	if s.ProjectorInterval <= 0 {
+		return errors.New("ProjectorInterval must be positive")
+	}
+	return nil
+}
+
+func (s *ReadThroughSettings) SourceBackend() string {
+	return s.Source
+}
+
+func openReadThrough(settings persistance.BackendSettings, blocks persistance.BackendBlocks) (persistance.AccountDB, error) {
+	readThrough := settings.(*ReadThroughSettings)
+	source, err := blocks.Open(readThrough.Source)
+	if err != nil {
+		return nil, err
+	}
+	feed, ok := persistance.As[persistance.ChangeFeed](source)
+	if !ok {
+		return nil, fmt.Errorf("the %s backend has no change feed to project from", readThrough.Source)
+	}
+	ctx := context.Background()
+	store, err := Open(ctx, readThrough.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+	err = store.Migrate(ctx)
+	if err != nil {
+		_ = store.Close()
+		return nil, err
+	}
+	NewProjector(feed, store, readThrough.ProjectorInterval).Start(ctx)
+	return NewReadThroughDB(source, store), nil
+}
+
+type readThroughStore interface {
+	FindAccountInfo(ctx context.Context, Id uint) (*models.AccountInfo, error)
+	SearchAccountInfos(ctx context.Context, search models.AccountSearch) ([]*models.AccountInfo, error)
+	GetAccountStats(ctx context.Context) ([]models.AccountStats, error)
+	Project(ctx context.Context, revisions []*models.AccountInfo) error
+}
+
+// ReadThroughDB serves reads from the read model and writes to the source backend. Its own writes are projected
+// right away, writes of other instances show up after the next projection, an account not projected yet is read from the source.
+// What it doesn't implement itself, like the revisions or the change feed, is reached on the source through Unwrap.
+type ReadThroughDB struct {
+	source persistance.AccountDB
+	store  readThroughStore
+}
+
+func NewReadThroughDB(source persistance.AccountDB, store readThroughStore) *ReadThroughDB {
+	return &ReadThroughDB{source: source, store: store}
+}
+
+func (db *ReadThroughDB) CreateAccountInfo(ctx context.Context, data models.AccountInfo) (*models.AccountInfo, error) {
+	created, err := db.source.CreateAccountInfo(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	db.project(ctx, created)
+	return created, nil
+}
+
+// CreateAccountInfos projects the accounts like single creates, a source that can't write a batch gets them one by one
+func (db *ReadThroughDB) CreateAccountInfos(ctx context.Context, data []models.AccountInfo) ([]*models.AccountInfo, error) {
+	var created []*models.AccountInfo
+	var err error
+	if batchDB, ok := persistance.As[persistance.BatchDB](db.source); ok {
+		created, err = batchDB.CreateAccountInfos(ctx, data)
+	} else {
+		for _, account := range data {
+			var result *models.AccountInfo
+			result, err = db.source.CreateAccountInfo(ctx, account)
+			if err != nil {
+				break
+			}
+			created = append(created, result)
+		}
+	}
+	if len(created) > 0 {
+		projectErr := db.store.Project(ctx, created)
+		if projectErr != nil {
+			logrus.WithError(projectErr).WithField("accounts", len(created)).Warn("failed to project a batch, the projector will catch up")
+		}
+	}
+	return created, err
+}
+
+func (db *ReadThroughDB) GetAllAccountInfos(ctx context.Context, pageNr, pageSize int) ([]*models.AccountInfo, error) {
+	result, err := db.store.SearchAccountInfos(ctx, models.AccountSearch{Page: pageNr, PageSize: pageSize})
+	if err != nil {
+		logrus.WithError(err).Warn("read model unavailable, reading from the source")
+		return db.source.GetAllAccountInfos(ctx, pageNr, pageSize)
+	}
+	return result, nil
+}
+
+func (db *ReadThroughDB) GetAccountInfoById(ctx context.Context, Id uint) (*models.AccountInfo, error) {
+	result, err := db.store.FindAccountInfo(ctx, Id)
+	if err != nil {
+		logrus.WithError(err).Warn("read model unavailable, reading from the source")
+	}
+	if result == nil {
+		return db.source.GetAccountInfoById(ctx, Id)
+	}
+	return result, nil
+}
+
+func (db *ReadThroughDB) Unwrap() persistance.AccountDB {
+	return db.source
+}
+
+// SearchAccountInfos is the search of the service, only the read model can answer it
+func (db *ReadThroughDB) SearchAccountInfos(ctx context.Context, search models.AccountSearch) ([]*models.AccountInfo, error) {
+	return db.store.SearchAccountInfos(ctx, search)
+}
+
+func (db *ReadThroughDB) GetAccountStats(ctx context.Context) ([]models.AccountStats, error) {
+	return db.store.GetAccountStats(ctx)
+}
+
+func (db *ReadThroughDB) UpdateAccountInfo(ctx context.Context, Id uint, expectedRevision uint64, data models.AccountInfo) (*models.AccountInfo, error) {
+	updatableDB, ok := persistance.As[persistance.UpdatableDB](db.source)
+	if !ok {
+		return nil, notSupported()
+	}
+	updated, err := updatableDB.UpdateAccountInfo(ctx, Id, expectedRevision, data)
+	if err != nil {
+		return nil, err
+	}
+	db.project(ctx, updated)
+	return updated, nil
+}
+
+// project is best effort, the write is already on the ledger and the projector picks it up if this fails
+func (db *ReadThroughDB) project(ctx context.Context, revision *models.AccountInfo) {
+	err := db.store.Project(ctx, []*models.AccountInfo{revision})
+	if err != nil {
+		logrus.WithError(err).WithField("documentId", revision.DocumentId).Warn("failed to project a write, the projector will catch up")
+	}
+}
+
+func notSupported() error {
+	return cerror.NewServiceError("not supported by the source backend of the read model", http.StatusNotImplemented)
+}
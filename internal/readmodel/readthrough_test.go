@@ -0,0 +1,92 @@
+package readmodel
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"testing"
+)
+
+// fakeStore keeps the projected accounts in a map, err makes every read fail
+type fakeStore struct {
+	accounts map[uint]*models.AccountInfo
+	err      error
+}
+
+func (f *fakeStore) FindAccountInfo(ctx context.Context, Id uint) (*models.AccountInfo, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.accounts[Id], nil
+}
+
+func (f *fakeStore) SearchAccountInfos(ctx context.Context, search models.AccountSearch) ([]*models.AccountInfo, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	var output []*models.AccountInfo
+	for _, account := range f.accounts {
+		output = append(output, account)
+	}
+	return output, nil
+}
+
+func (f *fakeStore) GetAccountStats(ctx context.Context) ([]models.AccountStats, error) {
+	return nil, f.err
+}
+
+func (f *fakeStore) Project(ctx context.Context, revisions []*models.AccountInfo) error {
+	for _, revision := range revisions {
+		f.accounts[revision.Id] = revision
+	}
+	return nil
+}
+
+func TestReadThroughDB(t *testing.T) {
+	// setup
+	ctx := context.Background()
+	source := persistance.NewMemoryDB()
+	store := &fakeStore{accounts: map[uint]*models.AccountInfo{}}
+	db := NewReadThroughDB(source, store)
+	// written by another instance, not projected yet
+	other, err := source.CreateAccountInfo(ctx, models.AccountInfo{Name: "other"})
+	assert.NoError(t, err)
+
+	// action
+	created, err := db.CreateAccountInfo(ctx, models.AccountInfo{Name: "mine"})
+	assert.NoError(t, err)
+	updated, err := db.UpdateAccountInfo(ctx, created.Id, 1, models.AccountInfo{Name: "mine updated"})
+	assert.NoError(t, err)
+
+	// assertions
+	assert.Equal(t, updated, store.accounts[created.Id], "own writes must be projected right away")
+	read, err := db.GetAccountInfoById(ctx, created.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, updated, read)
+	missed, err := db.GetAccountInfoById(ctx, other.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, other, missed, "an account not projected yet must be read from the source")
+	all, err := db.GetAllAccountInfos(ctx, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.AccountInfo{updated}, all)
+
+	batch, err := db.CreateAccountInfos(ctx, []models.AccountInfo{{Name: "batch"}})
+	assert.NoError(t, err)
+	assert.Equal(t, batch[0], store.accounts[batch[0].Id], "batch writes must be projected right away")
+	history, ok := persistance.As[persistance.RevisionDB](db)
+	assert.True(t, ok, "the revisions of the source must be reachable")
+	revisions, err := history.GetAccountInfoRevisions(ctx, created.DocumentId)
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 2)
+
+	// the source takes over while the read model is down
+	store.err = errors.New("connection refused")
+	all, err = db.GetAllAccountInfos(ctx, 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, all, 3)
+	read, err = db.GetAccountInfoById(ctx, created.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, updated, read)
+}
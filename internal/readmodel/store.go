@@ -0,0 +1,322 @@
+// Package readmodel mirrors the account documents of the ledger into relational tables, so list and search
+// queries the vault can't answer are served from SQL. The ledger stays the only place accounts are written to.
+package readmodel
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	_ "github.com/go-sql-driver/mysql"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"strings"
+	"time"
+)
+
+const (
+	checkpointName = "accounts"
+	accountColumns = `SELECT a.account_number, a.document_id, a.name, a.iban, a.address, a.amount, a.type,
+		a.revision, a.transaction_id, a.updated_at, a.creator FROM accounts a`
+)
+
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS accounts (
+		account_number BIGINT UNSIGNED NOT NULL PRIMARY KEY,
+		document_id VARCHAR(64) NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		iban VARCHAR(64) NOT NULL,
+		address TEXT NULL,
+		amount DOUBLE NOT NULL,
+		type TINYINT NULL,
+		revision BIGINT UNSIGNED NOT NULL,
+		transaction_id BIGINT UNSIGNED NOT NULL,
+		updated_at DATETIME(6) NOT NULL,
+		creator VARCHAR(255) NOT NULL,
+		UNIQUE KEY accounts_document_id (document_id),
+		KEY accounts_type_amount (type, amount),
+		FULLTEXT KEY accounts_text (name, iban, address)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+	`CREATE TABLE IF NOT EXISTS account_revisions (
+		document_id VARCHAR(64) NOT NULL,
+		revision BIGINT UNSIGNED NOT NULL,
+		account_number BIGINT UNSIGNED NOT NULL,
+		transaction_id BIGINT UNSIGNED NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		iban VARCHAR(64) NOT NULL,
+		address TEXT NULL,
+		amount DOUBLE NOT NULL,
+		type TINYINT NULL,
+		created_at DATETIME(6) NOT NULL,
+		creator VARCHAR(255) NOT NULL,
+		PRIMARY KEY (document_id, revision),
+		KEY account_revisions_transaction_id (transaction_id)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+	`CREATE TABLE IF NOT EXISTS projection_checkpoint (
+		name VARCHAR(64) NOT NULL PRIMARY KEY,
+		transaction_id BIGINT UNSIGNED NOT NULL,
+		document_id VARCHAR(64) NOT NULL,
+		ledger_time DATETIME(6) NOT NULL,
+		revision BIGINT UNSIGNED NOT NULL,
+		since DATETIME(6) NULL
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+}
+
+// Store reads and writes the read model tables, the schema is written for MySQL
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Open connects with a go-sql-driver/mysql connection string, parseTime=True is required
+func Open(ctx context.Context, connectionString string) (*Store, error) {
+	db, err := sql.Open("mysql", connectionString)
+	if err != nil {
+		return nil, err
+	}
+	err = db.PingContext(ctx)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return NewStore(db), nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) Migrate(ctx context.Context) error {
+	for _, statement := range schema {
+		_, err := s.db.ExecContext(ctx, statement)
+		if err != nil {
+			return fmt.Errorf("failed to migrate the read model: %w", err)
+		}
+	}
+	return nil
+}
+
+// Checkpoint returns the last projected revision, the zero cursor when nothing was projected yet
+func (s *Store) Checkpoint(ctx context.Context) (persistance.ChangeCursor, error) {
+	var cursor persistance.ChangeCursor
+	var since sql.NullTime
+	err := s.db.QueryRowContext(ctx, "SELECT transaction_id, document_id, ledger_time, revision, since FROM projection_checkpoint WHERE name = ?", checkpointName).
+		Scan(&cursor.TransactionId, &cursor.DocumentId, &cursor.Timestamp, &cursor.Revision, &since)
+	if errors.Is(err, sql.ErrNoRows) {
+		return persistance.ChangeCursor{}, nil
+	}
+	cursor.Since = since.Time
+	return cursor, err
+}
+
+// Apply projects the changes and moves the checkpoint to the last one in the same transaction, applying a revision twice is a no-op
+func (s *Store) Apply(ctx context.Context, changes []persistance.Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	revisions := make([]*models.AccountInfo, 0, len(changes))
+	for _, change := range changes {
+		revisions = append(revisions, change.Revision)
+	}
+	return s.project(ctx, revisions, &changes[len(changes)-1].Cursor)
+}
+
+// Project applies the revisions without moving the checkpoint, for writes the projector will see again later
+func (s *Store) Project(ctx context.Context, revisions []*models.AccountInfo) error {
+	return s.project(ctx, revisions, nil)
+}
+
+func (s *Store) project(ctx context.Context, revisions []*models.AccountInfo, checkpoint *persistance.ChangeCursor) error {
+	if len(revisions) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// no-op once committed
+	defer tx.Rollback()
+
+	for _, revision := range revisions {
+		if revision.Meta == nil {
+			return fmt.Errorf("revision of account %d has no ledger metadata", revision.Id)
+		}
+		// revision 0 would never win the guarded upsert below and leave the row stale forever
+		if revision.Meta.Revision == 0 {
+			return fmt.Errorf("revision of account %d is unknown", revision.Id)
+		}
+		_, err = tx.ExecContext(ctx, `INSERT IGNORE INTO account_revisions
+			(document_id, revision, account_number, transaction_id, name, iban, address, amount, type, created_at, creator)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			revision.DocumentId, revision.Meta.Revision, uint64(revision.Id), revision.Meta.TransactionId, revision.Name, revision.Iban,
+			revision.Address, revision.Amount, accountType(revision.Type), revision.Meta.Timestamp, revision.Meta.Creator)
+		if err != nil {
+			return err
+		}
+		// an older revision never overwrites a newer one, revision has to be assigned last
+		_, err = tx.ExecContext(ctx, `INSERT INTO accounts
+			(account_number, document_id, name, iban, address, amount, type, revision, transaction_id, updated_at, creator)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE
+				name = IF(VALUES(revision) > revision, VALUES(name), name),
+				iban = IF(VALUES(revision) > revision, VALUES(iban), iban),
+				address = IF(VALUES(revision) > revision, VALUES(address), address),
+				amount = IF(VALUES(revision) > revision, VALUES(amount), amount),
+				type = IF(VALUES(revision) > revision, VALUES(type), type),
+				transaction_id = IF(VALUES(revision) > revision, VALUES(transaction_id), transaction_id),
+				updated_at = IF(VALUES(revision) > revision, VALUES(updated_at), updated_at),
+				creator = IF(VALUES(revision) > revision, VALUES(creator), creator),
+				revision = GREATEST(VALUES(revision), revision)`,
+			uint64(revision.Id), revision.DocumentId, revision.Name, revision.Iban, revision.Address, revision.Amount,
+			accountType(revision.Type), revision.Meta.Revision, revision.Meta.TransactionId, revision.Meta.Timestamp, revision.Meta.Creator)
+		if err != nil {
+			return err
+		}
+	}
+
+	if checkpoint == nil {
+		return tx.Commit()
+	}
+	since := sql.NullTime{Time: checkpoint.Since, Valid: !checkpoint.Since.IsZero()}
+	_, err = tx.ExecContext(ctx, `INSERT INTO projection_checkpoint (name, transaction_id, document_id, ledger_time, revision, since) VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE transaction_id = VALUES(transaction_id), document_id = VALUES(document_id), ledger_time = VALUES(ledger_time),
+			revision = VALUES(revision), since = VALUES(since)`,
+		checkpointName, checkpoint.TransactionId, checkpoint.DocumentId, checkpoint.Timestamp, checkpoint.Revision, since)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Reset empties the read model so it can be filled from scratch
+func (s *Store) Reset(ctx context.Context) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, table := range []string{"account_revisions", "accounts", "projection_checkpoint"} {
+		_, err = tx.ExecContext(ctx, "DELETE FROM "+table)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SearchAccountInfos full text matches are ordered by relevance, everything else by account number
+func (s *Store) SearchAccountInfos(ctx context.Context, search models.AccountSearch) ([]*models.AccountInfo, error) {
+	var conditions []string
+	var args []interface{}
+	order := "a.account_number"
+	if search.Text != "" {
+		conditions = append(conditions, "MATCH(a.name, a.iban, a.address) AGAINST (? IN NATURAL LANGUAGE MODE)")
+		args = append(args, search.Text)
+		order = "MATCH(a.name, a.iban, a.address) AGAINST (? IN NATURAL LANGUAGE MODE) DESC, a.account_number"
+	}
+	if search.Type != nil {
+		conditions = append(conditions, "a.type = ?")
+		args = append(args, int(*search.Type))
+	}
+	if search.MinAmount != nil {
+		conditions = append(conditions, "a.amount >= ?")
+		args = append(args, *search.MinAmount)
+	}
+	if search.MaxAmount != nil {
+		conditions = append(conditions, "a.amount <= ?")
+		args = append(args, *search.MaxAmount)
+	}
+	query := accountColumns
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY " + order + " LIMIT ? OFFSET ?"
+	if search.Text != "" {
+		args = append(args, search.Text)
+	}
+	args = append(args, search.PageSize, (max(search.Page, 1)-1)*search.PageSize)
+
+	return s.queryAccountInfos(ctx, query, args...)
+}
+
+// FindAccountInfo returns the projected account, nil if it was not projected yet
+func (s *Store) FindAccountInfo(ctx context.Context, Id uint) (*models.AccountInfo, error) {
+	result, err := s.queryAccountInfos(ctx, accountColumns+" WHERE a.account_number = ?", uint64(Id))
+	if err != nil || len(result) == 0 {
+		return nil, err
+	}
+	return result[0], nil
+}
+
+func (s *Store) queryAccountInfos(ctx context.Context, query string, args ...interface{}) ([]*models.AccountInfo, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var output []*models.AccountInfo
+	for rows.Next() {
+		var account models.AccountInfo
+		var accountNumber uint64
+		var address sql.NullString
+		var accountType sql.NullInt64
+		meta := &models.DocumentMeta{}
+		err = rows.Scan(&accountNumber, &account.DocumentId, &account.Name, &account.Iban, &address, &account.Amount, &accountType,
+			&meta.Revision, &meta.TransactionId, &meta.Timestamp, &meta.Creator)
+		if err != nil {
+			return nil, err
+		}
+		account.Id = uint(accountNumber)
+		if address.Valid {
+			account.Address = &address.String
+		}
+		if accountType.Valid {
+			account.Type = persistance.AddPointer(models.AccountType(accountType.Int64))
+		}
+		meta.Timestamp = meta.Timestamp.UTC()
+		account.Meta = meta
+		output = append(output, &account)
+	}
+	return output, rows.Err()
+}
+
+// GetAccountStats aggregates the accounts per type together with how often they were changed
+func (s *Store) GetAccountStats(ctx context.Context) ([]models.AccountStats, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT a.type, COUNT(*), COALESCE(SUM(r.revisions), 0),
+			COALESCE(SUM(a.amount), 0), COALESCE(AVG(a.amount), 0), MAX(a.updated_at)
+		FROM accounts a
+		LEFT JOIN (SELECT document_id, COUNT(*) AS revisions FROM account_revisions GROUP BY document_id) r
+			ON r.document_id = a.document_id
+		GROUP BY a.type
+		ORDER BY a.type`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var output []models.AccountStats
+	for rows.Next() {
+		var stats models.AccountStats
+		var accountType sql.NullInt64
+		var lastChange time.Time
+		err = rows.Scan(&accountType, &stats.Accounts, &stats.Revisions, &stats.TotalAmount, &stats.AverageAmount, &lastChange)
+		if err != nil {
+			return nil, err
+		}
+		if accountType.Valid {
+			stats.Type = persistance.AddPointer(models.AccountType(accountType.Int64))
+		}
+		stats.LastChange = lastChange.UTC()
+		output = append(output, stats)
+	}
+	return output, rows.Err()
+}
+
+func accountType(value *models.AccountType) interface{} {
+	if value == nil {
+		return nil
+	}
+	return int(*value)
+}
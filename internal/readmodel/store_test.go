@@ -0,0 +1,162 @@
+package readmodel
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"testing"
+	"time"
+)
+
+func newMockStore(t *testing.T) (*Store, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err, "failed to create the sql mock")
+	t.Cleanup(func() { _ = db.Close() })
+	return NewStore(db), mock
+}
+
+func TestStore_Checkpoint(t *testing.T) {
+	// setup
+	store, mock := newMockStore(t)
+	ledgerTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	mock.ExpectQuery("SELECT transaction_id, document_id, ledger_time, revision, since FROM projection_checkpoint").
+		WithArgs(checkpointName).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SELECT transaction_id, document_id, ledger_time, revision, since FROM projection_checkpoint").
+		WithArgs(checkpointName).
+		WillReturnRows(sqlmock.NewRows([]string{"transaction_id", "document_id", "ledger_time", "revision", "since"}).AddRow(7, "doc", ledgerTime, 2, nil))
+
+	// action
+	empty, emptyErr := store.Checkpoint(context.Background())
+	cursor, err := store.Checkpoint(context.Background())
+
+	// assertions
+	assert.NoError(t, emptyErr)
+	assert.Equal(t, persistance.ChangeCursor{}, empty, "nothing projected yet must start from the beginning")
+	assert.NoError(t, err)
+	assert.Equal(t, persistance.ChangeCursor{TransactionId: 7, DocumentId: "doc", Timestamp: ledgerTime, Revision: 2}, cursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStore_Apply(t *testing.T) {
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	revision := &models.AccountInfo{
+		Id:         12,
+		DocumentId: "doc",
+		Name:       "julian",
+		Iban:       "AL1",
+		Amount:     10,
+		Type:       persistance.AddPointer(models.Sending),
+		Meta:       &models.DocumentMeta{Revision: 2, TransactionId: 9, Timestamp: timestamp, Creator: "a:test"},
+	}
+	// the vault has no transaction ids, its position is the last change of the document
+	change := persistance.Change{
+		Cursor:   persistance.ChangeCursor{DocumentId: "doc", Timestamp: timestamp.Add(time.Second), Revision: 2, Since: timestamp},
+		Revision: revision,
+	}
+	tests := []struct {
+		name            string
+		changes         []persistance.Change
+		expectations    func(mock sqlmock.Sqlmock)
+		isErrorExpected bool
+	}{
+		{
+			name:    "Test_Validity",
+			changes: []persistance.Change{change},
+			expectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT IGNORE INTO account_revisions").
+					WithArgs("doc", uint64(2), uint64(12), uint64(9), "julian", "AL1", nil, float64(10), 1, timestamp, "a:test").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO accounts").
+					WithArgs(uint64(12), "doc", "julian", "AL1", nil, float64(10), 1, uint64(2), uint64(9), timestamp, "a:test").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO projection_checkpoint").
+					WithArgs(checkpointName, uint64(0), "doc", timestamp.Add(time.Second), uint64(2), sql.NullTime{Time: timestamp, Valid: true}).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:         "Test_Nothing_To_Apply",
+			expectations: func(mock sqlmock.Sqlmock) {},
+		},
+		{
+			name:    "Test_Missing_Metadata_Rolls_Back",
+			changes: []persistance.Change{{Revision: &models.AccountInfo{Id: 1, DocumentId: "doc"}}},
+			expectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
+			isErrorExpected: true,
+		},
+		{
+			name:    "Test_Unknown_Revision_Rolls_Back",
+			changes: []persistance.Change{{Revision: &models.AccountInfo{Id: 1, DocumentId: "doc", Meta: &models.DocumentMeta{TransactionId: 9}}}},
+			expectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
+			isErrorExpected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			store, mock := newMockStore(t)
+			tt.expectations(mock)
+
+			// action
+			err := store.Apply(context.Background(), tt.changes)
+
+			// assertions
+			if tt.isErrorExpected {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStore_SearchAccountInfos(t *testing.T) {
+	// setup
+	store, mock := newMockStore(t)
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	columns := []string{"account_number", "document_id", "name", "iban", "address", "amount", "type", "revision", "transaction_id", "updated_at", "creator"}
+	mock.ExpectQuery(`FROM accounts a WHERE MATCH\(a.name, a.iban, a.address\) AGAINST .* AND a.type = \? AND a.amount >= \? ORDER BY MATCH`).
+		WithArgs("julian", 2, float64(5), "julian", 10, 10).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(12, "doc", "julian", "AL1", "street", 10.5, 2, 3, 9, timestamp, "a:test").
+			AddRow(13, "other", "julian b", "AL2", nil, 20.0, 2, 1, 4, timestamp, "a:test"))
+
+	// action
+	result, err := store.SearchAccountInfos(context.Background(), models.AccountSearch{
+		Text:      "julian",
+		Type:      persistance.AddPointer(models.Receiving),
+		MinAmount: persistance.AddPointer(5.0),
+		Page:      2,
+		PageSize:  10,
+	})
+
+	// assertions
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.AccountInfo{
+		{
+			Id: 12, DocumentId: "doc", Name: "julian", Iban: "AL1", Address: persistance.AddPointer("street"), Amount: 10.5,
+			Type: persistance.AddPointer(models.Receiving),
+			Meta: &models.DocumentMeta{Revision: 3, TransactionId: 9, Timestamp: timestamp, Creator: "a:test"},
+		},
+		{
+			Id: 13, DocumentId: "other", Name: "julian b", Iban: "AL2", Amount: 20,
+			Type: persistance.AddPointer(models.Receiving),
+			Meta: &models.DocumentMeta{Revision: 1, TransactionId: 4, Timestamp: timestamp, Creator: "a:test"},
+		},
+	}, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,16 +1,45 @@
 package internal
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"immudb/docs"
+	"immudb/internal/audit"
+	"immudb/internal/cdc"
 	"immudb/internal/configuration"
+	"immudb/internal/events"
+	"immudb/internal/graphqlapi"
+	"immudb/internal/grpcapi"
 	"immudb/internal/handlers"
+	"immudb/internal/importer"
+	"immudb/internal/models"
+	"immudb/internal/outbox"
 	"immudb/internal/persistance"
+	"immudb/internal/readmodel"
 	"immudb/internal/services"
+	"immudb/internal/webhooks"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const (
+	progressInterval = 5 * time.Second
+	// shutdownTimeout requests and calls in flight get this long to finish when the process is stopped
+	shutdownTimeout = 10 * time.Second
 )
 
 type Server struct {
@@ -22,19 +51,342 @@ func NewServer(config *configuration.ApplicationConfiguration) (*Server, error)
 	router.Use(handlers.CORSMiddleware())
 	docs.SwaggerInfo.Schemes = []string{"http"}
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-	db := persistance.NewImmmuDB(config.ImmuDbUrl, config.ImmuDbApiKey, config.ImmudbSearchUrl)
+	backend, err := newBackend(config)
+	if err != nil {
+		return nil, err
+	}
 
-	accountService, err := services.NewService(db)
+	accountService, err := services.NewService(backend.db)
 	if err != nil {
 		logrus.WithError(err).Fatal("couldn't setup server")
 	}
-	handler := handlers.NewHandler(accountService, router)
+	accountService.AllowUpdates = config.AllowUpdates
+	bus := events.NewBus()
+	accountService.Events = bus
+	// the sql-readthrough backend is the only way to run the read model, it projects the ledger itself
+	if readModel, ok := persistance.As[services.ReadModel](backend.db); ok {
+		accountService.ReadModel = readModel
+	}
+	handler := handlers.NewHandler(accountService, router, backend.checks...)
+	if backend.auditor != nil {
+		backend.auditor.Start(context.Background())
+		handler.Auditor = backend.auditor
+	}
+	if config.ImportDir != "" {
+		imports, err := importer.NewManager(accountService, config.ImportDir)
+		if err != nil {
+			return nil, err
+		}
+		handler.Imports = imports
+	}
+	feed, hasFeed := persistance.As[persistance.ChangeFeed](backend.db)
+	var capture *cdc.Capture
+	if hasFeed && config.CdcInterval > 0 {
+		capture = cdc.NewCapture(feed, newCheckpoints(config), config.CdcInterval)
+	}
+	if config.WebhookDir != "" {
+		manager, err := webhooks.NewManager(config.WebhookDir, webhooks.Options{
+			MaxAttempts:  config.WebhookMaxAttempts,
+			Backoff:      config.WebhookBackoff,
+			AllowedHosts: config.WebhookAllowedHosts,
+		})
+		if err != nil {
+			return nil, err
+		}
+		// through the change data capture webhooks get the writes of every instance and survive a restart
+		if capture != nil {
+			capture.Subscribe("webhooks", manager.Handle)
+		} else {
+			bus.Subscribe(manager.Publish)
+		}
+		manager.Start(context.Background())
+		handler.Webhooks = manager
+	}
+	// the change data capture is the only source of the event stream, so its events are in ledger order
+	if capture != nil {
+		stream := events.NewStream(feed)
+		if config.EventReplayWindow > 0 {
+			stream.ReplayWindow = config.EventReplayWindow
+		}
+		capture.SubscribeLive("event-stream", func(ctx context.Context, change persistance.Change) error {
+			stream.Publish(change)
+			return nil
+		})
+		handler.Events = stream
+	}
+	if config.OutboxPath != "" {
+		err = startOutbox(config, bus, capture)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if capture != nil {
+		capture.Start(context.Background())
+	}
+
+	graphqlHandler, err := graphqlapi.NewHandler(accountService, graphqlapi.Limits{
+		MaxDepth:      config.GraphqlMaxDepth,
+		MaxComplexity: config.GraphqlMaxComplexity,
+	})
+	if err != nil {
+		return nil, err
+	}
+	router.GET("/graphql", graphqlHandler)
+	router.POST("/graphql", graphqlHandler)
+
+	if config.MetricsPort != "" {
+		metricsServer, err := serveMetrics(fmt.Sprintf(":%s", config.MetricsPort))
+		if err != nil {
+			logrus.WithError(err).Error("failed to start the metrics listener")
+			return nil, err
+		}
+		defer metricsServer.Close()
+		logrus.Infof("metrics are served on port:%s", config.MetricsPort)
+	}
+
+	if config.GrpcPort != "" {
+		grpcServer, err := grpcapi.Start(fmt.Sprintf(":%s", config.GrpcPort), accountService, backend.checks...)
+		if err != nil {
+			logrus.WithError(err).Error("failed to start the gRPC api")
+			return nil, err
+		}
+		// the gRPC api lives as long as the REST one, however that one stops
+		defer grpcapi.Shutdown(grpcServer, shutdownTimeout)
+		logrus.Infof("gRPC api is running on port:%s", config.GrpcPort)
+	}
 
-	err = router.Run(fmt.Sprintf(":%s", config.Port))
+	err = serve(router, fmt.Sprintf(":%s", config.Port))
 	if err != nil {
 		logrus.WithError(err).Errorf("Setting up service failed.")
 		return nil, err
 	}
-	logrus.Infof("Application is running on port:%s", config.Port)
 	return &Server{handler: handler}, nil
 }
+
+// serve runs the REST api until it fails or the process is asked to stop, requests in flight get shutdownTimeout to finish
+func serve(router *gin.Engine, address string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	server := &http.Server{Addr: address, Handler: router, ReadHeaderTimeout: 10 * time.Second}
+	failed := make(chan error, 1)
+	go func() {
+		failed <- server.ListenAndServe()
+	}()
+	logrus.Infof("Application is running on port%s", address)
+	select {
+	case err := <-failed:
+		return err
+	case <-ctx.Done():
+	}
+	logrus.Info("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err := server.Shutdown(shutdownCtx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		// event streams stay open until the client leaves
+		logrus.Warn("requests still running after the shutdown timeout are cut off")
+		return server.Close()
+	}
+	return err
+}
+
+// serveMetrics serves /debug/vars on its own listener, it is meant for the internal network only
+func serveMetrics(address string) (*http.Server, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		err := server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.WithError(err).Error("metrics listener stopped")
+		}
+	}()
+	return server, nil
+}
+
+// publishedMetrics holds, by name, the metrics of the latest instance published under that name
+var publishedMetrics sync.Map
+
+// publishMetrics serves the metrics of one instance under name. expvar panics on a name published twice,
+// so every name is published once and a later instance replaces the metrics served under it
+func publishMetrics(name string, metrics *expvar.Map) {
+	current, loaded := publishedMetrics.LoadOrStore(name, &atomic.Pointer[expvar.Map]{})
+	latest := current.(*atomic.Pointer[expvar.Map])
+	latest.Store(metrics)
+	if loaded {
+		return
+	}
+	expvar.Publish(name, expvar.Func(func() any {
+		return json.RawMessage(latest.Load().String())
+	}))
+}
+
+// Backfill empties the read model of the sql-readthrough backend and projects the whole ledger of its source into it again
+func Backfill(ctx context.Context, config *configuration.ApplicationConfiguration) (int, error) {
+	settings, err := config.Backends.Settings(readmodel.BackendSQLReadThrough)
+	if err != nil {
+		return 0, err
+	}
+	readThrough := settings.(*readmodel.ReadThroughSettings)
+	source, err := config.Backends.Open(readThrough.Source)
+	if err != nil {
+		return 0, err
+	}
+	feed, ok := persistance.As[persistance.ChangeFeed](source)
+	if !ok {
+		return 0, fmt.Errorf("the %s backend has no change feed to project the read model from", readThrough.Source)
+	}
+	store, err := readmodel.Open(ctx, readThrough.ConnectionString)
+	if err != nil {
+		logrus.WithError(err).Error("failed to connect to the read model database")
+		return 0, err
+	}
+	defer store.Close()
+	err = store.Migrate(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return readmodel.NewProjector(feed, store, readThrough.ProjectorInterval).Backfill(ctx)
+}
+
+// Import reads the CSV at path into the configured backend, rejected rows are written to report.
+// Progress is logged every progressInterval.
+func Import(ctx context.Context, config *configuration.ApplicationConfiguration, path string, options importer.Options, report io.Writer) (models.ImportJob, error) {
+	backend, err := newBackend(config)
+	if err != nil {
+		return models.ImportJob{}, err
+	}
+	accountService, err := services.NewService(backend.db)
+	if err != nil {
+		return models.ImportJob{}, err
+	}
+	input, err := os.Open(path)
+	if err != nil {
+		return models.ImportJob{}, err
+	}
+	defer input.Close()
+	var size int64
+	if info, err := input.Stat(); err == nil {
+		size = info.Size()
+	}
+	job := importer.NewJob(options.DryRun, size)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				state := job.Snapshot()
+				logrus.WithFields(logrus.Fields{"rows": state.Rows, "imported": state.Imported, "failed": state.Failed, "bytesRead": state.BytesRead, "totalBytes": state.TotalBytes}).Info("importing")
+			}
+		}
+	}()
+	err = importer.Run(ctx, input, accountService, options, job, report)
+	job.Finish(err)
+	return job.Snapshot(), err
+}
+
+type backend struct {
+	db      persistance.AccountDB
+	checks  []handlers.HealthChecker
+	auditor *audit.Auditor
+}
+
+// newBackend opens the backend chosen in the configuration, backends that keep a trusted ledger state
+// get a health check and, if enabled, the background audit
+func newBackend(config *configuration.ApplicationConfiguration) (*backend, error) {
+	name := config.BackendName()
+	db, err := config.Backends.Open(name)
+	if err != nil {
+		logrus.WithError(err).WithField("backend", name).Error("failed to open the backend")
+		return nil, err
+	}
+	logrus.WithField("backend", name).Info("backend opened")
+	result := &backend{db: db}
+	// cache misses of the same account are coalesced as well
+	if config.CoalesceReads {
+		coalesced := persistance.NewCoalescedDB(result.db)
+		publishMetrics("readCoalescing", coalesced.Metrics)
+		result.db = coalesced
+	}
+	if config.CacheSize > 0 {
+		cached := persistance.NewCachedDB(result.db, config.CacheSize, config.CacheTTL)
+		publishMetrics("accountCache", cached.Metrics)
+		result.db = cached
+	}
+	trusted, ok := persistance.As[persistance.TrustedLedger](db)
+	if !ok {
+		if config.TamperAlarm {
+			return nil, fmt.Errorf("TamperAlarm needs a backend that keeps a trusted ledger state, %s doesn't, use %s", name, persistance.BackendFileLedger)
+		}
+		if config.AuditInterval > 0 {
+			return nil, fmt.Errorf("AuditInterval needs a backend that can be audited, %s can't, use %s or set it to 0", name, persistance.BackendFileLedger)
+		}
+		logrus.WithField("backend", name).Warn("the backend keeps no trusted ledger state, there is no tamper alarm")
+		return result, nil
+	}
+	verifier := trusted.LedgerVerifier()
+	trustedId, err := verifier.TrustedTransactionId()
+	if err != nil {
+		logrus.WithError(err).Error("failed to load the trusted ledger state")
+		return nil, err
+	}
+	logrus.WithField("transactionId", trustedId).Info("trusted ledger state loaded")
+	result.checks = append(result.checks, verifier)
+	auditable, ok := persistance.As[persistance.AuditableDB](db)
+	if !ok {
+		if config.AuditInterval > 0 {
+			return nil, fmt.Errorf("AuditInterval needs a backend that can be audited, %s can't, set it to 0", name)
+		}
+		return result, nil
+	}
+	if config.AuditInterval <= 0 {
+		logrus.Warn("the background audit is disabled, the ledger is only checked against the trusted state on verified reads")
+		return result, nil
+	}
+	result.auditor = audit.NewAuditor(auditable, verifier, config.AuditInterval, config.AuditWindow, config.AuditSampleSize)
+	return result, nil
+}
+
+// startOutbox journals the events of every write before it is answered and relays them to the configured sink.
+// The change data capture adds the events of writes whose process died before they were journaled, without it
+// or with checkpoints that don't survive a restart those events would be lost, so the outbox refuses to start.
+func startOutbox(config *configuration.ApplicationConfiguration, bus *events.Bus, capture *cdc.Capture) error {
+	if capture == nil {
+		return errors.New("the outbox needs the change data capture, set CdcInterval on a backend with a change feed")
+	}
+	// the ledger of the memory backend doesn't outlive the process, nothing is left to reconcile after a restart
+	if config.BackendName() != persistance.BackendMemory && config.CdcCheckpointPath == "" {
+		return errors.New("the outbox needs CdcCheckpointPath, so the change data capture resumes where it stopped after a restart")
+	}
+	box, err := outbox.Open(config.OutboxPath)
+	if err != nil {
+		logrus.WithError(err).Error("failed to open the outbox")
+		return err
+	}
+	sink, err := outbox.NewSink(config.OutboxSink, config.OutboxTarget, config.OutboxSecret)
+	if err != nil {
+		return err
+	}
+	bus.Subscribe(box.Publish)
+	capture.Subscribe("outbox", box.Handle)
+	outbox.NewRelay(box, sink).Start(context.Background())
+	logrus.WithFields(logrus.Fields{"sink": config.OutboxSink, "pending": box.Pending()}).Info("outbox opened")
+	return nil
+}
+
+// newCheckpoints the memory backend starts empty, positions in a ledger that is gone would skip the new one
+func newCheckpoints(config *configuration.ApplicationConfiguration) cdc.CheckpointStore {
+	if config.BackendName() == persistance.BackendMemory || config.CdcCheckpointPath == "" {
+		return cdc.NewMemoryCheckpoints()
+	}
+	return cdc.NewFileCheckpoints(config.CdcCheckpointPath)
+}
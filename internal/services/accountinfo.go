@@ -4,6 +4,7 @@ import (
 	"context"
 	"immudb/internal/errors"
 	"immudb/internal/models"
+	"immudb/internal/persistance"
 	"net/http"
 )
 
@@ -18,7 +19,12 @@ func (s *AccountService) CreateAccountInfo(ctx context.Context, data *models.Acc
 	if err != nil {
 		return nil, err
 	}
-	return s.Db.CreateAccountInfo(ctx, *data)
+	created, err := s.Db.CreateAccountInfo(ctx, *data)
+	if err != nil {
+		return nil, err
+	}
+	s.publish(models.AccountCreated, created)
+	return created, nil
 }
 
 func (s *AccountService) GetAllAccountInfos(ctx context.Context, page, pageSize int) ([]*models.AccountInfo, error) {
@@ -39,27 +45,71 @@ func (s *AccountService) GetAccountInfoById(ctx context.Context, Id uint) (*mode
 	return s.Db.GetAccountInfoById(ctx, Id)
 }
 
+func (s *AccountService) GetAccountInfoByDocumentId(ctx context.Context, documentId string) (*models.AccountInfo, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	db, ok := persistance.As[persistance.DocumentDB](s.Db)
+	if !ok {
+		return nil, errors.NewServiceError("lookups by document id are not supported by the configured backend", http.StatusNotImplemented)
+	}
+	return db.GetAccountInfoByDocumentId(ctx, documentId)
+}
+
+// GetVerifiedAccountInfoById only succeeds if the backend can prove the account wasn't tampered with
+func (s *AccountService) GetVerifiedAccountInfoById(ctx context.Context, Id uint) (*models.AccountInfo, *models.Verification, error) {
+	select {
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	default:
+	}
+	db, ok := persistance.As[persistance.VerifiableDB](s.Db)
+	if !ok {
+		return nil, nil, errors.NewServiceError("verified reads are not supported by the configured backend", http.StatusNotImplemented)
+	}
+	return db.GetVerifiedAccountInfoById(ctx, Id)
+}
+
+// ValidateAccountInfo runs the checks of CreateAccountInfo without writing, for dry runs
+func (s *AccountService) ValidateAccountInfo(data *models.AccountInfo) error {
+	return s.validateAccount(data)
+}
+
 func (s *AccountService) validateAccount(data *models.AccountInfo) error {
 	if data == nil {
 		return errors.NewServiceError("invalid input", http.StatusBadRequest)
 	}
 
+	// collect every invalid field so the caller gets all of them in one response
+	var invalidParams []errors.InvalidParam
 	if data.Id != 0 {
-		return errors.NewServiceError("invalid accountNumber for the account, do not specify accountNumber", http.StatusBadRequest)
+		invalidParams = append(invalidParams, errors.InvalidParam{Name: "account_number", Reason: "invalid accountNumber for the account, do not specify accountNumber"})
+	}
+	invalidParams = append(invalidParams, s.validateAccountFields(data)...)
+
+	if len(invalidParams) > 0 {
+		return errors.NewValidationError(http.StatusBadRequest, invalidParams...)
 	}
+	return nil
+}
 
+// validateAccountFields validates the fields a caller is allowed to set
+func (s *AccountService) validateAccountFields(data *models.AccountInfo) []errors.InvalidParam {
+	var invalidParams []errors.InvalidParam
 	if data.Iban == "" {
-		return errors.NewServiceError("invalid iban for the account", http.StatusBadRequest)
+		invalidParams = append(invalidParams, errors.InvalidParam{Name: "iban", Reason: "invalid iban for the account"})
 	}
 	// we might need to add a logic to check if iban already exist into our system since its supposed to be unique
 	// same logic might be considered for name
 
 	if data.Name == "" {
-		return errors.NewServiceError("invalid accountName for the account", http.StatusBadRequest)
+		invalidParams = append(invalidParams, errors.InvalidParam{Name: "account_name", Reason: "invalid accountName for the account"})
 	}
 
 	if data.Type == nil {
-		return errors.NewServiceError("invalid type for the account", http.StatusBadRequest)
+		invalidParams = append(invalidParams, errors.InvalidParam{Name: "type", Reason: "invalid type for the account"})
 	}
-	return nil
+	return invalidParams
 }
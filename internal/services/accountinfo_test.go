@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	cerror "immudb/internal/errors"
+	"immudb/internal/events"
 	"immudb/internal/models"
 	"immudb/internal/persistance"
 	"net/http"
@@ -272,6 +273,23 @@ func TestAccountService_CreateAccountInfo(t *testing.T) {
 			expectedError:   cerror.NewServiceError("invalid type for the account", http.StatusBadRequest),
 			isErrorExpected: true,
 		},
+		{
+			name: "Test_Model_Validation_MultipleFields",
+			request: &models.AccountInfo{
+				Id:      1,
+				Address: persistance.AddPointer("test"),
+				Amount:  11.1,
+			},
+			accountDb:      &MockAccountDB{},
+			expectedResult: nil,
+			expectedError: cerror.NewValidationError(http.StatusBadRequest,
+				cerror.InvalidParam{Name: "account_number", Reason: "invalid accountNumber for the account, do not specify accountNumber"},
+				cerror.InvalidParam{Name: "iban", Reason: "invalid iban for the account"},
+				cerror.InvalidParam{Name: "account_name", Reason: "invalid accountName for the account"},
+				cerror.InvalidParam{Name: "type", Reason: "invalid type for the account"},
+			),
+			isErrorExpected: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -294,3 +312,47 @@ func TestAccountService_CreateAccountInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestAccountService_GetVerifiedAccountInfoById_NotSupported(t *testing.T) {
+	// setup
+	service, err := NewService(&MockAccountDB{})
+	assert.NoError(t, err, "error setting up the service")
+
+	// action
+	info, verification, err := service.GetVerifiedAccountInfoById(context.Background(), 1)
+
+	//assert
+	assert.Nil(t, info)
+	assert.Nil(t, verification)
+	assert.Equal(t, cerror.NewServiceError("verified reads are not supported by the configured backend", http.StatusNotImplemented), err)
+}
+
+func TestAccountService_PublishesEvents(t *testing.T) {
+	// setup
+	service, err := NewService(persistance.NewMemoryDB())
+	assert.NoError(t, err, "error setting up the service")
+	service.AllowUpdates = true
+	bus := events.NewBus()
+	service.Events = bus
+	var published []models.AccountEvent
+	bus.Subscribe(func(event models.AccountEvent) {
+		published = append(published, event)
+	})
+	account := &models.AccountInfo{Name: "as", Iban: "a", Type: persistance.AddPointer(models.Sending)}
+
+	// action
+	created, err := service.CreateAccountInfo(context.Background(), account)
+	assert.NoError(t, err)
+	_, err = service.CreateAccountInfo(context.Background(), &models.AccountInfo{Name: "invalid"})
+	assert.Error(t, err)
+	updated, err := service.UpdateAccountInfo(context.Background(), created.Id, &models.AccountInfo{Name: "bs", Iban: "a", Type: persistance.AddPointer(models.Sending)}, 1)
+	assert.NoError(t, err)
+
+	//assert
+	assert.Len(t, published, 2)
+	assert.Equal(t, models.AccountCreated, published[0].Type)
+	assert.Equal(t, fmt.Sprintf("%d-1", created.Id), published[0].Id)
+	assert.Equal(t, models.AccountUpdated, published[1].Type)
+	assert.Equal(t, fmt.Sprintf("%d-2", created.Id), published[1].Id)
+	assert.Equal(t, updated.Name, published[1].Account.Name)
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"immudb/internal/errors"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"net/http"
+)
+
+const maxBatchSize = 500
+
+// CreateAccountInfos validates every account and writes the valid ones, with atomic an invalid account rejects the whole batch.
+// Atomic only covers validation, a write that fails halfway leaves the accounts written before it in place.
+func (s *AccountService) CreateAccountInfos(ctx context.Context, data []*models.AccountInfo, atomic bool) ([]models.BatchItem, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	if len(data) == 0 || len(data) > maxBatchSize {
+		return nil, errors.NewValidationError(http.StatusBadRequest,
+			errors.InvalidParam{Name: "accounts", Reason: fmt.Sprintf("a batch needs between 1 and %d accounts", maxBatchSize)})
+	}
+
+	result := make([]models.BatchItem, len(data))
+	var valid []int
+	for i, item := range data {
+		err := s.validateAccount(item)
+		if err != nil {
+			result[i].Err = err
+			continue
+		}
+		valid = append(valid, i)
+	}
+	if atomic && len(valid) < len(data) {
+		for _, i := range valid {
+			result[i].Err = errors.NewServiceError("not created, another account of the atomic batch is invalid", http.StatusFailedDependency)
+		}
+		return result, nil
+	}
+	if len(valid) == 0 {
+		return result, nil
+	}
+
+	batchDB, ok := persistance.As[persistance.BatchDB](s.Db)
+	if !ok {
+		for _, i := range valid {
+			result[i].Account, result[i].Err = s.Db.CreateAccountInfo(ctx, *data[i])
+			if result[i].Err == nil {
+				s.publish(models.AccountCreated, result[i].Account)
+			}
+		}
+		return result, nil
+	}
+	accounts := make([]models.AccountInfo, 0, len(valid))
+	for _, i := range valid {
+		accounts = append(accounts, *data[i])
+	}
+	created, err := batchDB.CreateAccountInfos(ctx, accounts)
+	if err == nil && len(created) < len(accounts) {
+		err = fmt.Errorf("the backend created %d of %d accounts", len(created), len(accounts))
+	}
+	for j, i := range valid {
+		if j < len(created) {
+			result[i].Account = created[j]
+			s.publish(models.AccountCreated, created[j])
+			continue
+		}
+		result[i].Err = err
+	}
+	return result, nil
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	cerror "immudb/internal/errors"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"net/http"
+	"testing"
+)
+
+func TestAccountService_CreateAccountInfos(t *testing.T) {
+	valid := func(name string) *models.AccountInfo {
+		return &models.AccountInfo{Name: name, Iban: "aa", Type: persistance.AddPointer(models.Sending)}
+	}
+	invalid := &models.AccountInfo{Iban: "aa", Type: persistance.AddPointer(models.Sending)}
+	invalidErr := cerror.NewValidationError(http.StatusBadRequest,
+		cerror.InvalidParam{Name: "account_name", Reason: "invalid accountName for the account"})
+	created := func(name string, id uint) *models.AccountInfo {
+		account := valid(name)
+		account.Id = id
+		return account
+	}
+	writeErr := errors.New("vault unavailable")
+	// creates one account per call and fails once the third one is reached
+	singleDB := &MockAccountDB{
+		CreateCustomFunction: func(ctx context.Context, info models.AccountInfo) (*models.AccountInfo, error) {
+			if info.Name == "third" {
+				return nil, writeErr
+			}
+			return created(info.Name, 1), nil
+		},
+	}
+	// writes the first account of every batch and fails on the rest
+	batchDB := &MockBatchDB{
+		CreateBatchCustomFunction: func(ctx context.Context, infos []models.AccountInfo) ([]*models.AccountInfo, error) {
+			return []*models.AccountInfo{created(infos[0].Name, 1)}, writeErr
+		},
+	}
+	tests := []struct {
+		name           string
+		accountDb      persistance.AccountDB
+		request        []*models.AccountInfo
+		atomic         bool
+		expectedError  error
+		expectedResult []models.BatchItem
+	}{
+		{
+			name:      "Test_Invalid_Item",
+			accountDb: singleDB,
+			request:   []*models.AccountInfo{valid("first"), invalid, valid("second")},
+			expectedResult: []models.BatchItem{
+				{Account: created("first", 1)},
+				{Err: invalidErr},
+				{Account: created("second", 1)},
+			},
+		},
+		{
+			name:      "Test_Atomic_Rejects_Everything",
+			accountDb: singleDB,
+			request:   []*models.AccountInfo{valid("first"), invalid},
+			atomic:    true,
+			expectedResult: []models.BatchItem{
+				{Err: cerror.NewServiceError("not created, another account of the atomic batch is invalid", http.StatusFailedDependency)},
+				{Err: invalidErr},
+			},
+		},
+		{
+			name:      "Test_Write_Fails_One_By_One",
+			accountDb: singleDB,
+			request:   []*models.AccountInfo{valid("first"), valid("third")},
+			expectedResult: []models.BatchItem{
+				{Account: created("first", 1)},
+				{Err: writeErr},
+			},
+		},
+		{
+			name:      "Test_Batch_Write_Fails_Halfway",
+			accountDb: batchDB,
+			request:   []*models.AccountInfo{valid("first"), invalid, valid("second")},
+			expectedResult: []models.BatchItem{
+				{Account: created("first", 1)},
+				{Err: invalidErr},
+				{Err: writeErr},
+			},
+		},
+		{
+			name:      "Test_Empty_Batch",
+			accountDb: singleDB,
+			expectedError: cerror.NewValidationError(http.StatusBadRequest,
+				cerror.InvalidParam{Name: "accounts", Reason: "a batch needs between 1 and 500 accounts"}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			service, err := NewService(tt.accountDb)
+			assert.NoError(t, err, "error setting up the service")
+
+			// action
+			result, err := service.CreateAccountInfos(context.Background(), tt.request, tt.atomic)
+
+			//assert
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, err)
+				return
+			}
+			assert.NoError(t, err, "error creating the batch")
+			assert.Equal(t, tt.expectedResult, result)
+		})
+	}
+}
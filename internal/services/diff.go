@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"immudb/internal/errors"
+	"immudb/internal/models"
+	"net/http"
+	"reflect"
+)
+
+// diffFields are the account fields a diff is reported on, in the order they are reported
+var diffFields = []struct {
+	name  string
+	value func(info *models.AccountInfo) interface{}
+}{
+	{name: "Name", value: func(info *models.AccountInfo) interface{} { return info.Name }},
+	{name: "Iban", value: func(info *models.AccountInfo) interface{} { return info.Iban }},
+	{name: "Address", value: func(info *models.AccountInfo) interface{} { return deref(info.Address) }},
+	{name: "Amount", value: func(info *models.AccountInfo) interface{} { return info.Amount }},
+	{name: "Type", value: func(info *models.AccountInfo) interface{} { return deref(info.Type) }},
+}
+
+// DiffAccountInfo returns the fields that changed between two revisions of an account, every change is
+// attributed to the revision in between that last touched the field
+func (s *AccountService) DiffAccountInfo(ctx context.Context, Id uint, from, to uint64) (*models.AccountDiff, error) {
+	if from == 0 || to == 0 || from > to {
+		return nil, errors.NewValidationError(http.StatusBadRequest, errors.InvalidParam{
+			Name:   "from",
+			Reason: "from and to must be revision numbers with from not after to",
+		})
+	}
+	revisions, err := s.GetAccountInfoHistory(ctx, Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// revisions in (from, to], the first one is the from revision itself
+	var window []*models.AccountInfo
+	for _, revision := range revisions {
+		if revision.Meta == nil || revision.Meta.Revision < from || revision.Meta.Revision > to {
+			continue
+		}
+		window = append(window, revision)
+	}
+	if len(window) == 0 || window[0].Meta.Revision != from {
+		return nil, errors.NewServiceError(fmt.Sprintf("revision %d not found", from), http.StatusNotFound)
+	}
+	if window[len(window)-1].Meta.Revision != to {
+		return nil, errors.NewServiceError(fmt.Sprintf("revision %d not found", to), http.StatusNotFound)
+	}
+
+	first, last := window[0], window[len(window)-1]
+	result := &models.AccountDiff{
+		Id:           Id,
+		DocumentId:   first.DocumentId,
+		FromRevision: from,
+		ToRevision:   to,
+		Changes:      []models.FieldChange{},
+	}
+	for _, field := range diffFields {
+		oldValue, newValue := field.value(first), field.value(last)
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		change := models.FieldChange{Field: field.name, OldValue: oldValue, NewValue: newValue}
+		for i := 1; i < len(window); i++ {
+			if !reflect.DeepEqual(field.value(window[i-1]), field.value(window[i])) {
+				change.ChangedBy = window[i].Meta.Creator
+				change.TransactionId = window[i].Meta.TransactionId
+			}
+		}
+		result.Changes = append(result.Changes, change)
+	}
+	return result, nil
+}
+
+// deref keeps nil as nil instead of a typed nil pointer so missing values compare and serialize as null
+func deref[T any](value *T) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	cerror "immudb/internal/errors"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"net/http"
+	"testing"
+)
+
+func TestAccountService_DiffAccountInfo(t *testing.T) {
+	revisions := []*models.AccountInfo{
+		{Id: 1, DocumentId: "doc1", Name: "John", Iban: "iban", Amount: 10, Type: persistance.AddPointer(models.Sending),
+			Meta: &models.DocumentMeta{Revision: 1, TransactionId: 3, Creator: "alice"}},
+		{Id: 1, DocumentId: "doc1", Name: "John Doe", Iban: "iban", Amount: 10, Type: persistance.AddPointer(models.Sending),
+			Meta: &models.DocumentMeta{Revision: 2, TransactionId: 6, Creator: "bob"}},
+		{Id: 1, DocumentId: "doc1", Name: "John Doe", Iban: "iban", Address: persistance.AddPointer("street"), Amount: 12, Type: persistance.AddPointer(models.Sending),
+			Meta: &models.DocumentMeta{Revision: 3, TransactionId: 9, Creator: "carol"}},
+	}
+	db := &MockRevisionDB{
+		MockAccountDB: MockAccountDB{
+			GetByCustomFunction: func(ctx context.Context, id uint) (*models.AccountInfo, error) {
+				return revisions[2], nil
+			},
+		},
+		RevisionsCustomFunction: func(ctx context.Context, documentId string) ([]*models.AccountInfo, error) {
+			return revisions, nil
+		},
+	}
+
+	tests := []struct {
+		name            string
+		from            uint64
+		to              uint64
+		expectedChanges []models.FieldChange
+		expectedError   error
+	}{
+		{
+			name: "Test_Single_Revision",
+			from: 1,
+			to:   2,
+			expectedChanges: []models.FieldChange{
+				{Field: "Name", OldValue: "John", NewValue: "John Doe", ChangedBy: "bob", TransactionId: 6},
+			},
+		},
+		{
+			name: "Test_Across_Revisions",
+			from: 1,
+			to:   3,
+			expectedChanges: []models.FieldChange{
+				{Field: "Name", OldValue: "John", NewValue: "John Doe", ChangedBy: "bob", TransactionId: 6},
+				{Field: "Address", OldValue: nil, NewValue: "street", ChangedBy: "carol", TransactionId: 9},
+				{Field: "Amount", OldValue: float64(10), NewValue: float64(12), ChangedBy: "carol", TransactionId: 9},
+			},
+		},
+		{
+			name:            "Test_Same_Revision",
+			from:            2,
+			to:              2,
+			expectedChanges: []models.FieldChange{},
+		},
+		{
+			name:          "Test_Unknown_Revision",
+			from:          2,
+			to:            7,
+			expectedError: cerror.NewServiceError("revision 7 not found", http.StatusNotFound),
+		},
+		{
+			name: "Test_Reversed_Revisions",
+			from: 3,
+			to:   1,
+			expectedError: cerror.NewValidationError(http.StatusBadRequest, cerror.InvalidParam{
+				Name:   "from",
+				Reason: "from and to must be revision numbers with from not after to",
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			service, err := NewService(db)
+			assert.NoError(t, err, "error setting up the service")
+
+			// action
+			diff, err := service.DiffAccountInfo(context.Background(), 1, tt.from, tt.to)
+
+			//assert
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, err)
+				return
+			}
+			assert.NoError(t, err, "error diffing account")
+			assert.Equal(t, tt.expectedChanges, diff.Changes)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"immudb/internal/models"
+)
+
+// scanPageSize is the largest page the vault search returns, every account is walked in pages of it
+const scanPageSize = 100
+
+// ExportAccountInfos walks every page of the accounts and hands them to write one page at a time,
+// only the current page is held in memory so the size of the collection doesn't matter.
+// With asOf only the accounts that existed at that point are exported, as they were then, in pages as full as without it.
+// The pages are read by offset and the vault keeps no search open between them, so the export is not a snapshot:
+// an account created or updated while it runs can shift the pages and be missed or exported twice, asOf doesn't change that.
+func (s *AccountService) ExportAccountInfos(ctx context.Context, asOf *models.AsOf, write func(page []*models.AccountInfo) error) error {
+	if asOf != nil {
+		return s.exportAsOf(ctx, *asOf, write)
+	}
+	for page := 1; ; page++ {
+		current, err := s.GetAllAccountInfos(ctx, page, scanPageSize)
+		if err != nil {
+			return err
+		}
+		if len(current) > 0 {
+			err = write(current)
+			if err != nil {
+				return err
+			}
+		}
+		if len(current) < scanPageSize {
+			return nil
+		}
+	}
+}
+
+// exportAsOf collects the accounts that existed at asOf into full pages, a page of the store loses the accounts created since
+func (s *AccountService) exportAsOf(ctx context.Context, asOf models.AsOf, write func(page []*models.AccountInfo) error) error {
+	db, err := s.revisionDB()
+	if err != nil {
+		return err
+	}
+	var page []*models.AccountInfo
+	err = s.walkAsOf(ctx, db, asOf, func(accounts []*models.AccountInfo) (bool, error) {
+		page = append(page, accounts...)
+		for len(page) >= scanPageSize {
+			err := write(page[:scanPageSize])
+			if err != nil {
+				return false, err
+			}
+			page = page[scanPageSize:]
+		}
+		return true, nil
+	})
+	if err != nil || len(page) == 0 {
+		return err
+	}
+	return write(page)
+}
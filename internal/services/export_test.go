@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"immudb/internal/models"
+	"testing"
+)
+
+func TestAccountService_ExportAccountInfos(t *testing.T) {
+	// pages of accounts numbered from 1, the store has total accounts
+	pagedDB := func(total int, failOnPage int) *MockAccountDB {
+		return &MockAccountDB{
+			GetAllCustomFunction: func(ctx context.Context, pageNr, pageSize int) ([]*models.AccountInfo, error) {
+				if pageNr == failOnPage {
+					return nil, errors.New("vault unavailable")
+				}
+				var page []*models.AccountInfo
+				for id := (pageNr-1)*pageSize + 1; id <= total && len(page) < pageSize; id++ {
+					page = append(page, &models.AccountInfo{Id: uint(id)})
+				}
+				return page, nil
+			},
+		}
+	}
+	tests := []struct {
+		name              string
+		db                *MockAccountDB
+		asOf              *models.AsOf
+		expectedPageSizes []int
+		expectedError     string
+	}{
+		{
+			name:              "Test_Walks_Every_Page",
+			db:                pagedDB(2*scanPageSize+1, 0),
+			expectedPageSizes: []int{scanPageSize, scanPageSize, 1},
+		},
+		{
+			name:              "Test_Exact_Pages",
+			db:                pagedDB(scanPageSize, 0),
+			expectedPageSizes: []int{scanPageSize},
+		},
+		{
+			name:              "Test_Empty",
+			db:                pagedDB(0, 0),
+			expectedPageSizes: nil,
+		},
+		{
+			name:              "Test_Error_After_First_Page",
+			db:                pagedDB(2*scanPageSize, 2),
+			expectedPageSizes: []int{scanPageSize},
+			expectedError:     "vault unavailable",
+		},
+		{
+			name:          "Test_AsOf_Without_Revisions",
+			db:            pagedDB(1, 0),
+			asOf:          &models.AsOf{TransactionId: 1},
+			expectedError: "revision history is not supported by the configured backend",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			service := &AccountService{Db: tt.db}
+			var pageSizes []int
+
+			// action
+			err := service.ExportAccountInfos(context.Background(), tt.asOf, func(page []*models.AccountInfo) error {
+				pageSizes = append(pageSizes, len(page))
+				return nil
+			})
+
+			// assertions
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedPageSizes, pageSizes)
+		})
+	}
+}
+
+func TestAccountService_ExportAccountInfosAsOf(t *testing.T) {
+	// setup
+	service := &AccountService{Db: newHistoryDB()}
+	var exported []*models.AccountInfo
+
+	// action
+	err := service.ExportAccountInfos(context.Background(), &models.AsOf{TransactionId: 3}, func(page []*models.AccountInfo) error {
+		exported = append(exported, page...)
+		return nil
+	})
+
+	// assertions
+	assert.NoError(t, err)
+	assert.Len(t, exported, 1, "the account created after the transaction is left out")
+	assert.Equal(t, "first", exported[0].Name)
+}
+
+func TestAccountService_ExportAccountInfosAsOf_FullPages(t *testing.T) {
+	// setup
+	service := &AccountService{Db: newInterleavedDB(2*scanPageSize + 50)}
+	var pageSizes []int
+
+	// action
+	err := service.ExportAccountInfos(context.Background(), &models.AsOf{TransactionId: 1000}, func(page []*models.AccountInfo) error {
+		pageSizes = append(pageSizes, len(page))
+		return nil
+	})
+
+	// assertions
+	assert.NoError(t, err)
+	assert.Equal(t, []int{scanPageSize, scanPageSize / 4}, pageSizes, "the pages are filled with the accounts that existed at the point")
+}
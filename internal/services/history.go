@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"immudb/internal/errors"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"net/http"
+	"sync"
+)
+
+// how many documents have their revisions loaded at the same time for a point in time list
+const asOfConcurrency = 8
+
+// GetAllAccountInfosAsOf pages over the accounts that existed at the requested point, each as it was then, so only the
+// last page is short and accounts created afterwards don't shift the pages. The store can't search by that point, the
+// accounts before the page are walked too: page n reads every account up to it, only those changed since load their revisions.
+func (s *AccountService) GetAllAccountInfosAsOf(ctx context.Context, page, pageSize int, asOf models.AsOf) ([]*models.AccountInfo, error) {
+	db, err := s.revisionDB()
+	if err != nil {
+		return nil, err
+	}
+	skip := (page - 1) * pageSize
+	var output []*models.AccountInfo
+	err = s.walkAsOf(ctx, db, asOf, func(accounts []*models.AccountInfo) (bool, error) {
+		skipped := min(skip, len(accounts))
+		skip -= skipped
+		accounts = accounts[skipped:]
+		output = append(output, accounts[:min(len(accounts), pageSize-len(output))]...)
+		return len(output) < pageSize, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// walkAsOf hands visit the accounts that existed at asOf as they were then, one page of the store at a time in its order,
+// until visit returns false or every account was read
+func (s *AccountService) walkAsOf(ctx context.Context, db persistance.RevisionDB, asOf models.AsOf, visit func(accounts []*models.AccountInfo) (bool, error)) error {
+	for page := 1; ; page++ {
+		current, err := s.GetAllAccountInfos(ctx, page, scanPageSize)
+		if err != nil {
+			return err
+		}
+		selected, err := selectAsOf(ctx, db, current, asOf)
+		if err != nil {
+			return err
+		}
+		more, err := visit(selected)
+		// a page shortened by asOf is not the last one, only a short page of the store is
+		if err != nil || !more || len(current) < scanPageSize {
+			return err
+		}
+	}
+}
+
+// selectAsOf loads the revisions of every account and keeps the one current at asOf,
+// accounts that didn't exist yet are dropped
+func selectAsOf(ctx context.Context, db persistance.RevisionDB, current []*models.AccountInfo, asOf models.AsOf) ([]*models.AccountInfo, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make([]*models.AccountInfo, len(current))
+	errs := make([]error, len(current))
+	semaphore := make(chan struct{}, asOfConcurrency)
+	wg := sync.WaitGroup{}
+	for i, account := range current {
+		// not changed since, the current revision is the one
+		if asOf.Includes(account.Meta) {
+			results[i] = account
+			continue
+		}
+		wg.Add(1)
+		go func(i int, account *models.AccountInfo) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			revisions, err := db.GetAccountInfoRevisions(ctx, account.DocumentId)
+			if err != nil {
+				errs[i] = err
+				cancel()
+				return
+			}
+			results[i] = asOf.Select(revisions)
+		}(i, account)
+	}
+	wg.Wait()
+
+	var output []*models.AccountInfo
+	for i := range results {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if results[i] != nil {
+			output = append(output, results[i])
+		}
+	}
+	return output, nil
+}
+
+func (s *AccountService) GetAccountInfoByIdAsOf(ctx context.Context, Id uint, asOf models.AsOf) (*models.AccountInfo, error) {
+	revisions, err := s.GetAccountInfoHistory(ctx, Id)
+	if err != nil {
+		return nil, err
+	}
+	result := asOf.Select(revisions)
+	if result == nil {
+		return nil, errors.NewServiceError("account info did not exist at the requested point in time", http.StatusNotFound)
+	}
+	return result, nil
+}
+
+// GetAccountInfoHistory returns every revision of the account, oldest first
+func (s *AccountService) GetAccountInfoHistory(ctx context.Context, Id uint) ([]*models.AccountInfo, error) {
+	db, err := s.revisionDB()
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.GetAccountInfoById(ctx, Id)
+	if err != nil {
+		return nil, err
+	}
+	return db.GetAccountInfoRevisions(ctx, current.DocumentId)
+}
+
+func (s *AccountService) revisionDB() (persistance.RevisionDB, error) {
+	db, ok := persistance.As[persistance.RevisionDB](s.Db)
+	if !ok {
+		return nil, errors.NewServiceError("revision history is not supported by the configured backend", http.StatusNotImplemented)
+	}
+	return db, nil
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	cerror "immudb/internal/errors"
+	"immudb/internal/models"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func revision(documentId string, name string, revision, transactionId uint64, ts int64) *models.AccountInfo {
+	return &models.AccountInfo{
+		Id:         1,
+		DocumentId: documentId,
+		Name:       name,
+		Meta:       &models.DocumentMeta{Revision: revision, TransactionId: transactionId, Timestamp: time.Unix(ts, 0).UTC()},
+	}
+}
+
+func newHistoryDB() *MockRevisionDB {
+	history := map[string][]*models.AccountInfo{
+		"doc1": {revision("doc1", "first", 1, 2, 100), revision("doc1", "corrected", 2, 5, 200)},
+		"doc2": {revision("doc2", "late", 1, 7, 300)},
+	}
+	return &MockRevisionDB{
+		MockAccountDB: MockAccountDB{
+			GetAllCustomFunction: func(ctx context.Context, pageNr, pageSize int) ([]*models.AccountInfo, error) {
+				return []*models.AccountInfo{history["doc1"][1], history["doc2"][0]}, nil
+			},
+			GetByCustomFunction: func(ctx context.Context, id uint) (*models.AccountInfo, error) {
+				return history["doc1"][1], nil
+			},
+		},
+		RevisionsCustomFunction: func(ctx context.Context, documentId string) ([]*models.AccountInfo, error) {
+			return history[documentId], nil
+		},
+	}
+}
+
+func TestAccountService_GetAccountInfoByIdAsOf(t *testing.T) {
+	tests := []struct {
+		name             string
+		asOf             models.AsOf
+		expectedRevision uint64
+		expectedError    error
+	}{
+		{
+			name:             "Test_By_Transaction_Before_Correction",
+			asOf:             models.AsOf{TransactionId: 4},
+			expectedRevision: 1,
+		},
+		{
+			name:             "Test_By_Transaction_At_Correction",
+			asOf:             models.AsOf{TransactionId: 5},
+			expectedRevision: 2,
+		},
+		{
+			name:             "Test_By_Timestamp",
+			asOf:             models.AsOf{Timestamp: unixTime(150)},
+			expectedRevision: 1,
+		},
+		{
+			name:          "Test_Before_Creation",
+			asOf:          models.AsOf{Timestamp: unixTime(50)},
+			expectedError: cerror.NewServiceError("account info did not exist at the requested point in time", http.StatusNotFound),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			service, err := NewService(newHistoryDB())
+			assert.NoError(t, err, "error setting up the service")
+
+			// action
+			info, err := service.GetAccountInfoByIdAsOf(context.Background(), 1, tt.asOf)
+
+			//assert
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, err)
+				return
+			}
+			assert.NoError(t, err, "error loading account")
+			assert.Equal(t, tt.expectedRevision, info.Meta.Revision)
+		})
+	}
+}
+
+func TestAccountService_GetAllAccountInfosAsOf(t *testing.T) {
+	// setup
+	service, err := NewService(newHistoryDB())
+	assert.NoError(t, err, "error setting up the service")
+
+	// action
+	infos, err := service.GetAllAccountInfosAsOf(context.Background(), 1, 10, models.AsOf{TransactionId: 3})
+
+	//assert
+	assert.NoError(t, err, "error loading account infos")
+	assert.Equal(t, []*models.AccountInfo{revision("doc1", "first", 1, 2, 100)}, infos, "doc2 did not exist at transaction 3")
+}
+
+// newInterleavedDB has total accounts in store order, the odd ones were created after transaction 1000
+func newInterleavedDB(total int) *MockRevisionDB {
+	account := func(id int) *models.AccountInfo {
+		transactionId := uint64(id)
+		if id%2 == 1 {
+			transactionId += 1000
+		}
+		return &models.AccountInfo{Id: uint(id), DocumentId: fmt.Sprint("doc", id), Meta: &models.DocumentMeta{Revision: 1, TransactionId: transactionId}}
+	}
+	return &MockRevisionDB{
+		MockAccountDB: MockAccountDB{
+			GetAllCustomFunction: func(ctx context.Context, pageNr, pageSize int) ([]*models.AccountInfo, error) {
+				var page []*models.AccountInfo
+				for id := (pageNr-1)*pageSize + 1; id <= total && len(page) < pageSize; id++ {
+					page = append(page, account(id))
+				}
+				return page, nil
+			},
+		},
+		RevisionsCustomFunction: func(ctx context.Context, documentId string) ([]*models.AccountInfo, error) {
+			var id int
+			_, err := fmt.Sscanf(documentId, "doc%d", &id)
+			return []*models.AccountInfo{account(id)}, err
+		},
+	}
+}
+
+func TestAccountService_GetAllAccountInfosAsOf_Paged(t *testing.T) {
+	// setup
+	service := &AccountService{Db: newInterleavedDB(250)}
+	tests := []struct {
+		name        string
+		page        int
+		expectedIds []uint
+	}{
+		{
+			name:        "Test_Page_Across_Store_Pages",
+			page:        2,
+			expectedIds: evenIds(102, 200),
+		},
+		{
+			name:        "Test_Last_Page",
+			page:        3,
+			expectedIds: evenIds(202, 250),
+		},
+		{
+			name: "Test_Past_The_End",
+			page: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// action
+			infos, err := service.GetAllAccountInfosAsOf(context.Background(), tt.page, 50, models.AsOf{TransactionId: 1000})
+
+			// assertions
+			assert.NoError(t, err)
+			var ids []uint
+			for _, info := range infos {
+				ids = append(ids, info.Id)
+			}
+			assert.Equal(t, tt.expectedIds, ids, "a page holds pageSize accounts that existed at the point")
+		})
+	}
+}
+
+func evenIds(from, to uint) []uint {
+	var ids []uint
+	for id := from; id <= to; id += 2 {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestAccountService_AsOf_NotSupported(t *testing.T) {
+	// setup
+	service, err := NewService(&MockAccountDB{})
+	assert.NoError(t, err, "error setting up the service")
+
+	// action
+	_, err = service.GetAccountInfoByIdAsOf(context.Background(), 1, models.AsOf{TransactionId: 1})
+
+	//assert
+	assert.Equal(t, cerror.NewServiceError("revision history is not supported by the configured backend", http.StatusNotImplemented), err)
+}
+
+func unixTime(ts int64) *time.Time {
+	value := time.Unix(ts, 0).UTC()
+	return &value
+}
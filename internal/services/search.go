@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"immudb/internal/errors"
+	"immudb/internal/models"
+	"net/http"
+)
+
+const maxSearchPageSize = 100
+
+// ReadModel serves the queries the vault can't answer, it trails the vault by the projection interval
+type ReadModel interface {
+	SearchAccountInfos(ctx context.Context, search models.AccountSearch) ([]*models.AccountInfo, error)
+	GetAccountStats(ctx context.Context) ([]models.AccountStats, error)
+}
+
+func (s *AccountService) SearchAccountInfos(ctx context.Context, search models.AccountSearch) ([]*models.AccountInfo, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	if s.ReadModel == nil {
+		return nil, errors.NewServiceError("search needs the read model, it is not configured", http.StatusNotImplemented)
+	}
+
+	var invalidParams []errors.InvalidParam
+	if search.Page < 1 {
+		invalidParams = append(invalidParams, errors.InvalidParam{Name: "page", Reason: "page must be greater than 0"})
+	}
+	if search.PageSize < 1 || search.PageSize > maxSearchPageSize {
+		invalidParams = append(invalidParams, errors.InvalidParam{Name: "pageSize", Reason: "pageSize must be between 1 and 100"})
+	}
+	if search.Type != nil && *search.Type != models.Sending && *search.Type != models.Receiving {
+		invalidParams = append(invalidParams, errors.InvalidParam{Name: "type", Reason: "invalid account type"})
+	}
+	if search.MinAmount != nil && search.MaxAmount != nil && *search.MinAmount > *search.MaxAmount {
+		invalidParams = append(invalidParams, errors.InvalidParam{Name: "minAmount", Reason: "minAmount can't be bigger than maxAmount"})
+	}
+	if len(invalidParams) > 0 {
+		return nil, errors.NewValidationError(http.StatusBadRequest, invalidParams...)
+	}
+	return s.ReadModel.SearchAccountInfos(ctx, search)
+}
+
+func (s *AccountService) GetAccountStats(ctx context.Context) ([]models.AccountStats, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	if s.ReadModel == nil {
+		return nil, errors.NewServiceError("stats need the read model, it is not configured", http.StatusNotImplemented)
+	}
+	return s.ReadModel.GetAccountStats(ctx)
+}
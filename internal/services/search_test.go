@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	cerror "immudb/internal/errors"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"net/http"
+	"testing"
+)
+
+type MockReadModel struct {
+	SearchCustomFunction func(ctx context.Context, search models.AccountSearch) ([]*models.AccountInfo, error)
+}
+
+func (m *MockReadModel) SearchAccountInfos(ctx context.Context, search models.AccountSearch) ([]*models.AccountInfo, error) {
+	return m.SearchCustomFunction(ctx, search)
+}
+
+func (m *MockReadModel) GetAccountStats(ctx context.Context) ([]models.AccountStats, error) {
+	return nil, nil
+}
+
+func TestAccountService_SearchAccountInfos(t *testing.T) {
+	readModel := &MockReadModel{
+		SearchCustomFunction: func(ctx context.Context, search models.AccountSearch) ([]*models.AccountInfo, error) {
+			return []*models.AccountInfo{{Id: 1, Name: search.Text}}, nil
+		},
+	}
+	tests := []struct {
+		name           string
+		readModel      ReadModel
+		search         models.AccountSearch
+		expectedError  error
+		expectedResult []*models.AccountInfo
+	}{
+		{
+			name:           "Test_Validity",
+			readModel:      readModel,
+			search:         models.AccountSearch{Text: "julian", Type: persistance.AddPointer(models.Sending), Page: 1, PageSize: 10},
+			expectedResult: []*models.AccountInfo{{Id: 1, Name: "julian"}},
+		},
+		{
+			name:          "Test_Not_Configured",
+			search:        models.AccountSearch{Page: 1, PageSize: 10},
+			expectedError: cerror.NewServiceError("search needs the read model, it is not configured", http.StatusNotImplemented),
+		},
+		{
+			name:      "Test_Invalid_Filters",
+			readModel: readModel,
+			search: models.AccountSearch{
+				Type:      persistance.AddPointer(models.AccountType(5)),
+				MinAmount: persistance.AddPointer(10.0),
+				MaxAmount: persistance.AddPointer(1.0),
+				PageSize:  101,
+			},
+			expectedError: cerror.NewValidationError(http.StatusBadRequest,
+				cerror.InvalidParam{Name: "page", Reason: "page must be greater than 0"},
+				cerror.InvalidParam{Name: "pageSize", Reason: "pageSize must be between 1 and 100"},
+				cerror.InvalidParam{Name: "type", Reason: "invalid account type"},
+				cerror.InvalidParam{Name: "minAmount", Reason: "minAmount can't be bigger than maxAmount"}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			service, err := NewService(&MockAccountDB{})
+			assert.NoError(t, err, "error setting up the service")
+			service.ReadModel = tt.readModel
+
+			// action
+			result, err := service.SearchAccountInfos(context.Background(), tt.search)
+
+			//assert
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, err)
+				return
+			}
+			assert.NoError(t, err, "error searching accounts")
+			assert.Equal(t, tt.expectedResult, result)
+		})
+	}
+}
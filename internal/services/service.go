@@ -2,20 +2,44 @@ package services
 
 import (
 	"context"
+	"immudb/internal/events"
 	"immudb/internal/models"
 	"immudb/internal/persistance"
 )
 
 type Service interface {
 	CreateAccountInfo(ctx context.Context, ata *models.AccountInfo) (*models.AccountInfo, error)
+	CreateAccountInfos(ctx context.Context, data []*models.AccountInfo, atomic bool) ([]models.BatchItem, error)
+	ValidateAccountInfo(data *models.AccountInfo) error
+	UpdateAccountInfo(ctx context.Context, Id uint, data *models.AccountInfo, expectedRevision uint64) (*models.AccountInfo, error)
 	GetAllAccountInfos(ctx context.Context, page, pageSize int) ([]*models.AccountInfo, error)
 	GetAccountInfoById(ctx context.Context, Id uint) (*models.AccountInfo, error)
+	GetAccountInfoByDocumentId(ctx context.Context, documentId string) (*models.AccountInfo, error)
+	GetAllAccountInfosAsOf(ctx context.Context, page, pageSize int, asOf models.AsOf) ([]*models.AccountInfo, error)
+	ExportAccountInfos(ctx context.Context, asOf *models.AsOf, write func(page []*models.AccountInfo) error) error
+	GetAccountInfoByIdAsOf(ctx context.Context, Id uint, asOf models.AsOf) (*models.AccountInfo, error)
+	GetAccountInfoHistory(ctx context.Context, Id uint) ([]*models.AccountInfo, error)
+	DiffAccountInfo(ctx context.Context, Id uint, from, to uint64) (*models.AccountDiff, error)
+	GetVerifiedAccountInfoById(ctx context.Context, Id uint) (*models.AccountInfo, *models.Verification, error)
+	SearchAccountInfos(ctx context.Context, search models.AccountSearch) ([]*models.AccountInfo, error)
+	GetAccountStats(ctx context.Context) ([]models.AccountStats, error)
 }
 
 type AccountService struct {
-	Db persistance.AccountDB
+	Db           persistance.AccountDB
+	AllowUpdates bool
+	ReadModel    ReadModel        // nil when the SQL read model is not configured
+	Events       events.Publisher // nil when nothing listens to account events
 }
 
 func NewService(db persistance.AccountDB) (*AccountService, error) {
 	return &AccountService{Db: db}, nil
 }
+
+// publish tells the listeners about a committed write
+func (s *AccountService) publish(eventType models.EventType, account *models.AccountInfo) {
+	if s.Events == nil || account == nil {
+		return
+	}
+	s.Events.Publish(events.NewAccountEvent(eventType, *account))
+}
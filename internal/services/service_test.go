@@ -22,3 +22,30 @@ func (m *MockAccountDB) GetAllAccountInfos(ctx context.Context, pageNr, pageSize
 func (m *MockAccountDB) GetAccountInfoById(ctx context.Context, id uint) (*models.AccountInfo, error) {
 	return m.GetByCustomFunction(ctx, id)
 }
+
+type MockRevisionDB struct {
+	MockAccountDB
+	RevisionsCustomFunction func(ctx context.Context, documentId string) ([]*models.AccountInfo, error)
+}
+
+func (m *MockRevisionDB) GetAccountInfoRevisions(ctx context.Context, documentId string) ([]*models.AccountInfo, error) {
+	return m.RevisionsCustomFunction(ctx, documentId)
+}
+
+type MockUpdatableDB struct {
+	MockAccountDB
+	UpdateCustomFunction func(ctx context.Context, id uint, expectedRevision uint64, info models.AccountInfo) (*models.AccountInfo, error)
+}
+
+func (m *MockUpdatableDB) UpdateAccountInfo(ctx context.Context, id uint, expectedRevision uint64, info models.AccountInfo) (*models.AccountInfo, error) {
+	return m.UpdateCustomFunction(ctx, id, expectedRevision, info)
+}
+
+type MockBatchDB struct {
+	MockAccountDB
+	CreateBatchCustomFunction func(ctx context.Context, infos []models.AccountInfo) ([]*models.AccountInfo, error)
+}
+
+func (m *MockBatchDB) CreateAccountInfos(ctx context.Context, infos []models.AccountInfo) ([]*models.AccountInfo, error) {
+	return m.CreateBatchCustomFunction(ctx, infos)
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"immudb/internal/errors"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"net/http"
+)
+
+// UpdateAccountInfo writes a new revision of the account, expectedRevision is the revision the caller last saw
+// so concurrent editors can't silently overwrite each other
+func (s *AccountService) UpdateAccountInfo(ctx context.Context, Id uint, data *models.AccountInfo, expectedRevision uint64) (*models.AccountInfo, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	if !s.AllowUpdates {
+		return nil, errors.NewServiceError("update is not allowed", http.StatusMethodNotAllowed)
+	}
+	if expectedRevision == 0 {
+		return nil, errors.NewServiceError("If-Match with the ETag of the revision being updated is required", http.StatusPreconditionRequired)
+	}
+	err := s.validateUpdate(Id, data)
+	if err != nil {
+		return nil, err
+	}
+	db, ok := persistance.As[persistance.UpdatableDB](s.Db)
+	if !ok {
+		return nil, errors.NewServiceError("updates are not supported by the configured backend", http.StatusNotImplemented)
+	}
+	updated, err := db.UpdateAccountInfo(ctx, Id, expectedRevision, *data)
+	if err != nil {
+		return nil, err
+	}
+	s.publish(models.AccountUpdated, updated)
+	return updated, nil
+}
+
+func (s *AccountService) validateUpdate(Id uint, data *models.AccountInfo) error {
+	if data == nil {
+		return errors.NewServiceError("invalid input", http.StatusBadRequest)
+	}
+
+	var invalidParams []errors.InvalidParam
+	if data.Id != 0 && data.Id != Id {
+		invalidParams = append(invalidParams, errors.InvalidParam{Name: "account_number", Reason: "accountNumber can't be changed"})
+	}
+	invalidParams = append(invalidParams, s.validateAccountFields(data)...)
+
+	if len(invalidParams) > 0 {
+		return errors.NewValidationError(http.StatusBadRequest, invalidParams...)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	cerror "immudb/internal/errors"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"net/http"
+	"testing"
+)
+
+func TestAccountService_UpdateAccountInfo(t *testing.T) {
+	validRequest := &models.AccountInfo{
+		Name: "as",
+		Iban: "a",
+		Type: persistance.AddPointer(models.Receiving),
+	}
+	updatableDB := &MockUpdatableDB{
+		UpdateCustomFunction: func(ctx context.Context, id uint, expectedRevision uint64, info models.AccountInfo) (*models.AccountInfo, error) {
+			if expectedRevision != 2 {
+				return nil, cerror.NewServiceError("account info was modified, reload it and apply your changes again", http.StatusPreconditionFailed)
+			}
+			info.Id = id
+			info.Meta = &models.DocumentMeta{Revision: 3}
+			return &info, nil
+		},
+	}
+	tests := []struct {
+		name             string
+		accountDb        persistance.AccountDB
+		allowUpdates     bool
+		request          *models.AccountInfo
+		expectedRevision uint64
+		expectedError    error
+		expectedResult   *models.AccountInfo
+	}{
+		{
+			name:             "Test_Validity",
+			accountDb:        updatableDB,
+			allowUpdates:     true,
+			request:          validRequest,
+			expectedRevision: 2,
+			expectedResult: &models.AccountInfo{
+				Id:   1,
+				Name: "as",
+				Iban: "a",
+				Type: persistance.AddPointer(models.Receiving),
+				Meta: &models.DocumentMeta{Revision: 3},
+			},
+		},
+		{
+			name:             "Test_Stale_Revision",
+			accountDb:        updatableDB,
+			allowUpdates:     true,
+			request:          validRequest,
+			expectedRevision: 1,
+			expectedError:    cerror.NewServiceError("account info was modified, reload it and apply your changes again", http.StatusPreconditionFailed),
+		},
+		{
+			name:          "Test_Missing_If_Match",
+			accountDb:     updatableDB,
+			allowUpdates:  true,
+			request:       validRequest,
+			expectedError: cerror.NewServiceError("If-Match with the ETag of the revision being updated is required", http.StatusPreconditionRequired),
+		},
+		{
+			name:             "Test_Updates_Not_Allowed",
+			accountDb:        updatableDB,
+			request:          validRequest,
+			expectedRevision: 2,
+			expectedError:    cerror.NewServiceError("update is not allowed", http.StatusMethodNotAllowed),
+		},
+		{
+			name:             "Test_Account_Number_Changed",
+			accountDb:        updatableDB,
+			allowUpdates:     true,
+			request:          &models.AccountInfo{Id: 7, Name: "as", Iban: "a", Type: persistance.AddPointer(models.Receiving)},
+			expectedRevision: 2,
+			expectedError: cerror.NewValidationError(http.StatusBadRequest,
+				cerror.InvalidParam{Name: "account_number", Reason: "accountNumber can't be changed"}),
+		},
+		{
+			name:             "Test_Not_Supported",
+			accountDb:        &MockAccountDB{},
+			allowUpdates:     true,
+			request:          validRequest,
+			expectedRevision: 2,
+			expectedError:    cerror.NewServiceError("updates are not supported by the configured backend", http.StatusNotImplemented),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			service, err := NewService(tt.accountDb)
+			assert.NoError(t, err, "error setting up the service")
+			service.AllowUpdates = tt.allowUpdates
+
+			// action
+			info, err := service.UpdateAccountInfo(context.Background(), 1, tt.request, tt.expectedRevision)
+
+			//assert
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, err)
+				return
+			}
+			assert.NoError(t, err, "error updating account")
+			assert.Equal(t, tt.expectedResult, info)
+		})
+	}
+}
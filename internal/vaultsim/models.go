@@ -0,0 +1,92 @@
+package vaultsim
+
+// the wire types are kept apart from the client ones on purpose, the simulator has to speak the vault
+// protocol and not whatever the client happens to send
+
+type CreateResponse struct {
+	DocumentID    string `json:"documentId"`
+	TransactionID string `json:"transactionId"`
+}
+
+type CreateDocumentsRequest struct {
+	Documents []map[string]interface{} `json:"documents"`
+}
+
+type CreateDocumentsResponse struct {
+	DocumentIDs   []string `json:"documentIds"`
+	TransactionID string   `json:"transactionId"`
+}
+
+type ReplaceRequest struct {
+	Query    *Query                 `json:"query"`
+	Document map[string]interface{} `json:"document"`
+}
+
+type ReplaceResponse struct {
+	Revisions []ReplacedRevision `json:"revisions"`
+}
+
+type ReplacedRevision struct {
+	DocumentID    string `json:"documentId"`
+	Revision      string `json:"revision"`
+	TransactionID string `json:"transactionId"`
+}
+
+type SearchRequest struct {
+	Query   *Query `json:"query"`
+	Page    int    `json:"page"`
+	PerPage int    `json:"perPage"`
+}
+
+type AuditRequest struct {
+	Desc    bool `json:"desc"`
+	Page    int  `json:"page"`
+	PerPage int  `json:"perPage"`
+}
+
+type FieldComparison struct {
+	Field    string      `json:"field"`
+	Operator string      `json:"operator"`
+	Value    interface{} `json:"value"`
+}
+
+// Expression matches when all of its comparisons match
+type Expression struct {
+	FieldComparisons []FieldComparison `json:"fieldComparisons"`
+}
+
+type OrderBy struct {
+	Desc  bool   `json:"desc"`
+	Field string `json:"field"`
+}
+
+// Query matches when any of its expressions match, an empty query matches every document
+type Query struct {
+	Expressions []Expression `json:"expressions"`
+	Limit       int          `json:"limit"`
+	OrderBy     []OrderBy    `json:"orderBy"`
+}
+
+type SearchResponse struct {
+	Page      int        `json:"page"`
+	PerPage   int        `json:"perPage"`
+	Revisions []Revision `json:"revisions"`
+	SearchID  string     `json:"searchId"`
+}
+
+type Revision struct {
+	Document      map[string]interface{} `json:"document"`
+	Revision      string                 `json:"revision"`
+	TransactionID string                 `json:"transactionId"`
+}
+
+type VaultMd struct {
+	Creator string `json:"creator"`
+	Ts      int64  `json:"ts"`
+}
+
+type ErrorResponse struct {
+	ErrorCode string `json:"errorCode"`
+	Error     string `json:"error"`
+	Status    int    `json:"status"`
+}
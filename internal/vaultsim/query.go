@@ -0,0 +1,195 @@
+package vaultsim
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var operators = map[string]bool{"EQ": true, "NE": true, "LT": true, "LE": true, "GT": true, "GE": true, "LIKE": true}
+
+// validate rejects the queries the vault would reject before touching any document
+func (q *Query) validate() error {
+	if q == nil {
+		return nil
+	}
+	if q.Limit < 0 {
+		return fmt.Errorf("limit can't be negative")
+	}
+	for _, expression := range q.Expressions {
+		for _, comparison := range expression.FieldComparisons {
+			if comparison.Field == "" {
+				return fmt.Errorf("field is required")
+			}
+			if !operators[comparison.Operator] {
+				return fmt.Errorf("unknown operator %q", comparison.Operator)
+			}
+			if comparison.Operator == "LIKE" {
+				pattern, ok := comparison.Value.(string)
+				if !ok {
+					return fmt.Errorf("LIKE needs a string pattern")
+				}
+				if _, err := regexp.Compile(pattern); err != nil {
+					return fmt.Errorf("invalid LIKE pattern: %w", err)
+				}
+			}
+		}
+		if len(expression.FieldComparisons) == 0 {
+			return fmt.Errorf("an expression needs at least one field comparison")
+		}
+	}
+	for _, order := range q.OrderBy {
+		if order.Field == "" {
+			return fmt.Errorf("orderBy field is required")
+		}
+	}
+	return nil
+}
+
+func (q *Query) matches(document map[string]interface{}) bool {
+	if q == nil || len(q.Expressions) == 0 {
+		return true
+	}
+	for _, expression := range q.Expressions {
+		if expression.matches(document) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e Expression) matches(document map[string]interface{}) bool {
+	for _, comparison := range e.FieldComparisons {
+		if !comparison.matches(document) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches a document without the field never matches, like the vault does
+func (f FieldComparison) matches(document map[string]interface{}) bool {
+	value, ok := fieldValue(document, f.Field)
+	if !ok {
+		return false
+	}
+	if f.Operator == "LIKE" {
+		text, ok := value.(string)
+		if !ok {
+			return false
+		}
+		matched, _ := regexp.MatchString(f.Value.(string), text)
+		return matched
+	}
+	result, comparable := compareValues(value, f.Value)
+	switch f.Operator {
+	case "EQ":
+		return comparable && result == 0
+	case "NE":
+		return !comparable || result != 0
+	case "LT":
+		return comparable && result < 0
+	case "LE":
+		return comparable && result <= 0
+	case "GT":
+		return comparable && result > 0
+	case "GE":
+		return comparable && result >= 0
+	}
+	return false
+}
+
+// sortRevisions orders by the query fields, ties and queries without an order keep the creation order
+func sortRevisions(revisions []*revision, orderBy []OrderBy) {
+	sort.SliceStable(revisions, func(i, j int) bool {
+		for _, order := range orderBy {
+			left, _ := fieldValue(revisions[i].document, order.Field)
+			right, _ := fieldValue(revisions[j].document, order.Field)
+			result := compareForOrder(left, right)
+			if result == 0 {
+				continue
+			}
+			if order.Desc {
+				return result > 0
+			}
+			return result < 0
+		}
+		return false
+	})
+}
+
+// fieldValue resolves dotted paths like _vault_md.ts
+func fieldValue(document map[string]interface{}, field string) (interface{}, bool) {
+	var current interface{} = document
+	for _, part := range strings.Split(field, ".") {
+		fields, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = fields[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// compareValues compares values of the same json type, numbers are compared exactly so big ids don't collide
+func compareValues(left, right interface{}) (int, bool) {
+	switch l := left.(type) {
+	case nil:
+		return 0, right == nil
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(l, r), true
+	case bool:
+		r, ok := right.(bool)
+		if !ok || l != r {
+			return 1, ok
+		}
+		return 0, true
+	case json.Number:
+		leftNumber, ok := toNumber(l)
+		if !ok {
+			return 0, false
+		}
+		rightNumber, ok := toNumber(right)
+		if !ok {
+			return 0, false
+		}
+		return leftNumber.Cmp(rightNumber), true
+	}
+	return 0, false
+}
+
+// compareForOrder puts missing and null values first and otherwise orders by type name so the sort is total
+func compareForOrder(left, right interface{}) int {
+	if result, ok := compareValues(left, right); ok {
+		return result
+	}
+	if left == nil {
+		return -1
+	}
+	if right == nil {
+		return 1
+	}
+	return strings.Compare(fmt.Sprintf("%T", left), fmt.Sprintf("%T", right))
+}
+
+func toNumber(value interface{}) (*big.Float, bool) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return nil, false
+	}
+	result, _, err := big.ParseFloat(number.String(), 10, 256, big.ToNearestEven)
+	if err != nil {
+		return nil, false
+	}
+	return result, true
+}
@@ -0,0 +1,382 @@
+// Package vaultsim emulates the immudb vault document REST API in process, so the client can be exercised
+// over real http without the network. Like the vault every write request is one transaction, a bulk create
+// or a replace matching many documents commits all of them together. The proof endpoint is not emulated.
+package vaultsim
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	DocumentPath  = "/ics/api/v1/ledger/default/collection/default/document"
+	DocumentsPath = "/ics/api/v1/ledger/default/collection/default/documents"
+	SearchPath    = DocumentsPath + "/search"
+
+	maxPerPage           = 100
+	maxDocumentsPerWrite = 100
+	creator              = "a:vaultsim"
+)
+
+type revision struct {
+	document      map[string]interface{}
+	revision      uint64
+	transactionId uint64
+}
+
+type Server struct {
+	mx           sync.Mutex
+	apiKey       string
+	transactions uint64                 // id of the last committed transaction
+	documents    map[string][]*revision // revisions of every document, oldest first
+	order        []string               // document ids in creation order
+	failures     []int                  // statuses returned by the next requests, see FailNext
+	now          func() time.Time       // the ledger clock, see SetClock
+	mux          *http.ServeMux
+	listener     net.Listener
+	server       *http.Server
+}
+
+// NewServer an empty apiKey accepts every request
+func NewServer(apiKey string) *Server {
+	s := &Server{
+		apiKey:    apiKey,
+		documents: map[string][]*revision{},
+		mux:       http.NewServeMux(),
+		now:       time.Now,
+	}
+	s.mux.HandleFunc("PUT "+DocumentPath, s.createDocument)
+	s.mux.HandleFunc("PUT "+DocumentsPath, s.createDocuments)
+	s.mux.HandleFunc("POST "+DocumentPath, s.replaceDocuments)
+	s.mux.HandleFunc("POST "+DocumentPath+"/{documentId}/audit", s.auditDocument)
+	s.mux.HandleFunc("POST "+SearchPath, s.searchDocuments)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.apiKey != "" && r.Header.Get("X-API-Key") != s.apiKey {
+		writeError(w, http.StatusUnauthorized, "invalid api key")
+		return
+	}
+	if status := s.nextFailure(); status != 0 {
+		writeError(w, status, "failure injected by the simulator")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// Start serves the simulator on addr, use 127.0.0.1:0 for a free port
+func (s *Server) Start(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	s.server = &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		_ = s.server.Serve(listener)
+	}()
+	return nil
+}
+
+func (s *Server) Close() error {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Shutdown(context.Background())
+}
+
+// URL the base url of a started simulator
+func (s *Server) URL() string {
+	if s.listener == nil {
+		return ""
+	}
+	return "http://" + s.listener.Addr().String()
+}
+
+// DocumentUrl and SearchUrl are the client urls for a simulator served on baseUrl
+func DocumentUrl(baseUrl string) string {
+	return baseUrl + DocumentPath
+}
+
+func SearchUrl(baseUrl string) string {
+	return baseUrl + SearchPath
+}
+
+// FailNext makes the next requests fail with the given statuses, one status per request
+func (s *Server) FailNext(statuses ...int) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.failures = append(s.failures, statuses...)
+}
+
+// SetClock replaces the clock the ledger time of new revisions is taken from
+func (s *Server) SetClock(now func() time.Time) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.now = now
+}
+
+func (s *Server) nextFailure() int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if len(s.failures) == 0 {
+		return 0
+	}
+	status := s.failures[0]
+	s.failures = s.failures[1:]
+	return status
+}
+
+func (s *Server) createDocument(w http.ResponseWriter, r *http.Request) {
+	document, err := decode[map[string]interface{}](r.Body)
+	if err != nil || document == nil {
+		writeError(w, http.StatusBadRequest, "the body must be a json document")
+		return
+	}
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	transactionId := s.begin()
+	documentId := s.newDocumentId(transactionId)
+	s.commit(documentId, 1, document, transactionId)
+	s.order = append(s.order, documentId)
+	writeJson(w, CreateResponse{DocumentID: documentId, TransactionID: formatUint(transactionId)})
+}
+
+// createDocuments is the bulk create, every document is committed in the same transaction
+func (s *Server) createDocuments(w http.ResponseWriter, r *http.Request) {
+	request, err := decode[CreateDocumentsRequest](r.Body)
+	if err != nil || len(request.Documents) == 0 {
+		writeError(w, http.StatusBadRequest, "the body must have at least one document")
+		return
+	}
+	if len(request.Documents) > maxDocumentsPerWrite {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("at most %d documents can be created at once", maxDocumentsPerWrite))
+		return
+	}
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	transactionId := s.begin()
+	response := CreateDocumentsResponse{DocumentIDs: make([]string, 0, len(request.Documents)), TransactionID: formatUint(transactionId)}
+	for _, document := range request.Documents {
+		documentId := s.newDocumentId(transactionId)
+		s.commit(documentId, 1, document, transactionId)
+		s.order = append(s.order, documentId)
+		response.DocumentIDs = append(response.DocumentIDs, documentId)
+	}
+	writeJson(w, response)
+}
+
+// replaceDocuments writes a new revision of every document matching the query, all in one transaction
+func (s *Server) replaceDocuments(w http.ResponseWriter, r *http.Request) {
+	request, err := decode[ReplaceRequest](r.Body)
+	if err != nil || request.Document == nil {
+		writeError(w, http.StatusBadRequest, "the body must have a query and a document")
+		return
+	}
+	if err = request.Query.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	matched := s.search(request.Query)
+	if len(matched) == 0 {
+		writeError(w, http.StatusNotFound, "no document matches the query")
+		return
+	}
+	transactionId := s.begin()
+	response := ReplaceResponse{Revisions: []ReplacedRevision{}}
+	for _, current := range matched {
+		documentId, _ := current.document["_id"].(string)
+		committed := s.commit(documentId, current.revision+1, request.Document, transactionId)
+		response.Revisions = append(response.Revisions, ReplacedRevision{
+			DocumentID:    documentId,
+			Revision:      formatUint(committed.revision),
+			TransactionID: formatUint(transactionId),
+		})
+	}
+	writeJson(w, response)
+}
+
+func (s *Server) searchDocuments(w http.ResponseWriter, r *http.Request) {
+	request, err := decode[SearchRequest](r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid search request")
+		return
+	}
+	if err = validatePaging(request.Page, request.PerPage); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err = request.Query.validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	matched := s.search(request.Query)
+	writeJson(w, SearchResponse{
+		Page:      request.Page,
+		PerPage:   request.PerPage,
+		Revisions: toSearchRevisions(page(matched, request.Page, request.PerPage)),
+	})
+}
+
+func (s *Server) auditDocument(w http.ResponseWriter, r *http.Request) {
+	request, err := decode[AuditRequest](r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid audit request")
+		return
+	}
+	if err = validatePaging(request.Page, request.PerPage); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	revisions, ok := s.documents[r.PathValue("documentId")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "document not found")
+		return
+	}
+	ordered := make([]*revision, 0, len(revisions))
+	for i := range revisions {
+		if request.Desc {
+			ordered = append(ordered, revisions[len(revisions)-1-i])
+		} else {
+			ordered = append(ordered, revisions[i])
+		}
+	}
+	writeJson(w, SearchResponse{
+		Page:      request.Page,
+		PerPage:   request.PerPage,
+		Revisions: toRevisions(page(ordered, request.Page, request.PerPage)),
+	})
+}
+
+// begin starts the transaction of a write request, it must be called with the lock held
+func (s *Server) begin() uint64 {
+	s.transactions++
+	return s.transactions
+}
+
+// commit stores a revision in the given transaction, it must be called with the lock held
+func (s *Server) commit(documentId string, revisionNumber uint64, fields map[string]interface{}, transactionId uint64) *revision {
+	document := make(map[string]interface{}, len(fields)+2)
+	for key, value := range fields {
+		document[key] = value
+	}
+	document["_id"] = documentId
+	document["_vault_md"] = map[string]interface{}{"creator": creator, "ts": json.Number(strconv.FormatInt(s.now().Unix(), 10))}
+	committed := &revision{document: document, revision: revisionNumber, transactionId: transactionId}
+	s.documents[documentId] = append(s.documents[documentId], committed)
+	return committed
+}
+
+// search returns the latest revision of the matching documents, it must be called with the lock held
+func (s *Server) search(query *Query) []*revision {
+	var matched []*revision
+	for _, documentId := range s.order {
+		revisions := s.documents[documentId]
+		latest := revisions[len(revisions)-1]
+		if query.matches(latest.document) {
+			matched = append(matched, latest)
+		}
+	}
+	if query == nil {
+		return matched
+	}
+	sortRevisions(matched, query.OrderBy)
+	if query.Limit > 0 && len(matched) > query.Limit {
+		matched = matched[:query.Limit]
+	}
+	return matched
+}
+
+func validatePaging(page, perPage int) error {
+	if page < 1 {
+		return errors.New("page must be greater than 0")
+	}
+	if perPage < 1 || perPage > maxPerPage {
+		return fmt.Errorf("perPage must be between 1 and %d", maxPerPage)
+	}
+	return nil
+}
+
+func page(revisions []*revision, page, perPage int) []*revision {
+	start := (page - 1) * perPage
+	if start >= len(revisions) {
+		return nil
+	}
+	return revisions[start:min(start+perPage, len(revisions))]
+}
+
+// toSearchRevisions the vault search leaves revision and transactionId empty, only the audit log reports them
+func toSearchRevisions(revisions []*revision) []Revision {
+	output := make([]Revision, 0, len(revisions))
+	for _, value := range revisions {
+		output = append(output, Revision{Document: value.document})
+	}
+	return output
+}
+
+func toRevisions(revisions []*revision) []Revision {
+	output := make([]Revision, 0, len(revisions))
+	for _, value := range revisions {
+		output = append(output, Revision{
+			Document:      value.document,
+			Revision:      formatUint(value.revision),
+			TransactionID: formatUint(value.transactionId),
+		})
+	}
+	return output
+}
+
+// newDocumentId has the shape of the vault ids, seconds, transaction and random bits in hex.
+// The documents of one transaction only differ in the random bits, a clash is drawn again.
+func (s *Server) newDocumentId(transactionId uint64) string {
+	for {
+		random := make([]byte, 4)
+		_, _ = rand.Read(random)
+		documentId := fmt.Sprintf("%08x%016x%08x", time.Now().Unix(), transactionId, binary.BigEndian.Uint32(random))
+		if _, ok := s.documents[documentId]; !ok {
+			return documentId
+		}
+	}
+}
+
+// decode keeps numbers as json.Number so big account ids survive the round trip
+func decode[T any](body io.Reader) (T, error) {
+	var result T
+	decoder := json.NewDecoder(body)
+	decoder.UseNumber()
+	err := decoder.Decode(&result)
+	return result, err
+}
+
+func formatUint(value uint64) string {
+	return strconv.FormatUint(value, 10)
+}
+
+func writeJson(w http.ResponseWriter, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(ErrorResponse{ErrorCode: http.StatusText(status), Error: message, Status: status})
+}
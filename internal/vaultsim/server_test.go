@@ -0,0 +1,218 @@
+package vaultsim
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testApiKey = "test-key"
+
+func call[T any](t *testing.T, ts *httptest.Server, method, path string, body interface{}) (T, int) {
+	var result T
+	jsonData, err := json.Marshal(body)
+	assert.NoError(t, err)
+	req, err := http.NewRequest(method, ts.URL+path, bytes.NewReader(jsonData))
+	assert.NoError(t, err)
+	req.Header.Set("X-API-Key", testApiKey)
+	resp, err := ts.Client().Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		decoder := json.NewDecoder(resp.Body)
+		decoder.UseNumber()
+		assert.NoError(t, decoder.Decode(&result))
+	}
+	return result, resp.StatusCode
+}
+
+func newTestServer(t *testing.T, documents ...map[string]interface{}) (*httptest.Server, *Server, []CreateResponse) {
+	sim := NewServer(testApiKey)
+	ts := httptest.NewServer(sim)
+	t.Cleanup(ts.Close)
+	var created []CreateResponse
+	for _, document := range documents {
+		response, status := call[CreateResponse](t, ts, http.MethodPut, DocumentPath, document)
+		assert.Equal(t, http.StatusOK, status)
+		created = append(created, response)
+	}
+	return ts, sim, created
+}
+
+func names(revisions []Revision) []string {
+	var output []string
+	for _, revision := range revisions {
+		output = append(output, revision.Document["name"].(string))
+	}
+	return output
+}
+
+func TestServer_Search(t *testing.T) {
+	// setup
+	ts, _, _ := newTestServer(t,
+		map[string]interface{}{"id": uint64(1722699072077830001), "name": "a", "amount": 10},
+		map[string]interface{}{"id": uint64(1722699072077830002), "name": "b", "amount": 30},
+		map[string]interface{}{"id": uint64(1722699072077830003), "name": "c", "amount": 20},
+	)
+	tests := []struct {
+		name          string
+		request       SearchRequest
+		expectedNames []string
+	}{
+		{
+			name:          "Test_No_Query_Keeps_Creation_Order",
+			request:       SearchRequest{Page: 1, PerPage: 10},
+			expectedNames: []string{"a", "b", "c"},
+		},
+		{
+			name: "Test_Exact_Big_Id",
+			request: SearchRequest{Page: 1, PerPage: 10, Query: &Query{Expressions: []Expression{
+				{FieldComparisons: []FieldComparison{{Field: "id", Operator: "EQ", Value: uint64(1722699072077830002)}}},
+			}}},
+			expectedNames: []string{"b"},
+		},
+		{
+			name: "Test_Expressions_Are_Or_Comparisons_Are_And",
+			request: SearchRequest{Page: 1, PerPage: 10, Query: &Query{Expressions: []Expression{
+				{FieldComparisons: []FieldComparison{{Field: "amount", Operator: "GE", Value: 20}, {Field: "name", Operator: "NE", Value: "b"}}},
+				{FieldComparisons: []FieldComparison{{Field: "name", Operator: "LIKE", Value: "^a"}}},
+			}}},
+			expectedNames: []string{"a", "c"},
+		},
+		{
+			name:          "Test_Order_And_Limit",
+			request:       SearchRequest{Page: 1, PerPage: 10, Query: &Query{OrderBy: []OrderBy{{Field: "amount", Desc: true}}, Limit: 2}},
+			expectedNames: []string{"b", "c"},
+		},
+		{
+			name:          "Test_Paging",
+			request:       SearchRequest{Page: 2, PerPage: 2, Query: &Query{OrderBy: []OrderBy{{Field: "amount"}}}},
+			expectedNames: []string{"b"},
+		},
+		{
+			name: "Test_Missing_Field_Never_Matches",
+			request: SearchRequest{Page: 1, PerPage: 10, Query: &Query{Expressions: []Expression{
+				{FieldComparisons: []FieldComparison{{Field: "unknown", Operator: "NE", Value: 1}}},
+			}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// action
+			result, status := call[SearchResponse](t, ts, http.MethodPost, SearchPath, tt.request)
+
+			// assertions
+			assert.Equal(t, http.StatusOK, status)
+			assert.Equal(t, tt.expectedNames, names(result.Revisions))
+		})
+	}
+}
+
+func TestServer_ErrorStatuses(t *testing.T) {
+	// setup
+	ts, sim, _ := newTestServer(t, map[string]interface{}{"name": "a"})
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           interface{}
+		apiKey         string
+		failWith       int
+		expectedStatus int
+	}{
+		{name: "Test_Invalid_Api_Key", method: http.MethodPost, path: SearchPath, body: SearchRequest{Page: 1, PerPage: 1}, apiKey: "wrong", expectedStatus: http.StatusUnauthorized},
+		{name: "Test_Invalid_Page", method: http.MethodPost, path: SearchPath, body: SearchRequest{Page: 0, PerPage: 1}, expectedStatus: http.StatusBadRequest},
+		{name: "Test_Page_Too_Big", method: http.MethodPost, path: SearchPath, body: SearchRequest{Page: 1, PerPage: 101}, expectedStatus: http.StatusBadRequest},
+		{name: "Test_Unknown_Operator", method: http.MethodPost, path: SearchPath, body: SearchRequest{Page: 1, PerPage: 1, Query: &Query{Expressions: []Expression{{FieldComparisons: []FieldComparison{{Field: "a", Operator: "XX"}}}}}}, expectedStatus: http.StatusBadRequest},
+		{name: "Test_Document_Not_An_Object", method: http.MethodPut, path: DocumentPath, body: []int{1}, expectedStatus: http.StatusBadRequest},
+		{name: "Test_Unknown_Document_Audit", method: http.MethodPost, path: DocumentPath + "/unknown/audit", body: AuditRequest{Page: 1, PerPage: 1}, expectedStatus: http.StatusNotFound},
+		{name: "Test_Too_Many_Documents", method: http.MethodPut, path: DocumentsPath, body: CreateDocumentsRequest{Documents: make([]map[string]interface{}, maxDocumentsPerWrite+1)}, expectedStatus: http.StatusBadRequest},
+		{name: "Test_Replace_Without_Match", method: http.MethodPost, path: DocumentPath, body: ReplaceRequest{Query: &Query{Expressions: []Expression{{FieldComparisons: []FieldComparison{{Field: "name", Operator: "EQ", Value: "x"}}}}}, Document: map[string]interface{}{"name": "y"}}, expectedStatus: http.StatusNotFound},
+		{name: "Test_Wrong_Method", method: http.MethodGet, path: SearchPath, expectedStatus: http.StatusMethodNotAllowed},
+		{name: "Test_Injected_Failure", method: http.MethodPost, path: SearchPath, body: SearchRequest{Page: 1, PerPage: 1}, failWith: http.StatusTooManyRequests, expectedStatus: http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			if tt.failWith != 0 {
+				sim.FailNext(tt.failWith)
+			}
+			jsonData, err := json.Marshal(tt.body)
+			assert.NoError(t, err)
+			req, err := http.NewRequest(tt.method, ts.URL+tt.path, bytes.NewReader(jsonData))
+			assert.NoError(t, err)
+			req.Header.Set("X-API-Key", testApiKey)
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+
+			// action
+			resp, err := ts.Client().Do(req)
+
+			// assertions
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestServer_CreateDocuments(t *testing.T) {
+	// setup
+	ts, _, _ := newTestServer(t, map[string]interface{}{"id": 1, "name": "a"})
+
+	// action
+	created, status := call[CreateDocumentsResponse](t, ts, http.MethodPut, DocumentsPath, CreateDocumentsRequest{Documents: []map[string]interface{}{
+		{"id": 2, "name": "b"},
+		{"id": 3, "name": "c"},
+	}})
+	assert.Equal(t, http.StatusOK, status)
+	all, status := call[SearchResponse](t, ts, http.MethodPost, SearchPath, SearchRequest{Page: 1, PerPage: 10})
+	assert.Equal(t, http.StatusOK, status)
+
+	// assertions
+	assert.Equal(t, "2", created.TransactionID, "the bulk create is one transaction")
+	assert.Len(t, created.DocumentIDs, 2)
+	assert.NotEqual(t, created.DocumentIDs[0], created.DocumentIDs[1])
+	assert.Equal(t, []string{"a", "b", "c"}, names(all.Revisions))
+	for i, revision := range all.Revisions[1:] {
+		assert.Equal(t, created.DocumentIDs[i], revision.Document["_id"])
+		assert.Empty(t, revision.TransactionID, "the search doesn't report the transaction")
+		assert.Empty(t, revision.Revision, "the search doesn't report the revision")
+	}
+}
+
+func TestServer_ReplaceAndAudit(t *testing.T) {
+	// setup
+	ts, _, created := newTestServer(t,
+		map[string]interface{}{"id": 1, "name": "a", "group": "x"},
+		map[string]interface{}{"id": 2, "name": "b", "group": "x"},
+		map[string]interface{}{"id": 3, "name": "c", "group": "y"},
+	)
+	documentId := created[0].DocumentID
+
+	// action
+	replaced, status := call[ReplaceResponse](t, ts, http.MethodPost, DocumentPath, ReplaceRequest{
+		Query:    &Query{Expressions: []Expression{{FieldComparisons: []FieldComparison{{Field: "group", Operator: "EQ", Value: "x"}}}}},
+		Document: map[string]interface{}{"name": "replaced", "group": "x"},
+	})
+	assert.Equal(t, http.StatusOK, status)
+	audit, status := call[SearchResponse](t, ts, http.MethodPost, DocumentPath+"/"+documentId+"/audit", AuditRequest{Desc: true, Page: 1, PerPage: 10})
+	assert.Equal(t, http.StatusOK, status)
+
+	// assertions
+	assert.Equal(t, ReplaceResponse{Revisions: []ReplacedRevision{
+		{DocumentID: documentId, Revision: "2", TransactionID: "4"},
+		{DocumentID: created[1].DocumentID, Revision: "2", TransactionID: "4"},
+	}}, replaced, "every matched document is replaced in the same transaction")
+	assert.Equal(t, []string{"replaced", "a"}, names(audit.Revisions))
+	assert.Equal(t, "2", audit.Revisions[0].Revision)
+	assert.Equal(t, "4", audit.Revisions[0].TransactionID)
+	assert.Equal(t, "1", audit.Revisions[1].TransactionID)
+	assert.Equal(t, documentId, audit.Revisions[0].Document["_id"])
+}
@@ -0,0 +1,157 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"immudb/internal/events"
+	"immudb/internal/models"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderWebhookId = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+	userAgent       = "immudb-account-webhooks"
+	// maxResponseSize is read from every response and thrown away, so the connection can be reused
+	maxResponseSize = 64 << 10
+)
+
+// Start runs the delivery workers until ctx is done, the deliveries left pending by the last run are sent first
+func (m *Manager) Start(ctx context.Context) {
+	// taken before anything new is queued, so nothing is sent twice
+	pending := m.leftPending()
+	for i := 0; i < m.options.Workers; i++ {
+		go m.run(ctx)
+	}
+	go func() {
+		for _, delivery := range pending {
+			select {
+			case m.queue <- delivery:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (m *Manager) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case delivery := <-m.queue:
+			m.deliver(ctx, delivery)
+		}
+	}
+}
+
+// deliver makes one attempt, a failed one is retried after a backoff until the attempts run out
+func (m *Manager) deliver(ctx context.Context, delivery *models.WebhookDelivery) {
+	webhook, ok := m.webhook(delivery.WebhookId)
+	if !ok || !webhook.Active {
+		// deleted or deactivated since the event was queued
+		m.settle(delivery, true)
+		return
+	}
+	delivery.Attempts++
+	now := time.Now().UTC()
+	delivery.LastAttemptAt = &now
+	statusCode, err := m.send(ctx, webhook, delivery)
+	delivery.LastStatusCode = statusCode
+	if err == nil {
+		delivery.LastError = ""
+		m.settle(delivery, true)
+		return
+	}
+	delivery.LastError = err.Error()
+	if ctx.Err() != nil {
+		// still pending, sent again after the next start
+		return
+	}
+	if delivery.Attempts >= m.options.MaxAttempts {
+		m.deadLetter(delivery)
+		return
+	}
+	wait := m.backoff(delivery.Attempts)
+	logrus.WithError(err).WithFields(logrus.Fields{"webhook": delivery.WebhookId, "delivery": delivery.Id, "attempts": delivery.Attempts, "retryIn": wait}).
+		Info("webhook delivery failed")
+	// the attempts are saved so a restart doesn't start counting again
+	m.settle(delivery, false)
+	m.retry(ctx, delivery, wait)
+}
+
+// retry queues the delivery again after wait, while the queue is full it waits once more instead of giving up on it
+func (m *Manager) retry(ctx context.Context, delivery *models.WebhookDelivery, wait time.Duration) {
+	time.AfterFunc(wait, func() {
+		if ctx.Err() != nil {
+			return
+		}
+		select {
+		case m.queue <- delivery:
+		default:
+			m.retry(ctx, delivery, wait)
+		}
+	})
+}
+
+// backoff doubles the wait after every failed attempt
+func (m *Manager) backoff(attempts int) time.Duration {
+	wait := m.options.Backoff
+	for i := 1; i < attempts && wait < m.options.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, m.options.MaxBackoff)
+}
+
+// send posts the event, anything but a 2xx answer is a failure
+func (m *Manager) send(ctx context.Context, webhook models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(events.NewEnvelope(delivery.Event))
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, m.options.Timeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", userAgent)
+	request.Header.Set(HeaderWebhookId, webhook.Id)
+	request.Header.Set(HeaderEvent, string(delivery.Event.Type))
+	request.Header.Set(HeaderDelivery, delivery.Id)
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, body))
+	response, err := m.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, maxResponseSize))
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("the webhook answered %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
+
+// Sign is the HMAC-SHA256 of timestamp and body with the secret of the webhook, as sent in X-Webhook-Signature.
+// The timestamp is signed too so receivers can refuse replays of old deliveries.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = fmt.Fprintf(mac, "%d.", timestamp)
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
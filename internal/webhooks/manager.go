@@ -0,0 +1,607 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	cerror "immudb/internal/errors"
+	"immudb/internal/models"
+	"immudb/internal/persistance"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	journalFile = "webhooks.log"
+	// stateFile the whole state as older versions wrote it, read once when there is no journal yet
+	stateFile = "webhooks.json"
+	// compactAfter entries in the journal before it is rewritten with the current state
+	compactAfter = 1000
+	// maxDeadLetters dead letters beyond this are dropped, oldest first
+	maxDeadLetters  = 1000
+	queueSize       = 1000
+	minSecretLength = 16
+)
+
+var (
+	ErrWebhookNotFound  = cerror.NewServiceError("webhook not found", http.StatusNotFound)
+	ErrDeliveryNotFound = cerror.NewServiceError("dead letter not found", http.StatusNotFound)
+	ErrWebhookInactive  = cerror.NewServiceError("the webhook is not active", http.StatusConflict)
+	ErrQueueFull        = cerror.NewServiceError("the delivery queue is full, try again later", http.StatusServiceUnavailable)
+)
+
+// Options of the deliveries, zero values use the defaults
+type Options struct {
+	MaxAttempts int           // attempts before a delivery goes to the dead letters, 5 by default
+	Backoff     time.Duration // wait after the first failed attempt, doubled after every further one, 10s by default
+	MaxBackoff  time.Duration // the longest wait between two attempts, 10m by default
+	Timeout     time.Duration // of one attempt, 10s by default
+	Workers     int           // deliveries sent at the same time, 4 by default
+	// AllowedHosts webhooks may call although they are loopback, private or link-local: hosts, addresses and CIDR ranges
+	AllowedHosts []string
+}
+
+func (o Options) withDefaults() Options {
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 5
+	}
+	if o.Backoff <= 0 {
+		o.Backoff = 10 * time.Second
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = 10 * time.Minute
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 10 * time.Second
+	}
+	if o.Workers <= 0 {
+		o.Workers = 4
+	}
+	return o
+}
+
+const (
+	opWebhook    = "webhook"     // created or updated
+	opDelete     = "delete"      // the webhook with its dead letters and pending deliveries
+	opPending    = "pending"     // queued, after a failed attempt or redelivered off the dead letters
+	opDone       = "done"        // sent, or its webhook is gone
+	opDeadLetter = "dead-letter" // out of attempts
+)
+
+// entry is one change in the journal, what survives a restart
+type entry struct {
+	Op       string                  `json:"op"`
+	Webhook  *models.Webhook         `json:"webhook,omitempty"`
+	Delivery *models.WebhookDelivery `json:"delivery,omitempty"`
+	Id       string                  `json:"id,omitempty"`
+}
+
+// state is what older versions saved instead of the journal
+type state struct {
+	Webhooks    []*models.Webhook
+	DeadLetters []*models.WebhookDelivery // oldest first
+	Pending     []*models.WebhookDelivery // queued or waiting for a retry, sent again after a restart
+}
+
+// Manager keeps the webhook subscriptions and delivers the account events to them.
+// Every change to the subscriptions, dead letters and pending deliveries is appended to a journal in dir,
+// only subscriptions and new deliveries are synced, losing the outcome of an attempt at worst sends it again.
+type Manager struct {
+	mx          sync.Mutex
+	path        string
+	file        *os.File
+	entries     int // in the journal since it was last compacted
+	options     Options
+	targets     *targets
+	client      *http.Client
+	webhooks    map[string]*models.Webhook
+	deadLetters []*models.WebhookDelivery
+	pending     map[string]models.WebhookDelivery // by delivery id, copies so saving doesn't race the workers
+	queue       chan *models.WebhookDelivery
+}
+
+func NewManager(dir string, options Options) (*Manager, error) {
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, err
+	}
+	targets := newTargets(options.AllowedHosts)
+	// no proxy, it would connect to the addresses the targets refuse
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = targets.dial
+	m := &Manager{
+		path:     filepath.Join(dir, journalFile),
+		options:  options.withDefaults(),
+		targets:  targets,
+		client:   &http.Client{Transport: transport},
+		webhooks: map[string]*models.Webhook{},
+		pending:  map[string]models.WebhookDelivery{},
+		queue:    make(chan *models.WebhookDelivery, queueSize),
+	}
+	err = m.load(filepath.Join(dir, stateFile))
+	if err != nil {
+		return nil, err
+	}
+	m.file, err = openJournal(m.path)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func openJournal(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+}
+
+// load replays the journal, without one the state file of an older version is turned into the journal
+func (m *Manager) load(statePath string) error {
+	data, err := os.ReadFile(m.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m.loadState(statePath)
+	}
+	if err != nil {
+		return err
+	}
+	// a crash while appending leaves the last line cut short
+	if complete := bytes.LastIndexByte(data, '\n') + 1; complete < len(data) {
+		logrus.WithField("bytes", len(data)-complete).Warn("dropped the torn end of the webhooks journal")
+		err = os.Truncate(m.path, int64(complete))
+		if err != nil {
+			return err
+		}
+		data = data[:complete]
+	}
+	for i, line := range bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var e entry
+		err = json.Unmarshal(line, &e)
+		if err != nil {
+			return fmt.Errorf("corrupted webhooks journal %s at line %d: %w", m.path, i+1, err)
+		}
+		m.apply(e)
+		m.entries++
+	}
+	return nil
+}
+
+func (m *Manager) loadState(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var saved state
+	err = json.Unmarshal(data, &saved)
+	if err != nil {
+		return err
+	}
+	for _, webhook := range saved.Webhooks {
+		m.webhooks[webhook.Id] = webhook
+	}
+	m.deadLetters = saved.DeadLetters
+	for _, delivery := range saved.Pending {
+		m.pending[delivery.Id] = *delivery
+	}
+	err = m.compact()
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func (m *Manager) Create(webhook models.Webhook) (models.Webhook, error) {
+	err := m.validate(&webhook)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	if webhook.Secret == "" {
+		webhook.Secret = newId(32)
+	}
+	webhook.Id = newId(16)
+	webhook.CreatedAt = time.Now().UTC()
+	webhook.UpdatedAt = webhook.CreatedAt
+
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	err = m.write(true, entry{Op: opWebhook, Webhook: &webhook})
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// List returns the webhooks, oldest first
+func (m *Manager) List() []models.Webhook {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	result := make([]models.Webhook, 0, len(m.webhooks))
+	for _, webhook := range m.webhooks {
+		result = append(result, *webhook)
+	}
+	slices.SortFunc(result, func(a, b models.Webhook) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return result
+}
+
+func (m *Manager) Get(id string) (models.Webhook, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	webhook, ok := m.webhooks[id]
+	if !ok {
+		return models.Webhook{}, ErrWebhookNotFound
+	}
+	return *webhook, nil
+}
+
+// Update replaces url, events and active, the secret is only replaced when one is given
+func (m *Manager) Update(id string, webhook models.Webhook) (models.Webhook, error) {
+	err := m.validate(&webhook)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	current, ok := m.webhooks[id]
+	if !ok {
+		return models.Webhook{}, ErrWebhookNotFound
+	}
+	updated := *current
+	updated.Url = webhook.Url
+	updated.Events = webhook.Events
+	updated.Active = webhook.Active
+	if webhook.Secret != "" {
+		updated.Secret = webhook.Secret
+	}
+	updated.UpdatedAt = time.Now().UTC()
+	err = m.write(true, entry{Op: opWebhook, Webhook: &updated})
+	if err != nil {
+		return models.Webhook{}, err
+	}
+	return updated, nil
+}
+
+// Delete removes the webhook with its dead letters, deliveries already under way are dropped
+func (m *Manager) Delete(id string) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	if _, ok := m.webhooks[id]; !ok {
+		return ErrWebhookNotFound
+	}
+	return m.write(true, entry{Op: opDelete, Id: id})
+}
+
+// DeadLetters returns the deliveries to the webhook that ran out of attempts, oldest first
+func (m *Manager) DeadLetters(id string) ([]models.WebhookDelivery, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	if _, ok := m.webhooks[id]; !ok {
+		return nil, ErrWebhookNotFound
+	}
+	result := []models.WebhookDelivery{}
+	for _, delivery := range m.deadLetters {
+		if delivery.WebhookId == id {
+			result = append(result, *delivery)
+		}
+	}
+	return result, nil
+}
+
+// Redeliver takes the delivery off the dead letters and sends it again with a fresh set of attempts
+func (m *Manager) Redeliver(id, deliveryId string) (models.WebhookDelivery, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	webhook, ok := m.webhooks[id]
+	if !ok {
+		return models.WebhookDelivery{}, ErrWebhookNotFound
+	}
+	if !webhook.Active {
+		return models.WebhookDelivery{}, ErrWebhookInactive
+	}
+	i := slices.IndexFunc(m.deadLetters, func(delivery *models.WebhookDelivery) bool {
+		return delivery.WebhookId == id && delivery.Id == deliveryId
+	})
+	if i < 0 {
+		return models.WebhookDelivery{}, ErrDeliveryNotFound
+	}
+	delivery := *m.deadLetters[i]
+	delivery.Attempts = 0
+	// the workers get their own copy to record the attempts on
+	queued := delivery
+	select {
+	case m.queue <- &queued:
+	default:
+		return models.WebhookDelivery{}, ErrQueueFull
+	}
+	err := m.write(true, entry{Op: opPending, Delivery: &delivery})
+	if err != nil {
+		// the delivery is already queued, at worst it is redelivered once more after a restart
+		logrus.WithError(err).WithField("delivery", deliveryId).Error("failed to save the webhook dead letters")
+	}
+	return delivery, nil
+}
+
+// Publish queues a delivery of the event to every active webhook subscribed to its type, it never blocks
+func (m *Manager) Publish(event models.AccountEvent) {
+	deliveries, err := m.deliveriesOf(event)
+	if err != nil {
+		// the deliveries are still made, only a restart before they are done loses them
+		logrus.WithError(err).WithField("event", event.Id).Error("failed to save the pending webhook deliveries")
+	}
+	for _, delivery := range deliveries {
+		m.enqueue(delivery)
+	}
+}
+
+// Handle saves the deliveries of an event of the change data capture before it is acknowledged, so a restart
+// sends them again, and queues them. A full queue holds the capture back until the workers catch up.
+func (m *Manager) Handle(ctx context.Context, event models.AccountEvent) error {
+	deliveries, err := m.deliveriesOf(event)
+	if err != nil {
+		return err
+	}
+	for _, delivery := range deliveries {
+		select {
+		case m.queue <- delivery:
+		case <-ctx.Done():
+			// saved, they are sent after the next start
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// deliveriesOf creates and saves the pending deliveries of the event, on a failed save they are not pending
+func (m *Manager) deliveriesOf(event models.AccountEvent) ([]*models.WebhookDelivery, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	var deliveries []*models.WebhookDelivery
+	for _, webhook := range m.webhooks {
+		if webhook.Active && slices.Contains(webhook.Events, event.Type) {
+			deliveries = append(deliveries, &models.WebhookDelivery{
+				Id:        newId(16),
+				WebhookId: webhook.Id,
+				Event:     event,
+				CreatedAt: time.Now().UTC(),
+			})
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil, nil
+	}
+	// one entry per delivery, synced once for the whole event
+	entries := make([]entry, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		entries = append(entries, entry{Op: opPending, Delivery: delivery})
+	}
+	return deliveries, m.write(true, entries...)
+}
+
+func (m *Manager) enqueue(delivery *models.WebhookDelivery) {
+	select {
+	case m.queue <- delivery:
+	default:
+		delivery.LastError = ErrQueueFull.Error()
+		m.deadLetter(delivery)
+	}
+}
+
+func (m *Manager) deadLetter(delivery *models.WebhookDelivery) {
+	logrus.WithFields(logrus.Fields{"webhook": delivery.WebhookId, "delivery": delivery.Id, "event": delivery.Event.Id, "attempts": delivery.Attempts, "lastError": delivery.LastError}).
+		Warn("webhook delivery moved to the dead letters")
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	err := m.write(false, entry{Op: opDeadLetter, Delivery: delivery})
+	if err != nil {
+		logrus.WithError(err).WithField("delivery", delivery.Id).Error("failed to save the webhook dead letters")
+	}
+}
+
+// settle records the outcome of an attempt, a delivery that is done is no longer pending
+func (m *Manager) settle(delivery *models.WebhookDelivery, done bool) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	if _, ok := m.pending[delivery.Id]; !ok {
+		return
+	}
+	e := entry{Op: opPending, Delivery: delivery}
+	if done {
+		e = entry{Op: opDone, Id: delivery.Id}
+	}
+	err := m.write(false, e)
+	if err != nil {
+		// at worst the delivery is sent again after a restart
+		logrus.WithError(err).WithField("delivery", delivery.Id).Error("failed to save the pending webhook deliveries")
+	}
+}
+
+// leftPending copies the deliveries left pending by the last run, oldest first
+func (m *Manager) leftPending() []*models.WebhookDelivery {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	pending := make([]*models.WebhookDelivery, 0, len(m.pending))
+	for _, delivery := range m.pending {
+		pending = append(pending, &delivery)
+	}
+	slices.SortFunc(pending, func(a, b *models.WebhookDelivery) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return pending
+}
+
+func (m *Manager) webhook(id string) (models.Webhook, bool) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	webhook, ok := m.webhooks[id]
+	if !ok {
+		return models.Webhook{}, false
+	}
+	return *webhook, true
+}
+
+// write appends the entries to the journal and applies them, synced when a restart must not lose them.
+// Once the journal grew to twice what it holds it is compacted. Must be called with the lock held.
+func (m *Manager) write(sync bool, entries ...entry) error {
+	var data []byte
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	var err error
+	if m.file == nil {
+		m.file, err = openJournal(m.path)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = m.file.Write(data)
+	if err != nil {
+		return err
+	}
+	if sync {
+		err = m.file.Sync()
+		if err != nil {
+			return err
+		}
+	}
+	for _, e := range entries {
+		m.apply(e)
+	}
+	m.entries += len(entries)
+	if m.entries >= max(compactAfter, 2*m.size()) {
+		err = m.compact()
+		if err != nil {
+			// the journal is only longer than needed, compaction is tried again on the next write
+			logrus.WithError(err).Warn("failed to compact the webhooks journal")
+		}
+	}
+	return nil
+}
+
+// apply must be called with the lock held
+func (m *Manager) apply(e entry) {
+	switch e.Op {
+	case opWebhook:
+		m.webhooks[e.Webhook.Id] = e.Webhook
+	case opDelete:
+		delete(m.webhooks, e.Id)
+		m.deadLetters = slices.DeleteFunc(m.deadLetters, func(delivery *models.WebhookDelivery) bool {
+			return delivery.WebhookId == e.Id
+		})
+		maps.DeleteFunc(m.pending, func(_ string, delivery models.WebhookDelivery) bool {
+			return delivery.WebhookId == e.Id
+		})
+	case opPending:
+		m.pending[e.Delivery.Id] = *e.Delivery
+		m.deadLetters = slices.DeleteFunc(m.deadLetters, func(delivery *models.WebhookDelivery) bool {
+			return delivery.Id == e.Delivery.Id
+		})
+	case opDone:
+		delete(m.pending, e.Id)
+	case opDeadLetter:
+		delete(m.pending, e.Delivery.Id)
+		if _, ok := m.webhooks[e.Delivery.WebhookId]; !ok {
+			return
+		}
+		m.deadLetters = append(m.deadLetters, e.Delivery)
+		if len(m.deadLetters) > maxDeadLetters {
+			m.deadLetters = slices.Clone(m.deadLetters[len(m.deadLetters)-maxDeadLetters:])
+		}
+	}
+}
+
+// size is how many entries the journal holds after a compaction, must be called with the lock held
+func (m *Manager) size() int {
+	return len(m.webhooks) + len(m.deadLetters) + len(m.pending)
+}
+
+// compact rewrites the journal with the current state, must be called with the lock held
+func (m *Manager) compact() error {
+	entries := make([]entry, 0, m.size())
+	for _, webhook := range m.webhooks {
+		entries = append(entries, entry{Op: opWebhook, Webhook: webhook})
+	}
+	for _, delivery := range m.deadLetters {
+		entries = append(entries, entry{Op: opDeadLetter, Delivery: delivery})
+	}
+	for _, delivery := range m.pending {
+		entries = append(entries, entry{Op: opPending, Delivery: &delivery})
+	}
+	var data []byte
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	err := persistance.WriteFileAtomic(m.path, data)
+	if err != nil {
+		return err
+	}
+	m.entries = len(entries)
+	if m.file == nil {
+		return nil
+	}
+	// the old file is gone, the next write opens the new one if this fails
+	_ = m.file.Close()
+	m.file, err = openJournal(m.path)
+	return err
+}
+
+// validate collects every invalid field, duplicated events are dropped
+func (m *Manager) validate(webhook *models.Webhook) error {
+	var invalidParams []cerror.InvalidParam
+	target, err := url.Parse(webhook.Url)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		invalidParams = append(invalidParams, cerror.InvalidParam{Name: "url", Reason: "url must be an absolute http or https url"})
+	} else if err = m.targets.check(target.Hostname()); err != nil {
+		invalidParams = append(invalidParams, cerror.InvalidParam{Name: "url", Reason: err.Error()})
+	}
+	var eventTypes []models.EventType
+	for _, eventType := range webhook.Events {
+		if !slices.Contains(models.EventTypes, eventType) {
+			invalidParams = append(invalidParams, cerror.InvalidParam{Name: "events", Reason: "unknown event " + string(eventType)})
+			continue
+		}
+		if !slices.Contains(eventTypes, eventType) {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	if len(webhook.Events) == 0 {
+		invalidParams = append(invalidParams, cerror.InvalidParam{Name: "events", Reason: "at least one event is required"})
+	}
+	if webhook.Secret != "" && len(webhook.Secret) < minSecretLength {
+		invalidParams = append(invalidParams, cerror.InvalidParam{Name: "secret", Reason: "secret must have at least 16 characters"})
+	}
+	if len(invalidParams) > 0 {
+		return cerror.NewValidationError(http.StatusBadRequest, invalidParams...)
+	}
+	webhook.Events = eventTypes
+	return nil
+}
+
+func newId(size int) string {
+	id := make([]byte, size)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,311 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	cerror "immudb/internal/errors"
+	"immudb/internal/events"
+	"immudb/internal/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testServers the httptest servers listen on loopback, which webhooks may only call when it is allowed
+var testServers = []string{"127.0.0.1"}
+
+func TestManager_Create(t *testing.T) {
+	tests := []struct {
+		name          string
+		webhook       models.Webhook
+		expectedError error
+	}{
+		{
+			name:    "Test_Validity",
+			webhook: models.Webhook{Url: "https://example.com/hook", Events: []models.EventType{models.AccountCreated, models.AccountCreated}},
+		},
+		{
+			name:    "Test_Invalid_Fields",
+			webhook: models.Webhook{Url: "ftp://example.com", Events: []models.EventType{"account.deleted"}, Secret: "short"},
+			expectedError: cerror.NewValidationError(http.StatusBadRequest,
+				cerror.InvalidParam{Name: "url", Reason: "url must be an absolute http or https url"},
+				cerror.InvalidParam{Name: "events", Reason: "unknown event account.deleted"},
+				cerror.InvalidParam{Name: "secret", Reason: "secret must have at least 16 characters"},
+			),
+		},
+		{
+			name:    "Test_Metadata_Service",
+			webhook: models.Webhook{Url: "http://169.254.169.254/latest/meta-data", Events: []models.EventType{models.AccountCreated}},
+			expectedError: cerror.NewValidationError(http.StatusBadRequest,
+				cerror.InvalidParam{Name: "url", Reason: "url must not point to a loopback, private or link-local address"},
+			),
+		},
+		{
+			name:    "Test_Loopback",
+			webhook: models.Webhook{Url: "http://[::ffff:127.0.0.1]:8080/hook", Events: []models.EventType{models.AccountCreated}},
+			expectedError: cerror.NewValidationError(http.StatusBadRequest,
+				cerror.InvalidParam{Name: "url", Reason: "url must not point to a loopback, private or link-local address"},
+			),
+		},
+		{
+			name:    "Test_Allowed_Private_Range",
+			webhook: models.Webhook{Url: "http://10.1.2.3/hook", Events: []models.EventType{models.AccountCreated}},
+		},
+		{
+			name:    "Test_No_Events",
+			webhook: models.Webhook{Url: "/hook"},
+			expectedError: cerror.NewValidationError(http.StatusBadRequest,
+				cerror.InvalidParam{Name: "url", Reason: "url must be an absolute http or https url"},
+				cerror.InvalidParam{Name: "events", Reason: "at least one event is required"},
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			manager, err := NewManager(t.TempDir(), Options{AllowedHosts: []string{"10.0.0.0/8"}})
+			assert.NoError(t, err)
+
+			// action
+			webhook, err := manager.Create(tt.webhook)
+
+			// assertions
+			if tt.expectedError != nil {
+				assert.Equal(t, tt.expectedError, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotEmpty(t, webhook.Id)
+			assert.Len(t, webhook.Secret, 64)
+			assert.Equal(t, []models.EventType{models.AccountCreated}, webhook.Events)
+		})
+	}
+}
+
+func TestManager_Deliver(t *testing.T) {
+	// setup
+	received := make(chan *http.Request, 1)
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, body)
+		received <- r
+	}))
+	defer server.Close()
+	manager, err := NewManager(t.TempDir(), Options{AllowedHosts: testServers})
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	manager.Start(ctx)
+	webhook, err := manager.Create(models.Webhook{Url: server.URL, Events: []models.EventType{models.AccountCreated}, Secret: "0123456789abcdef", Active: true})
+	assert.NoError(t, err)
+	account := models.AccountInfo{Id: 7, Name: "John", Meta: &models.DocumentMeta{Revision: 1, TransactionId: 12}}
+
+	// action
+	manager.Publish(events.NewAccountEvent(models.AccountUpdated, account))
+	manager.Publish(events.NewAccountEvent(models.AccountCreated, account))
+
+	// assertions
+	var request *http.Request
+	select {
+	case request = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the webhook was not called")
+	}
+	assert.Equal(t, webhook.Id, request.Header.Get(HeaderWebhookId))
+	assert.Equal(t, "account.created", request.Header.Get(HeaderEvent))
+	assert.NotEmpty(t, request.Header.Get(HeaderDelivery))
+	timestamp, err := strconv.ParseInt(request.Header.Get(HeaderTimestamp), 10, 64)
+	assert.NoError(t, err)
+	assert.Equal(t, Sign("0123456789abcdef", timestamp, bodies[0]), request.Header.Get(HeaderSignature))
+	var envelope events.Envelope
+	assert.NoError(t, json.Unmarshal(bodies[0], &envelope))
+	assert.Equal(t, "7-1", envelope.Id)
+	assert.Equal(t, uint64(12), envelope.TransactionId)
+	assert.Equal(t, "John", envelope.Data.AccountName)
+	select {
+	case <-received:
+		t.Fatal("the webhook is not subscribed to account.updated")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestManager_DeadLetterAndRedeliver(t *testing.T) {
+	// setup
+	var healthy atomic.Bool
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	options := Options{MaxAttempts: 3, Backoff: time.Millisecond, AllowedHosts: testServers}
+	manager, err := NewManager(dir, options)
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	manager.Start(ctx)
+	webhook, err := manager.Create(models.Webhook{Url: server.URL, Events: []models.EventType{models.AccountCreated}, Active: true})
+	assert.NoError(t, err)
+
+	// action
+	manager.Publish(events.NewAccountEvent(models.AccountCreated, models.AccountInfo{Id: 1}))
+
+	// assertions
+	var deadLetters []models.WebhookDelivery
+	assert.Eventually(t, func() bool {
+		deadLetters, err = manager.DeadLetters(webhook.Id)
+		return err == nil && len(deadLetters) == 1
+	}, 5*time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, 3, deadLetters[0].Attempts)
+	assert.Equal(t, http.StatusBadGateway, deadLetters[0].LastStatusCode)
+	assert.Equal(t, "the webhook answered 502", deadLetters[0].LastError)
+
+	// the dead letters survive a restart
+	reopened, err := NewManager(dir, options)
+	assert.NoError(t, err)
+	saved, err := reopened.DeadLetters(webhook.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, deadLetters[0].Id, saved[0].Id)
+
+	// action
+	healthy.Store(true)
+	_, err = manager.Redeliver(webhook.Id, "unknown")
+	assert.Equal(t, ErrDeliveryNotFound, err)
+	redelivered, err := manager.Redeliver(webhook.Id, deadLetters[0].Id)
+
+	// assertions
+	assert.NoError(t, err)
+	assert.Equal(t, 0, redelivered.Attempts)
+	assert.Eventually(t, func() bool {
+		return calls.Load() == 4
+	}, 5*time.Second, 5*time.Millisecond)
+	deadLetters, err = manager.DeadLetters(webhook.Id)
+	assert.NoError(t, err)
+	assert.Empty(t, deadLetters)
+}
+
+func TestManager_PendingSurvivesRestart(t *testing.T) {
+	// setup
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	stopped, err := NewManager(dir, Options{AllowedHosts: testServers})
+	assert.NoError(t, err)
+	webhook, err := stopped.Create(models.Webhook{Url: server.URL, Events: []models.EventType{models.AccountCreated}, Active: true})
+	assert.NoError(t, err)
+	// handed over by the change data capture but never sent, the process stops before the workers ran
+	assert.NoError(t, stopped.Handle(context.Background(), events.NewAccountEvent(models.AccountCreated, models.AccountInfo{Id: 1})))
+
+	// action
+	restarted, err := NewManager(dir, Options{AllowedHosts: testServers})
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	restarted.Start(ctx)
+
+	// assertions
+	assert.Eventually(t, func() bool {
+		return calls.Load() == 1
+	}, 5*time.Second, 5*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		reopened, err := NewManager(dir, Options{})
+		return err == nil && len(reopened.leftPending()) == 0
+	}, 5*time.Second, 5*time.Millisecond, "a sent delivery is no longer pending")
+	deadLetters, err := restarted.DeadLetters(webhook.Id)
+	assert.NoError(t, err)
+	assert.Empty(t, deadLetters)
+}
+
+func TestManager_RefusesInternalAddressesWhenSending(t *testing.T) {
+	// setup
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+	manager, err := NewManager(t.TempDir(), Options{})
+	assert.NoError(t, err)
+	// a host that resolved to a public address when the webhook was validated and resolves to loopback now
+	webhook := models.Webhook{Id: "rebound", Url: server.URL, Secret: "0123456789abcdef", Active: true}
+
+	// action
+	_, err = manager.send(context.Background(), webhook, &models.WebhookDelivery{Id: "1", Event: events.NewAccountEvent(models.AccountCreated, models.AccountInfo{Id: 1})})
+
+	// assertions
+	assert.ErrorIs(t, err, errInternalAddress)
+	assert.Equal(t, int32(0), calls.Load())
+}
+
+func TestManager_RetryWaitsForTheQueue(t *testing.T) {
+	// setup
+	manager, err := NewManager(t.TempDir(), Options{})
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for i := 0; i < queueSize; i++ {
+		manager.queue <- &models.WebhookDelivery{Id: strconv.Itoa(i)}
+	}
+
+	// action
+	manager.retry(ctx, &models.WebhookDelivery{Id: "retried"}, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	<-manager.queue
+
+	// assertions
+	assert.Eventually(t, func() bool {
+		return len(manager.queue) == queueSize
+	}, 5*time.Second, time.Millisecond, "the retry must be queued once there is room")
+	var last *models.WebhookDelivery
+	for len(manager.queue) > 0 {
+		last = <-manager.queue
+	}
+	assert.Equal(t, "retried", last.Id)
+}
+
+func TestManager_JournalCompacts(t *testing.T) {
+	// setup
+	dir := t.TempDir()
+	manager, err := NewManager(dir, Options{})
+	assert.NoError(t, err)
+	webhook, err := manager.Create(models.Webhook{Url: "https://example.com/hook", Events: []models.EventType{models.AccountCreated}, Active: true})
+	assert.NoError(t, err)
+	deliveries, err := manager.deliveriesOf(events.NewAccountEvent(models.AccountCreated, models.AccountInfo{Id: 1}))
+	assert.NoError(t, err)
+
+	// action
+	for i := 0; i < 2*compactAfter; i++ {
+		deliveries[0].Attempts = i + 1
+		manager.settle(deliveries[0], false)
+	}
+
+	// assertions
+	assert.Less(t, manager.entries, compactAfter)
+	reopened, err := NewManager(dir, Options{})
+	assert.NoError(t, err)
+	pending := reopened.leftPending()
+	assert.Len(t, pending, 1)
+	assert.Equal(t, webhook.Id, pending[0].WebhookId)
+	assert.Equal(t, 2*compactAfter, pending[0].Attempts)
+}
+
+func TestManager_Backoff(t *testing.T) {
+	manager, err := NewManager(t.TempDir(), Options{Backoff: time.Second, MaxBackoff: 5 * time.Second})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, manager.backoff(1))
+	assert.Equal(t, 2*time.Second, manager.backoff(2))
+	assert.Equal(t, 4*time.Second, manager.backoff(3))
+	assert.Equal(t, 5*time.Second, manager.backoff(4))
+}
@@ -0,0 +1,119 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+)
+
+// lookupTimeout of resolving the host of a webhook url when it is validated
+const lookupTimeout = 5 * time.Second
+
+// internalRanges the netip checks don't cover: "this network" and the shared address space, some clouds serve their metadata from it
+var internalRanges = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+var errInternalAddress = errors.New("internal address")
+
+// targets decides which hosts webhooks may call. A host resolving to a loopback, private, link-local or otherwise internal
+// address is refused unless it or a range of its addresses is allowed, so a webhook can't reach the metadata service of
+// the cloud or an api on the internal network. The addresses are checked again on every connection, a host that resolves
+// to another address than when the webhook was validated is refused too.
+type targets struct {
+	hosts    []string
+	ranges   []netip.Prefix
+	resolver *net.Resolver
+}
+
+// newTargets allows the given hosts, addresses and ranges in CIDR notation even though they are internal
+func newTargets(allowed []string) *targets {
+	t := &targets{resolver: net.DefaultResolver}
+	for _, value := range allowed {
+		if prefix, err := netip.ParsePrefix(value); err == nil {
+			t.ranges = append(t.ranges, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(value); err == nil {
+			t.ranges = append(t.ranges, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+		} else {
+			t.hosts = append(t.hosts, strings.ToLower(value))
+		}
+	}
+	return t
+}
+
+// check is the reason a webhook may not call the host, a host that doesn't resolve yet is checked when it is called
+func (t *targets) check(host string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+	defer cancel()
+	_, err := t.resolve(ctx, host)
+	if errors.Is(err, errInternalAddress) {
+		return errors.New("url must not point to a loopback, private or link-local address")
+	}
+	return nil
+}
+
+// resolve returns the addresses of the host, it fails when any of them is internal and not allowed
+func (t *targets) resolve(ctx context.Context, host string) ([]netip.Addr, error) {
+	addrs, err := t.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(t.hosts, strings.ToLower(host)) {
+		return addrs, nil
+	}
+	for _, addr := range addrs {
+		if !t.allowed(addr.Unmap()) {
+			return nil, fmt.Errorf("%s resolves to %s: %w", host, addr, errInternalAddress)
+		}
+	}
+	return addrs, nil
+}
+
+func (t *targets) allowed(addr netip.Addr) bool {
+	for _, prefix := range t.ranges {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return !internal(addr)
+}
+
+func internal(addr netip.Addr) bool {
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() {
+		return true
+	}
+	for _, prefix := range internalRanges {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// dial connects to the first address of the host that answers, once every address passed the check
+func (t *targets) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := t.resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	var dialer net.Dialer
+	for _, addr := range addrs {
+		var conn net.Conn
+		conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(addr.Unmap().String(), port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}